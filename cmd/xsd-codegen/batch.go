@@ -0,0 +1,159 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/xmlgen"
+)
+
+// manifestName is the name of the manifest of a batch, next to its documents.
+const manifestName = "manifest.json"
+
+// writeBatch generates -count documents with -workers workers and writes them, with their manifest, to
+// -out-dir or to the -archive file. With -all, the documents cycle through the global elements.
+func writeBatch(schema *model.XSDSchema, genOpts []xmlgen.Option, opts *cliOptions) error {
+	out, err := newBatchOutput(opts.outDir, opts.archive)
+	if err != nil {
+		return fmt.Errorf("unable to create batch output: %w", err)
+	}
+	b := &xmlgen.Batch{Count: opts.count, Workers: opts.workers, Name: opts.name}
+	if opts.all {
+		b.Roots = xmlgen.Roots(schema)
+	} else {
+		genOpts = append(genOpts, xmlgen.WithRoot(opts.root))
+	}
+	manifest, err := xmlgen.New(schema, genOpts...).Batch(context.Background(), b,
+		func(d *xmlgen.BatchDocument, data []byte) error {
+			return out.add(d.Name, data)
+		})
+	if err == nil {
+		var buf bytes.Buffer
+		if _, err = manifest.WriteTo(&buf); err == nil {
+			err = out.add(manifestName, buf.Bytes())
+		}
+	}
+	if closeErr := out.close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("cannot generate the batch: %w", err)
+	}
+	target := opts.archive
+	if target == "" {
+		target = opts.outDir
+	}
+	fmt.Fprintf(os.Stderr, "Generated %d documents into %s\n", len(manifest.Documents), target)
+	return nil
+}
+
+// batchOutput stores the files of a batch, in a directory or an archive.
+type batchOutput interface {
+	add(name string, data []byte) error
+	close() error
+}
+
+// newBatchOutput returns the output writing to dir, or to the archive file when it is set: a zip file, or a
+// gzipped tarball when archive ends with .tar.gz or .tgz.
+func newBatchOutput(dir, archive string) (batchOutput, error) {
+	if archive == "" {
+		dir, err := cleanOutputPath(dir)
+		if err != nil {
+			return nil, err
+		}
+		return dirOutput(dir), os.MkdirAll(dir, 0o750)
+	}
+	zipped := strings.HasSuffix(archive, ".zip")
+	if !zipped && !strings.HasSuffix(archive, ".tar.gz") && !strings.HasSuffix(archive, ".tgz") {
+		return nil, fmt.Errorf("archive %s: want a .zip, .tar.gz or .tgz file", archive)
+	}
+	archive, err := cleanOutputPath(archive)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Create(archive)
+	if err != nil {
+		return nil, err
+	}
+	if zipped {
+		return &zipOutput{file: file, zip: zip.NewWriter(file)}, nil
+	}
+	gz := gzip.NewWriter(file)
+	return &tarOutput{file: file, gz: gz, tar: tar.NewWriter(gz)}, nil
+}
+
+// dirOutput writes files below a directory.
+type dirOutput string
+
+func (d dirOutput) add(name string, data []byte) error {
+	path := filepath.Join(string(d), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func (d dirOutput) close() error {
+	return nil
+}
+
+// zipOutput writes files to a zip archive. Entries carry no modification time, so that the same batch
+// gives the same archive.
+type zipOutput struct {
+	file *os.File
+	zip  *zip.Writer
+}
+
+func (z *zipOutput) add(name string, data []byte) error {
+	w, err := z.zip.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (z *zipOutput) close() error {
+	return closeAll(z.zip, z.file)
+}
+
+// tarOutput writes files to a gzipped tarball, with the fixed modification time of the Unix epoch.
+type tarOutput struct {
+	file *os.File
+	gz   *gzip.Writer
+	tar  *tar.Writer
+}
+
+func (t *tarOutput) add(name string, data []byte) error {
+	header := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: time.Unix(0, 0), Format: tar.FormatPAX}
+	if err := t.tar.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := t.tar.Write(data)
+	return err
+}
+
+func (t *tarOutput) close() error {
+	return closeAll(t.tar, t.gz, t.file)
+}
+
+// closeAll closes every closer in order and returns the first error.
+func closeAll(closers ...io.Closer) error {
+	var first error
+	for _, c := range closers {
+		if err := c.Close(); first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -2,41 +2,314 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/codegen"
 	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
 	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
 	"github.com/Patrick-Ivann/xsd-codegen/pkg/parser"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/semantic"
 	"github.com/Patrick-Ivann/xsd-codegen/pkg/xmlgen"
-	"github.com/beevik/etree"
 )
 
+// cliOptions holds the parsed command-line flags.
+type cliOptions struct {
+	xsdPath   string
+	outPath   string
+	goOutPath string
+	goOutDir  string
+	goImport  string
+	goPackage string
+	goChoice  string
+	tmplDir   string
+	goTypes   string
+	fixtures  bool
+	builders  bool
+	seed      seedValue
+	root      string
+	all       bool
+	outDir    string
+	listRoots bool
+	count     int
+	workers   int
+	name      string
+	archive   string
+
+	mode        xmlgen.Mode
+	boundary    bool
+	semantic    bool
+	rules       string
+	now         time.Time
+	constraints string
+	generators  string
+	maxDepth    int
+	maxElements int
+	maxBytes    int
+	targetSize  int
+
+	schemaLocation string
+	prefixes       map[string]string // namespace URI to prefix
+}
+
 func main() {
-	xsdPath, outPath := parseFlags()
-	schema := mustParseSchema(xsdPath)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "selftest":
+			os.Exit(runSelftest(os.Args[2:]))
+		case "suite":
+			os.Exit(runSuite(os.Args[2:]))
+		case "mutate":
+			os.Exit(runMutate(os.Args[2:]))
+		}
+	}
+	opts := parseFlags()
+	schema := mustParseSchema(opts.xsdPath)
+
+	if opts.listRoots {
+		listRoots(schema)
+		return
+	}
+	if opts.goOutPath != "" || opts.goOutDir != "" {
+		writeGoCode(schema, opts)
+		// Go generation alone was requested: only emit XML when an output file is given as well.
+		if opts.outPath == "" && !opts.all && opts.count == 0 {
+			return
+		}
+	}
+
+	seed := opts.seed.value()
+	genOpts := []xmlgen.Option{
+		xmlgen.WithSeed(seed),
+		xmlgen.WithSchemaLocation(opts.schemaLocation),
+		xmlgen.WithNamespacePrefixes(opts.prefixes),
+		xmlgen.WithMode(opts.mode),
+		xmlgen.WithMaxDepth(opts.maxDepth),
+		xmlgen.WithMaxElements(opts.maxElements),
+		xmlgen.WithMaxBytes(opts.maxBytes),
+	}
+	if opts.targetSize > 0 {
+		genOpts = append(genOpts, xmlgen.WithTargetSize(opts.targetSize))
+		// the target lifts the element limit, unless it is given explicitly
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name == "max-elements" {
+				genOpts = append(genOpts, xmlgen.WithMaxElements(opts.maxElements))
+			}
+		})
+	}
+	if opts.boundary {
+		genOpts = append(genOpts, xmlgen.WithBoundaryValues())
+	}
+	if opts.semantic {
+		genOpts = append(genOpts, xmlgen.WithSemanticValues(semantic.NewRegistry()))
+	}
+	reproduce := fmt.Sprintf("-seed %d", seed)
+	if opts.rules != "" {
+		// relative bounds such as today+30d are part of what reproduces the documents
+		if opts.now.IsZero() {
+			opts.now = time.Now().UTC().Truncate(time.Second)
+		}
+		reproduce += " -now " + opts.now.Format(time.RFC3339)
+		genOpts = append(genOpts, xmlgen.WithRules(loadRules(schema, opts.rules)), xmlgen.WithNow(opts.now))
+	}
+	if opts.constraints != "" {
+		constraints, err := xmlgen.LoadConstraints(opts.constraints)
+		if err != nil {
+			log.Fatalf("Cannot load the constraints: %v", err)
+		}
+		genOpts = append(genOpts, xmlgen.WithConstraints(constraints))
+	}
+	var registry *xmlgen.Registry
+	if opts.generators != "" {
+		var err error
+		if registry, err = xmlgen.LoadPlugins(opts.generators); err != nil {
+			log.Fatalf("Cannot start the generator plugins: %v", err)
+		}
+		genOpts = append(genOpts, xmlgen.WithGenerators(registry))
+	}
+	err := writeDocuments(schema, genOpts, &opts)
+	// the plugins are shut down before exiting, which log.Fatal would skip
+	if closeErr := registry.Close(); closeErr != nil {
+		fmt.Fprintf(os.Stderr, "Generator plugins: %v\n", closeErr)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Fprintf(os.Stderr, "Generated with %s\n", reproduce)
+}
 
-	doc, found := generateXMLDocument(schema)
-	if !found {
-		log.Fatal("Entrypoint element 'purchaseOrder' not found in schema.")
+// writeDocuments writes the documents requested by the flags: a batch, one per global element or a
+// single one.
+func writeDocuments(schema *model.XSDSchema, genOpts []xmlgen.Option, opts *cliOptions) error {
+	switch {
+	case opts.count > 0:
+		return writeBatch(schema, genOpts, opts)
+	case opts.all:
+		return writeAllDocuments(schema, genOpts, opts.outDir)
+	}
+	if _, err := xmlgen.FindRoot(schema, opts.root); err != nil {
+		return fmt.Errorf("cannot select the root element: %w", err)
 	}
-	writeOutput(doc, outPath)
+	return writeOutput(xmlgen.New(schema, append(genOpts, xmlgen.WithRoot(opts.root))...), opts.outPath)
 }
 
 // parseFlags handles command-line flag parsing and validation.
 // Uses flag.StringVar to avoid immediate dereference issues.
-func parseFlags() (pathToXSDFile, pathToOutputXML string) {
-	var xsdPath, outPath string
-	flag.StringVar(&xsdPath, "xsd", "", "Path to XSD file")
-	flag.StringVar(&outPath, "out", "", "Output XML file path (default stdout)")
+func parseFlags() cliOptions {
+	var opts cliOptions
+	flag.StringVar(&opts.xsdPath, "xsd", "", "Path to XSD file")
+	flag.StringVar(&opts.outPath, "out", "", "Output XML file path (default stdout)")
+	flag.StringVar(&opts.goOutPath, "go-out", "", "Output Go source file path for the generated types")
+	flag.StringVar(&opts.goOutDir, "go-out-dir", "",
+		"Output directory of the generated Go packages, one per namespace mapped in -go-types")
+	flag.StringVar(&opts.goImport, "go-import-path", "", "Import path of the Go package generated into -go-out-dir")
+	flag.StringVar(&opts.goPackage, "go-package", codegen.DefaultPackage, "Package name of the generated Go source")
+	flag.StringVar(&opts.goChoice, "go-choice", string(codegen.ChoiceFields),
+		"Representation of xs:choice in the generated Go code: fields or interface")
+	flag.StringVar(&opts.tmplDir, "template-dir", "", "Directory of *.tmpl files overriding the built-in Go templates")
+	flag.StringVar(&opts.goTypes, "go-types", "", "YAML or JSON file mapping schema types and fields to Go types and names")
+	flag.BoolVar(&opts.fixtures, "go-fixtures", false, "Also generate RandomX functions building random values of the Go types")
+	flag.BoolVar(&opts.builders, "go-builders", false,
+		"Also generate NewX constructors applying fixed and default values, and XBuilder types")
+	flag.Var(&opts.seed, "seed", "Seed of the generated XML; the same seed, schema and flags, with -now for -rules, give the same document (default random)")
+	flag.StringVar(&opts.root, "root", "",
+		"Global element of the generated XML: local name, prefix:name or {namespace}name (default: first complex one)")
+	flag.BoolVar(&opts.all, "all", false, "Generate one XML document per global element into -out-dir")
+	flag.StringVar(&opts.outDir, "out-dir", "", "Output directory of the XML documents generated with -all or -count")
+	flag.IntVar(&opts.count, "count", 0, "Generate this many XML documents, each from a seed derived from -seed, "+
+		"into -out-dir or -archive with a manifest.json; with -all, roots cycle through the global elements")
+	flag.IntVar(&opts.workers, "workers", 0, "Documents generated in parallel with -count, 0 for one per CPU; "+
+		"the documents do not depend on it")
+	flag.StringVar(&opts.name, "name", xmlgen.DefaultBatchName, "Template of the file names of the documents generated "+
+		"with -count, with the fields N, Root and Seed")
+	flag.StringVar(&opts.archive, "archive", "", "Write the documents generated with -count to this .zip, .tar.gz "+
+		"or .tgz file instead of -out-dir")
+	flag.BoolVar(&opts.listRoots, "list-roots", false, "List the global elements that can be used with -root, and exit")
+	opts.mode = xmlgen.ModeRandom
+	flag.Func("mode", "Shape of the generated XML: min (required items only), max (every optional item) "+
+		"or random (default random)", func(v string) error {
+		mode, err := xmlgen.ParseMode(v)
+		opts.mode = mode
+		return err
+	})
+	flag.BoolVar(&opts.boundary, "boundary", false,
+		"Generate values on the edges of their facets, such as min, min+1, max-1 and max, instead of random ones")
+	flag.BoolVar(&opts.semantic, "semantic", false,
+		"Generate realistic names, addresses, e-mails, phone numbers, IBANs, URLs, ... from element, attribute and type names")
+	flag.StringVar(&opts.rules, "rules", "",
+		"YAML or JSON file pinning or constraining the values and occurrences of the nodes at given paths")
+	flag.Func("now", "Reference time of the relative bounds of -rules, such as today+30d, as 2006-01-02 or RFC 3339 "+
+		"(default the current time)", func(v string) error {
+		now, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			now, err = time.Parse(time.DateOnly, v)
+		}
+		opts.now = now
+		return err
+	})
+	flag.StringVar(&opts.constraints, "constraints", "",
+		"YAML or JSON file of copies, derivations and comparisons between the values of the generated documents")
+	flag.StringVar(&opts.generators, "generators", "",
+		"YAML or JSON file mapping paths, simple types and built-in types to generator plugins run as subprocesses")
+	flag.IntVar(&opts.maxDepth, "max-depth", xmlgen.DefaultMaxDepth,
+		"Depth from which only the shortest valid content is generated, 0 for no limit")
+	flag.IntVar(&opts.maxElements, "max-elements", xmlgen.DefaultMaxElements,
+		"Number of elements from which only the shortest valid completion is generated, 0 for no limit")
+	flag.IntVar(&opts.maxBytes, "max-bytes", 0,
+		"Approximate document size from which only the shortest valid completion is generated, 0 for no limit")
+	flag.Func("target-size", "Repeat the outermost unbounded elements until the document is about this large, "+
+		"such as 500MB or 2GiB; documents are written as they are generated, in constant memory", func(v string) error {
+		n, err := xmlgen.ParseSize(v)
+		opts.targetSize = n
+		return err
+	})
+	flag.StringVar(&opts.schemaLocation, "schema-location", "schema.xsd",
+		"Location of the schema in the xsi:schemaLocation attribute of the root element, empty to leave it out")
+	opts.prefixes = map[string]string{}
+	flag.Func("ns-prefix", "Write the namespace with a prefix, given as prefix=namespace (repeatable)", func(v string) error {
+		prefix, ns, ok := strings.Cut(v, "=")
+		if !ok || prefix == "" || ns == "" {
+			return fmt.Errorf("expected prefix=namespace, got %q", v)
+		}
+		opts.prefixes[ns] = prefix
+		return nil
+	})
 	flag.Parse()
 
-	if xsdPath == "" {
+	if opts.xsdPath == "" {
 		log.Fatal("XSD file path is required. Use -xsd flag.")
 	}
-	return xsdPath, outPath
+	if opts.count > 0 {
+		if (opts.outDir == "") == (opts.archive == "") || opts.outPath != "" || (opts.all && opts.root != "") {
+			log.Fatal("-count writes into either -out-dir or -archive, and cannot be combined with -out, or -root with -all.")
+		}
+		if opts.targetSize > 0 {
+			// batch documents are held in memory until written in order, which a target size would defeat
+			log.Fatal("-count cannot be combined with -target-size, whose documents are written as they are generated.")
+		}
+	} else if opts.all && (opts.outDir == "" || opts.root != "" || opts.outPath != "") {
+		log.Fatal("-all writes into -out-dir and cannot be combined with -root or -out.")
+	}
+	return opts
+}
+
+// writeGoCode generates the Go types for the schema and writes them to the -go-out file,
+// or to one file per package under -go-out-dir.
+func writeGoCode(schema *model.XSDSchema, opts cliOptions) {
+	genOpts := codegen.Options{
+		Package:     opts.goPackage,
+		ImportPath:  opts.goImport,
+		ChoiceStyle: codegen.ChoiceStyle(opts.goChoice),
+		TemplateDir: opts.tmplDir,
+		Fixtures:    opts.fixtures,
+		Builders:    opts.builders,
+	}
+	if opts.goTypes != "" {
+		mapping, err := codegen.LoadTypeMapping(opts.goTypes)
+		if err != nil {
+			log.Fatalf("Failed to load type mapping: %v", err)
+		}
+		genOpts.TypeMapping = mapping
+	}
+	if opts.goOutDir != "" {
+		writeGoPackages(schema, genOpts, opts.goOutDir)
+		return
+	}
+	src, err := codegen.Generate(schema, genOpts)
+	if err != nil {
+		log.Fatalf("Failed to generate Go code: %v", err)
+	}
+	if err := os.WriteFile(sanitizeOutputPath(opts.goOutPath), src, 0o600); err != nil {
+		log.Fatalf("Unable to write Go output file: %v", err)
+	}
+}
+
+// writeGoPackages writes every generated package to <dir>/<path>/<name>.go, where path is the import path
+// of the package relative to -go-import-path.
+func writeGoPackages(schema *model.XSDSchema, genOpts codegen.Options, dir string) {
+	packages, err := codegen.GeneratePackages(schema, genOpts)
+	if err != nil {
+		log.Fatalf("Failed to generate Go code: %v", err)
+	}
+	for _, pkg := range packages {
+		rel, ok := strings.CutPrefix(pkg.ImportPath, genOpts.ImportPath)
+		if !ok || (rel != "" && !strings.HasPrefix(rel, "/")) {
+			log.Fatalf("Package %s is not below -go-import-path %s", pkg.ImportPath, genOpts.ImportPath)
+		}
+		pkgDir := sanitizeOutputPath(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err := os.MkdirAll(pkgDir, 0o750); err != nil {
+			log.Fatalf("Unable to create Go package directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(pkgDir, pkg.Name+".go"), pkg.Source, 0o600); err != nil {
+			log.Fatalf("Unable to write Go output file: %v", err)
+		}
+	}
 }
 
 // mustParseSchema parses the XSD file and exits the program on error.
@@ -49,60 +322,138 @@ func mustParseSchema(xsdPath string) *model.XSDSchema {
 	return schema
 }
 
-// generateXMLDocument creates the XML document with root populated from the entrypoint element.
-func generateXMLDocument(schema *model.XSDSchema) (*etree.Document, bool) {
-	doc := etree.NewDocument()
-	for i := range schema.Elements {
-		el := &schema.Elements[i]       // pass element by pointer to avoid copying large struct
-		if el.Name != "purchaseOrder" { // invert if to reduce nesting and continue early
-			continue
+// writeAllDocuments writes one document per global element to <dir>/<name>.xml. Elements of different
+// namespaces sharing a name get a _2, _3, ... suffix in schema order.
+func writeAllDocuments(schema *model.XSDSchema, genOpts []xmlgen.Option, dir string) error {
+	dir, err := cleanOutputPath(dir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("unable to create output directory: %w", err)
+	}
+	seen := map[string]int{}
+	for _, root := range xmlgen.Roots(schema) {
+		name := root.Element.Name
+		if seen[name]++; seen[name] > 1 {
+			name += "_" + strconv.Itoa(seen[name])
+		}
+		gen := xmlgen.New(schema, append(genOpts, xmlgen.WithRoot("{"+root.Namespace+"}"+root.Element.Name))...)
+		if err := writeOutput(gen, filepath.Join(dir, name+".xml")); err != nil {
+			return err
 		}
-		root := xmlgen.GenerateElement(schema, el, helpers.DefaultValueGenerator{})
-		// Add schema namespace attributes
-		root.CreateAttr("xmlns", schema.TargetNamespace)
-		root.CreateAttr("xsi:schemaLocation", schema.TargetNamespace+" schema.xsd")
-		root.CreateAttr("xmlns:xsi", "http://www.w3.org/2001/XMLSchema-instance")
-		doc.SetRoot(root)
-		return doc, true
 	}
-	return nil, false
+	return nil
 }
 
-// writeOutput outputs the XML document either to stdout or a file, with indenting for readability.
-// Checks file.Close error explicitly without using fatal inside defer.
+// listRoots prints the global elements of the schema with their declared types.
+func listRoots(schema *model.XSDSchema) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, root := range xmlgen.Roots(schema) {
+		fmt.Fprintf(w, "%s\t%s\n", root.QName(schema), root.Type)
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatalf("Unable to write output: %v", err)
+	}
+}
+
+// writeOutput generates a document and writes it either to stdout or a file.
+// The document is written to a temporary file next to outPath, renamed to outPath once complete, so that a
+// failed generation leaves neither an empty nor a truncated file, nor replaces an existing one.
 // Ensures output path does not allow directory traversal for safer file creation.
-func writeOutput(doc *etree.Document, outPath string) {
-	doc.Indent(2)
+func writeOutput(gen *xmlgen.Generator, outPath string) error {
 	if outPath == "" {
-		_, err := doc.WriteTo(os.Stdout)
-		if err != nil {
-			log.Fatalf("Unable to write output: %v", err)
+		if _, err := gen.WriteTo(os.Stdout); err != nil {
+			return fmt.Errorf("unable to write output: %w", err)
 		}
-		return
+		return nil
 	}
 
+	outPath, err := cleanOutputPath(outPath)
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(outPath), "."+filepath.Base(outPath)+".*")
+	if err != nil {
+		return fmt.Errorf("unable to create output file: %w", err)
+	}
+	tmpPath := file.Name()
+
+	if _, err = gen.WriteTo(file); err != nil {
+		_ = file.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("unable to write output file: %w", err)
+	}
+	if err = file.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to close output file: %w", err)
+	}
+	if err = os.Rename(tmpPath, outPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("unable to create output file: %w", err)
+	}
+	return nil
+}
+
+// sanitizeOutputPath cleans an output path and exits on path traversal attempts.
+func sanitizeOutputPath(outPath string) string {
+	cleanPath, err := cleanOutputPath(outPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return cleanPath
+}
+
+// cleanOutputPath cleans an output path and rejects path traversal attempts.
+func cleanOutputPath(outPath string) (string, error) {
 	// Sanitize output path: e.g., prevent path traversal attacks or unsupported characters.
 	cleanPath := filepath.Clean(outPath)
 	if strings.HasPrefix(cleanPath, "..") || strings.Contains(cleanPath, string(os.PathSeparator)+"..") {
-		log.Fatalf("Invalid output file path: %s", outPath)
+		return "", fmt.Errorf("invalid output file path: %s", outPath)
 	}
+	return cleanPath, nil
+}
 
-	file, err := os.Create(cleanPath)
-	if err != nil {
-		log.Fatalf("Unable to create output file: %v", err)
+// seedValue is the -seed flag, remembering whether it was given.
+type seedValue struct {
+	seed uint64
+	set  bool
+}
+
+// String implements flag.Value.
+func (s *seedValue) String() string {
+	if s == nil || !s.set {
+		return ""
 	}
+	return strconv.FormatUint(s.seed, 10)
+}
 
-	_, err = doc.WriteTo(file)
+// Set implements flag.Value.
+func (s *seedValue) Set(v string) error {
+	seed, err := strconv.ParseUint(v, 10, 64)
 	if err != nil {
-		fileCloseErr := file.Close()
-		if fileCloseErr != nil {
-			log.Fatalf("Unable to write output file: %v", fileCloseErr)
-		}
-		log.Fatalf("Unable to write output file: %v", err)
+		return fmt.Errorf("seed must be an unsigned integer: %w", err)
 	}
+	s.seed, s.set = seed, true
+	return nil
+}
 
-	// Explicitly check Close error here, after successful write
-	if err = file.Close(); err != nil {
-		log.Fatalf("Failed to close output file: %v", err)
+// value returns the seed, picking a random one when the flag was not given.
+func (s *seedValue) value() uint64 {
+	if !s.set {
+		s.seed, s.set = helpers.NewSeed(), true
+	}
+	return s.seed
+}
+
+// loadRules reads the rules file at path and warns about the rules matching no node of schema.
+func loadRules(schema *model.XSDSchema, path string) xmlgen.Rules {
+	rules, err := xmlgen.LoadRules(path)
+	if err != nil {
+		log.Fatalf("Cannot load the rules: %v", err)
+	}
+	for _, p := range rules.Unmatched(schema) {
+		fmt.Fprintf(os.Stderr, "Rule %s matches no element or attribute of the schema\n", p)
 	}
+	return rules
 }
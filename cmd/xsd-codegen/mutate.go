@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/xmlgen"
+)
+
+// runMutate implements "xsd-codegen mutate": it writes a valid document and one invalid document per
+// constraint it can violate, with a JSON manifest of the expected violations. It returns the exit status.
+func runMutate(args []string) int {
+	fs := flag.NewFlagSet("mutate", flag.ExitOnError)
+	xsdPath := fs.String("xsd", "", "Path to XSD file")
+	outDir := fs.String("out-dir", "", "Output directory of the documents")
+	manifestPath := fs.String("manifest", "", "Output path of the JSON manifest (default <out-dir>/manifest.json)")
+	root := fs.String("root", "",
+		"Global element of the documents: local name, prefix:name or {namespace}name (default: first complex one)")
+	schemaLocation := fs.String("schema-location", "schema.xsd",
+		"Location of the schema in the xsi:schemaLocation attribute of the root element, empty to leave it out")
+	var seed seedValue
+	fs.Var(&seed, "seed", "Seed of the valid document (default random)")
+	if err := fs.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	if *xsdPath == "" || *outDir == "" {
+		log.Fatal("The -xsd and -out-dir flags are required.")
+	}
+
+	gen := xmlgen.New(mustParseSchema(*xsdPath),
+		xmlgen.WithSeed(seed.value()),
+		xmlgen.WithRoot(*root),
+		xmlgen.WithSchemaLocation(*schemaLocation),
+	)
+	set, err := gen.Mutate(context.Background())
+	if err != nil {
+		log.Fatalf("Cannot generate the mutations: %v", err)
+	}
+
+	dir := sanitizeOutputPath(*outDir)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		log.Fatalf("Unable to create output directory: %v", err)
+	}
+	writeDocumentFile(set.Valid, filepath.Join(dir, "valid.xml"))
+	for _, m := range set.Mutations {
+		writeDocumentFile(m.Document, filepath.Join(dir, m.Name))
+	}
+	if *manifestPath == "" {
+		*manifestPath = filepath.Join(dir, "manifest.json")
+	}
+	writeJSONFile(*manifestPath, set.WriteManifest)
+	fmt.Fprintf(os.Stderr, "%d invalid documents, generated with -seed %d\n", len(set.Mutations), seed.value())
+	return 0
+}
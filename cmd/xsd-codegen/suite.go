@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/xmlgen"
+	"github.com/beevik/etree"
+)
+
+// runSuite implements "xsd-codegen suite": it writes a small set of documents covering every choice
+// branch, enumeration value, optional particle and substitution group member of the schema, and with
+// -boundary every boundary value of its restricted types, and a JSON coverage report. It returns the exit status.
+func runSuite(args []string) int {
+	fs := flag.NewFlagSet("suite", flag.ExitOnError)
+	xsdPath := fs.String("xsd", "", "Path to XSD file")
+	outDir := fs.String("out-dir", "", "Output directory of the documents")
+	reportPath := fs.String("report", "", "Output path of the JSON coverage report (default <out-dir>/coverage.json)")
+	root := fs.String("root", "", "Only generate documents of this global element (default: every global element)")
+	schemaLocation := fs.String("schema-location", "schema.xsd",
+		"Location of the schema in the xsi:schemaLocation attribute of the root element, empty to leave it out")
+	maxDepth := fs.Int("max-depth", xmlgen.DefaultMaxDepth, "Depth from which only the shortest valid content is generated")
+	maxElements := fs.Int("max-elements", xmlgen.DefaultMaxElements,
+		"Number of elements from which only the shortest valid completion is generated")
+	boundary := fs.Bool("boundary", false, "Also cover the boundary values of the restricted simple types")
+	var seed seedValue
+	fs.Var(&seed, "seed", "Seed of the generated documents (default random)")
+	if err := fs.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	if *xsdPath == "" || *outDir == "" {
+		log.Fatal("The -xsd and -out-dir flags are required.")
+	}
+
+	genOpts := []xmlgen.Option{
+		xmlgen.WithSeed(seed.value()),
+		xmlgen.WithRoot(*root),
+		xmlgen.WithSchemaLocation(*schemaLocation),
+		xmlgen.WithMaxDepth(*maxDepth),
+		xmlgen.WithMaxElements(*maxElements),
+	}
+	if *boundary {
+		genOpts = append(genOpts, xmlgen.WithBoundaryValues())
+	}
+	gen := xmlgen.New(mustParseSchema(*xsdPath), genOpts...)
+	suite, err := gen.Suite(context.Background())
+	if err != nil {
+		log.Fatalf("Cannot generate the suite: %v", err)
+	}
+
+	dir := sanitizeOutputPath(*outDir)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		log.Fatalf("Unable to create output directory: %v", err)
+	}
+	for _, d := range suite.Documents {
+		writeDocumentFile(d.Document, filepath.Join(dir, d.Name))
+	}
+	if *reportPath == "" {
+		*reportPath = filepath.Join(dir, "coverage.json")
+	}
+	writeJSONFile(*reportPath, suite.WriteReport)
+
+	uncovered := suite.Uncovered()
+	fmt.Fprintf(os.Stderr, "%d documents cover %d/%d cases, generated with -seed %d\n",
+		len(suite.Documents), len(suite.Coverage)-len(uncovered), len(suite.Coverage), seed.value())
+	for _, c := range uncovered {
+		fmt.Fprintf(os.Stderr, "not covered: %s %s %s\n", c.Kind, c.Construct, c.Case)
+	}
+	return 0
+}
+
+// writeDocumentFile writes doc indented with 2 spaces to path.
+func writeDocumentFile(doc *etree.Document, path string) {
+	doc.Indent(2)
+	if err := doc.WriteToFile(path); err != nil {
+		log.Fatalf("Unable to write output file: %v", err)
+	}
+}
+
+// writeJSONFile creates the file at path and writes a JSON report into it with write.
+func writeJSONFile(path string, write func(io.Writer) error) {
+	file, err := os.Create(sanitizeOutputPath(path))
+	if err != nil {
+		log.Fatalf("Unable to create report file: %v", err)
+	}
+	err = write(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Fatalf("Unable to write report file: %v", err)
+	}
+}
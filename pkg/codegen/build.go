@@ -0,0 +1,462 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+)
+
+// builder converts a parsed schema into the File data model consumed by the templates.
+type builder struct {
+	schema  *model.XSDSchema
+	root    *model.XSDOrigin // origin of components without one
+	file    *File
+	names   nameSet
+	choices ChoiceStyle
+	mapping *TypeMapping
+	fixture bool              // generate RandomX functions
+	builder bool              // generate NewX functions and XBuilder types
+	mapped  map[string]GoType // mapped Go types by type expression, for the pointer and slice policies
+	enums   map[string]*Enum  // enumerated simple types by XSD name
+	structs map[string]*Type  // named complex types by XSD name
+	imports map[string]bool
+	errs    []error // invalid values of the schema, reported by build
+
+	layout      *packageLayout  // Go package of each namespace, nil when everything goes into one package
+	pkg         string          // import path of the package being built
+	structTypes map[string]bool // Go type expressions of generated structs, including other packages' ones
+	enumTypes   map[string]bool // Go type expressions of generated enums, including other packages' ones
+}
+
+func newBuilder(schema *model.XSDSchema) *builder {
+	return &builder{
+		schema:  schema,
+		root:    schema.Origin(),
+		file:    &File{},
+		names:   nameSet{},
+		enums:   map[string]*Enum{},
+		structs: map[string]*Type{},
+		imports: map[string]bool{},
+		mapped:  map[string]GoType{},
+
+		structTypes: map[string]bool{},
+		enumTypes:   map[string]bool{},
+	}
+}
+
+// build declares every named type first so that references resolve regardless of declaration order,
+// then fills in enum values, struct fields and global element wrappers. It fails on the default and fixed
+// values of the schema that the generated NewX functions could not set.
+func (b *builder) build() (*File, error) {
+	for i := range b.schema.SimpleTypes {
+		b.declareEnum(&b.schema.SimpleTypes[i])
+	}
+	for i := range b.schema.ComplexTypes {
+		b.declareStruct(&b.schema.ComplexTypes[i])
+	}
+	for i := range b.schema.ComplexTypes {
+		ct := &b.schema.ComplexTypes[i]
+		if t := b.structs[ct.Name]; t != nil && t.Fields == nil && t.Attributes == nil && b.owns(t.origin) {
+			b.fillStruct(t, ct)
+		}
+	}
+	b.buildGlobalElements()
+	b.nameEnumValues()
+	if len(b.file.Types) > 0 {
+		b.imports[validationImport] = true
+	}
+	if b.fixture {
+		b.shortestContent()
+	}
+	if b.fixture && len(b.file.Types)+len(b.file.Enums) > 0 {
+		b.imports[fixtureImport] = true
+		b.imports[helpersImport] = true
+		b.file.Fixtures = true
+	}
+	b.file.Builders = b.builder
+
+	b.file.Imports = sortedKeys(b.imports)
+	b.file.TargetNamespace = b.schema.TargetNamespace
+	b.file.Namespaces = b.schema.Namespaces()
+	return b.file, errors.Join(b.errs...)
+}
+
+// declareEnum registers a named simpleType as a Go enum when it is a pure enumeration.
+func (b *builder) declareEnum(st *model.XSDSimpleType) {
+	if _, seen := b.enums[st.Name]; seen || !isEnumeration(st) {
+		return
+	}
+	enum := b.newEnum(st.Name, GoName(st.Name), st.Restriction, b.originOf(st.Origin))
+	enum.Documentation = st.Annotation.Text()
+	b.enums[st.Name] = enum
+}
+
+// newEnum claims the enum type name and keeps each enumerated value once; nameEnumValues names their
+// constants. Enums of other packages are named the same way but left out of the file.
+func (b *builder) newEnum(name, goName string, r *model.XSDRestriction, origin *model.XSDOrigin) *Enum {
+	enum := &Enum{
+		Name:      name,
+		GoName:    b.names.claim(goName),
+		Namespace: origin.TargetNamespace,
+		XSDType:   b.xsdBase(r.Base),
+		origin:    origin,
+	}
+	for _, v := range r.Enumerations {
+		if slices.ContainsFunc(enum.Values, func(e EnumValue) bool { return e.Value == v.Value }) {
+			continue
+		}
+		enum.Values = append(enum.Values, EnumValue{Value: v.Value, Documentation: v.Annotation.Text()})
+	}
+	if b.owns(origin) {
+		b.file.Enums = append(b.file.Enums, enum)
+		b.enumTypes[enum.GoName] = true
+		b.imports["fmt"] = true
+	}
+	return enum
+}
+
+// nameEnumValues mangles one constant name per value of the enums of the file, once every type is
+// named, so that constants take the suffixed names rather than the types they collide with.
+func (b *builder) nameEnumValues() {
+	for _, enum := range b.file.Enums {
+		for i := range enum.Values {
+			enum.Values[i].GoName = b.names.claim(enumConstName(enum.GoName, enum.Values[i].Value))
+		}
+	}
+}
+
+// declareStruct reserves the Go name of a named complexType without building its fields yet.
+func (b *builder) declareStruct(ct *model.XSDComplexType) {
+	if _, seen := b.structs[ct.Name]; seen {
+		return
+	}
+	origin := b.originOf(ct.Origin)
+	t := &Type{
+		Name:          ct.Name,
+		GoName:        b.names.claim(GoName(ct.Name)),
+		Namespace:     origin.TargetNamespace,
+		Path:          ct.Name,
+		Documentation: ct.Annotation.Text(),
+		origin:        origin,
+	}
+	b.structs[ct.Name] = t
+	if b.owns(origin) {
+		b.addType(t)
+	}
+}
+
+// addType appends a struct to the generated file.
+func (b *builder) addType(t *Type) {
+	b.file.Types = append(b.file.Types, t)
+	b.structTypes[t.GoName] = true
+}
+
+// fillStruct adds one field per child element and attribute of the complex type.
+func (b *builder) fillStruct(t *Type, ct *model.XSDComplexType) {
+	t.Fields = []*Field{}
+	fieldNames := nameSet{}
+	if ct.Sequence != nil {
+		b.addSequence(t, ct.Sequence, occurs{}, fieldNames)
+	}
+	if ct.Choice != nil {
+		b.addChoice(t, ct.Choice, occurs{}, fieldNames)
+	}
+	for _, attr := range ct.Attrs {
+		t.Attributes = append(t.Attributes, b.attributeField(t, attr, fieldNames))
+	}
+}
+
+// elementField describes the struct field for a child element, following references to global elements.
+func (b *builder) elementField(parent *Type, el *model.XSDElement, fieldNames nameSet) *Field {
+	decl := el
+	namespace := parent.origin.ElementNamespace(el.Form)
+	if el.Ref != "" {
+		namespace = ""
+		if target := b.globalElement(localName(el.Ref)); target != nil {
+			decl = target
+			namespace = b.originOf(target.Origin).TargetNamespace
+		}
+	}
+	name := decl.Name
+	if name == "" {
+		name = localName(el.Ref)
+	}
+	f := &Field{
+		Name:          name,
+		Namespace:     namespace,
+		GoName:        fieldNames.claim(b.fieldGoName(parent.Path+"/"+name, GoName(name))),
+		MinOccurs:     el.MinOccurs,
+		MaxOccurs:     el.MaxOccurs,
+		Fixed:         decl.Fixed,
+		Default:       decl.Default,
+		Documentation: elementDocumentation(el, decl),
+	}
+	f.Type, f.Restriction = b.elementType(parent, parent.GoName+GoName(name), decl)
+	f.XSDType = b.elementXSDType(decl)
+	b.describeValue(parent, f)
+	return f
+}
+
+// elementDocumentation prefers the annotation of the local declaration over the one of a referenced element.
+func elementDocumentation(el, decl *model.XSDElement) string {
+	if doc := el.Annotation.Text(); doc != "" {
+		return doc
+	}
+	return decl.Annotation.Text()
+}
+
+// elementType resolves the Go type of an element declaration, generating inline types under inlineName.
+func (b *builder) elementType(parent *Type, inlineName string, el *model.XSDElement) (string, *model.XSDRestriction) {
+	switch {
+	case el.Type != "":
+		return b.resolveType(el.Type)
+	case el.ComplexType != nil:
+		t := &Type{
+			Name:          el.Name,
+			GoName:        b.names.claim(inlineName),
+			Namespace:     parent.Namespace,
+			Path:          parent.Path + "/" + el.Name,
+			Documentation: el.ComplexType.Annotation.Text(),
+			origin:        parent.origin,
+		}
+		b.addType(t)
+		b.fillStruct(t, el.ComplexType)
+		return t.GoName, nil
+	case el.SimpleType != nil && el.SimpleType.Restriction != nil:
+		r := el.SimpleType.Restriction
+		if isEnumeration(el.SimpleType) {
+			enum := b.newEnum(el.Name, inlineName, r, parent.origin)
+			enum.Documentation = el.SimpleType.Annotation.Text()
+			return enum.GoName, r
+		}
+		goType, _ := b.resolveType(r.Base)
+		return goType, r
+	}
+	return "string", nil
+}
+
+// attributeField describes the struct field for an attribute; only use="required" attributes are mandatory.
+func (b *builder) attributeField(parent *Type, attr model.XSDAttribute, fieldNames nameSet) *Field {
+	goName := GoName(attr.Name)
+	if fieldNames[goName] {
+		goName += "Attr"
+	}
+	goName = b.fieldGoName(parent.Path+"/@"+attr.Name, goName)
+	f := &Field{
+		Name:          attr.Name,
+		Namespace:     parent.origin.AttributeNamespace(attr.Form),
+		GoName:        fieldNames.claim(goName),
+		MinOccurs:     "0",
+		Fixed:         attr.Fixed,
+		Default:       attr.Default,
+		Documentation: attr.Annotation.Text(),
+		IsAttribute:   true,
+	}
+	if attr.Use == "required" {
+		f.MinOccurs = "1"
+	}
+	f.Type, f.Restriction = b.resolveType(attr.Type)
+	f.XSDType = b.xsdBase(attr.Type)
+	b.describeValue(parent, f)
+	return f
+}
+
+// describeValue records how the field is validated, then applies the pointer policy.
+func (b *builder) describeValue(parent *Type, f *Field) {
+	f.IsStruct = b.isStruct(f.Type)
+	f.IsEnum = b.isEnum(f.Type)
+	f.Facets = newFacets("facets"+parent.GoName+f.GoName, f.Restriction, f.Fixed, f.XSDType)
+	if isRepeated(f.MaxOccurs) {
+		f.Slice = b.mapped[f.Type].Slice
+	}
+	b.pointerIfOptional(f)
+	if b.builder {
+		initial, err := b.initialValue(f)
+		if err != nil {
+			path := parent.Path + "/" + f.Name
+			if f.IsAttribute {
+				path = parent.Path + "/@" + f.Name
+			}
+			b.errs = append(b.errs, fmt.Errorf("%s: %w", path, err))
+		}
+		f.Initial = initial
+	}
+}
+
+// pointerIfOptional applies the pointer policy to optional single-valued fields. By default only struct-typed
+// fields become pointers: encoding/xml never omits struct values, so an absent value must be nil.
+func (b *builder) pointerIfOptional(f *Field) {
+	if f.MinOccurs != "0" || isRepeated(f.MaxOccurs) {
+		return
+	}
+	policy := b.mapped[f.Type].Optional
+	if policy == "" && b.mapping != nil {
+		policy = b.mapping.Optional
+	}
+	switch policy {
+	case PointerAlways:
+		f.Type = "*" + f.Type
+	case PointerNever:
+	default:
+		if f.IsStruct || isStructValue(f.Type) {
+			f.Type = "*" + f.Type
+		}
+	}
+}
+
+// fieldGoName returns the Go name configured for the field at path, or goName.
+func (b *builder) fieldGoName(path, goName string) string {
+	if name, ok := b.mapping.fieldName(path); ok {
+		return name
+	}
+	return goName
+}
+
+// resolveType maps a type QName to a Go type, returning the restriction of simple types along the way.
+func (b *builder) resolveType(qname string) (string, *model.XSDRestriction) {
+	local := localName(qname)
+	if t, ok := b.mapping.lookup(qname); ok {
+		return b.mappedType(t), b.restrictionOf(qname)
+	}
+	if !isBuiltinQName(qname) {
+		if t := b.structs[local]; t != nil {
+			return b.qualify(t.GoName, t.origin, b.structTypes), nil
+		}
+		if e := b.enums[local]; e != nil {
+			return b.qualify(e.GoName, e.origin, b.enumTypes), b.simpleType(local).Restriction
+		}
+		if st := b.simpleType(local); st != nil && st.Restriction != nil && st.Restriction.Base != qname {
+			goType, _ := b.resolveType(st.Restriction.Base)
+			return goType, st.Restriction
+		}
+	}
+	return b.builtinType(local), nil
+}
+
+// mappedType registers the imports of a type configured in the TypeMapping and returns its Go expression.
+func (b *builder) mappedType(t GoType) string {
+	ref, importPath := goTypeRef(t.Type)
+	if importPath != "" {
+		b.imports[importPath] = true
+	}
+	mapped := GoType{Type: ref, Optional: t.Optional}
+	if t.Slice != "" {
+		slice, sliceImport := goTypeRef(t.Slice)
+		if sliceImport != "" {
+			b.imports[sliceImport] = true
+		}
+		mapped.Slice = slice
+	}
+	b.mapped[ref] = mapped
+	return ref
+}
+
+// restrictionOf returns the restriction of a schema simple type, so that mapped types keep their facets.
+func (b *builder) restrictionOf(qname string) *model.XSDRestriction {
+	if isBuiltinQName(qname) {
+		return nil
+	}
+	if st := b.simpleType(localName(qname)); st != nil {
+		return st.Restriction
+	}
+	return nil
+}
+
+// buildGlobalElements emits a root type carrying XMLName for every global element with complex content.
+func (b *builder) buildGlobalElements() {
+	seen := map[string]bool{}
+	for i := range b.schema.Elements {
+		el := &b.schema.Elements[i]
+		origin := b.originOf(el.Origin)
+		if seen[el.Name] || !b.owns(origin) {
+			continue
+		}
+		seen[el.Name] = true
+		t := &Type{
+			Name:          el.Name,
+			XMLName:       el.Name,
+			Namespace:     origin.TargetNamespace,
+			Path:          el.Name,
+			Documentation: el.Annotation.Text(),
+			origin:        origin,
+		}
+		switch {
+		case el.ComplexType != nil:
+			t.GoName = b.names.claim(GoName(el.Name))
+			b.addType(t)
+			b.fillStruct(t, el.ComplexType)
+		case el.Type != "" && !isBuiltinQName(el.Type) && b.structs[localName(el.Type)] != nil:
+			embedded := b.structs[localName(el.Type)]
+			t.GoName = b.names.claim(GoName(el.Name))
+			t.Embeds = b.qualify(embedded.GoName, embedded.origin, b.structTypes)
+			b.addType(t)
+		default:
+			continue
+		}
+		b.imports["encoding/xml"] = true
+	}
+}
+
+func (b *builder) isStruct(goType string) bool {
+	return b.structTypes[goType]
+}
+
+func (b *builder) isEnum(goType string) bool {
+	return b.enumTypes[goType]
+}
+
+func (b *builder) simpleType(name string) *model.XSDSimpleType {
+	for i := range b.schema.SimpleTypes {
+		if b.schema.SimpleTypes[i].Name == name {
+			return &b.schema.SimpleTypes[i]
+		}
+	}
+	return nil
+}
+
+func (b *builder) globalElement(name string) *model.XSDElement {
+	for i := range b.schema.Elements {
+		if b.schema.Elements[i].Name == name {
+			return &b.schema.Elements[i]
+		}
+	}
+	return nil
+}
+
+// isEnumeration reports whether a simpleType is a restriction made only of enumeration facets.
+func isEnumeration(st *model.XSDSimpleType) bool {
+	r := st.Restriction
+	return r != nil && len(r.Enumerations) > 0 && r.Pattern == nil && r.MinIncl == nil && r.MaxExcl == nil
+}
+
+// isRepeated reports whether a maxOccurs value allows more than one occurrence.
+func isRepeated(maxOccurs string) bool {
+	return maxOccurs == "unbounded" || helpers.ParseOccurs(maxOccurs, 1) > 1
+}
+
+// localName strips the namespace prefix of a QName.
+func localName(qname string) string {
+	if i := strings.LastIndex(qname, ":"); i >= 0 {
+		return qname[i+1:]
+	}
+	return qname
+}
+
+// isBuiltinQName reports whether qname uses one of the conventional XML Schema prefixes.
+func isBuiltinQName(qname string) bool {
+	return strings.HasPrefix(qname, "xs:") || strings.HasPrefix(qname, "xsd:")
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,156 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/xsdtypes"
+)
+
+// initialValue returns the Go expression of the fixed or default value of a single-valued field,
+// used by the generated NewX functions, or "" when the field has none. Values of predeclared types
+// become literals; other types are decoded at run time with xsdtypes.MustDecode, once the value is
+// decoded here, so that invalid values of the schema are reported now rather than by a panic of NewX.
+func (b *builder) initialValue(f *Field) (string, error) {
+	lexical := f.Fixed
+	if lexical == "" {
+		lexical = f.Default
+	}
+	if lexical == "" || f.Choice != nil || isRepeated(f.MaxOccurs) {
+		return "", nil
+	}
+	if err := checkInitialValue(f, lexical); err != nil {
+		return "", fmt.Errorf("invalid %s value %q: %w", initialKind(f), lexical, err)
+	}
+	if f.IsEnum && !strings.HasPrefix(f.Type, "*") {
+		return f.Type + "(" + strconv.Quote(lexical) + ")", nil
+	}
+	if literal, ok := goLiteral(f.Type, strings.TrimSpace(lexical)); ok {
+		return literal, nil
+	}
+	b.imports[xsdtypesImport] = true
+	return "xsdtypes.MustDecode[" + f.Type + "](" + strconv.Quote(lexical) + ")", nil
+}
+
+// initialKind names the schema value a field starts with.
+func initialKind(f *Field) string {
+	if f.Fixed != "" {
+		return "fixed"
+	}
+	return "default"
+}
+
+// checkInitialValue decodes lexical as the Go type of f would, or as its built-in XSD type for types
+// mapped to other Go types, and checks it against the enumerated values and facets of f.
+func checkInitialValue(f *Field, lexical string) error {
+	if f.IsEnum {
+		if r := f.Restriction; r != nil && !slices.ContainsFunc(r.Enumerations, func(e model.XSDValue) bool {
+			return e.Value == lexical
+		}) {
+			return errors.New("not one of the enumerated values")
+		}
+		return nil
+	}
+	var value any = lexical
+	decode, ok := decoders[strings.TrimPrefix(f.Type, "*")]
+	if !ok {
+		decode = decoders[builtinTypes[localName(f.XSDType)]]
+	}
+	if decode != nil {
+		var err error
+		if value, err = decode(lexical); err != nil {
+			return err
+		}
+	}
+	if f.Facets == nil {
+		return nil
+	}
+	var errs validation.Errors
+	validation.CheckValue("", value, f.Facets.validation(), false, &errs)
+	if len(errs) > 0 {
+		return errors.New(errs[0].Message)
+	}
+	return nil
+}
+
+// decoders decode lexical values into the Go types of the built-in XSD types.
+var decoders = map[string]func(lexical string) (any, error){
+	"bool":    decodeAs[bool],
+	"float32": decodeAs[float32], "float64": decodeAs[float64],
+	"int": decodeAs[int], "int8": decodeAs[int8], "int16": decodeAs[int16], "int32": decodeAs[int32],
+	"int64": decodeAs[int64], "uint8": decodeAs[uint8], "uint16": decodeAs[uint16], "uint32": decodeAs[uint32],
+	"uint64":                decodeAs[uint64],
+	"xsdtypes.Decimal":      decodeAs[xsdtypes.Decimal],
+	"xsdtypes.Integer":      decodeAs[xsdtypes.Integer],
+	"xsdtypes.Date":         decodeAs[xsdtypes.Date],
+	"xsdtypes.DateTime":     decodeAs[xsdtypes.DateTime],
+	"xsdtypes.Time":         decodeAs[xsdtypes.Time],
+	"xsdtypes.Duration":     decodeAs[xsdtypes.Duration],
+	"xsdtypes.GYear":        decodeAs[xsdtypes.GYear],
+	"xsdtypes.GYearMonth":   decodeAs[xsdtypes.GYearMonth],
+	"xsdtypes.HexBinary":    decodeAs[xsdtypes.HexBinary],
+	"xsdtypes.Base64Binary": decodeAs[xsdtypes.Base64Binary],
+	"xsdtypes.QName":        decodeAs[xsdtypes.QName],
+}
+
+func decodeAs[T any](lexical string) (any, error) {
+	var v T
+	err := xsdtypes.Decode(lexical, &v)
+	return v, err
+}
+
+// goLiteral renders a lexical value as a literal of a predeclared Go type, normalizing numbers
+// so that "007" does not turn into an octal literal.
+func goLiteral(goType, lexical string) (string, bool) {
+	switch goType {
+	case "string":
+		return strconv.Quote(lexical), true
+	case "bool":
+		v, err := strconv.ParseBool(lexical)
+		return strconv.FormatBool(v), err == nil && lexical != "t" && lexical != "f"
+	case "int", "int8", "int16", "int32", "int64":
+		v, err := strconv.ParseInt(lexical, 10, 64)
+		return strconv.FormatInt(v, 10), err == nil
+	case "uint8", "uint16", "uint32", "uint64":
+		v, err := strconv.ParseUint(strings.TrimPrefix(lexical, "+"), 10, 64)
+		return strconv.FormatUint(v, 10), err == nil
+	case "float32", "float64":
+		v, err := strconv.ParseFloat(lexical, 64)
+		return strconv.FormatFloat(v, 'g', -1, 64), err == nil && !strings.ContainsAny(lexical, "INFa")
+	}
+	return "", false
+}
+
+// builderField pairs a field with the type whose builder sets it.
+type builderField struct {
+	Type  *Type
+	Field *Field
+}
+
+func fieldOfType(t *Type, f *Field) builderField {
+	return builderField{Type: t, Field: f}
+}
+
+// required reports whether a builder must be given a value for f: required elements, attributes
+// and choices without a fixed or default value.
+func required(f *Field) bool {
+	return f.MinOccurs != "0" && f.Initial == ""
+}
+
+// newFunc returns the name of the NewX function of a generated type, keeping its package qualifier.
+func newFunc(goType string) string {
+	return prefixFunc("New", goType)
+}
+
+// prefixFunc prefixes the name of a generated type, keeping its package qualifier:
+// "*common.Address" gives "common.<prefix>Address".
+func prefixFunc(prefix, goType string) string {
+	goType = strings.TrimPrefix(goType, "*")
+	i := strings.LastIndex(goType, ".") + 1
+	return goType[:i] + prefix + goType[i:]
+}
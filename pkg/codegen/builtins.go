@@ -0,0 +1,64 @@
+package codegen
+
+import "strings"
+
+// xsdtypesImport is the runtime package providing Go types for XSD built-ins without a lossless native equivalent.
+const xsdtypesImport = "github.com/Patrick-Ivann/xsd-codegen/pkg/xsdtypes"
+
+// builtinTypes maps the local name of XSD built-in types to Go types.
+// Types not listed here, such as string-derived types, map to string.
+var builtinTypes = map[string]string{
+	"boolean":            "bool",
+	"float":              "float32",
+	"double":             "float64",
+	"decimal":            "xsdtypes.Decimal",
+	"integer":            "xsdtypes.Integer",
+	"int":                "int",
+	"long":               "int64",
+	"short":              "int16",
+	"byte":               "int8",
+	"nonNegativeInteger": "xsdtypes.Integer",
+	"positiveInteger":    "xsdtypes.Integer",
+	"nonPositiveInteger": "xsdtypes.Integer",
+	"negativeInteger":    "xsdtypes.Integer",
+	"unsignedLong":       "uint64",
+	"unsignedInt":        "uint32",
+	"unsignedShort":      "uint16",
+	"unsignedByte":       "uint8",
+	"date":               "xsdtypes.Date",
+	"dateTime":           "xsdtypes.DateTime",
+	"time":               "xsdtypes.Time",
+	"duration":           "xsdtypes.Duration",
+	"gYear":              "xsdtypes.GYear",
+	"gYearMonth":         "xsdtypes.GYearMonth",
+	"hexBinary":          "xsdtypes.HexBinary",
+	"base64Binary":       "xsdtypes.Base64Binary",
+	"QName":              "xsdtypes.QName",
+}
+
+// builtinType returns the Go type of an XSD built-in type and registers the xsdtypes import when needed.
+func (b *builder) builtinType(local string) string {
+	goType, ok := builtinTypes[local]
+	if !ok {
+		return "string"
+	}
+	if strings.HasPrefix(goType, "xsdtypes.") {
+		b.imports[xsdtypesImport] = true
+	}
+	return goType
+}
+
+// integerBounds are the bounds of the built-in integer types without a fixed size, which their Go type,
+// xsdtypes.Integer, does not enforce: the generated Validate methods check them as facets.
+var integerBounds = map[string][2]string{
+	"nonNegativeInteger": {"0", ""},
+	"positiveInteger":    {"1", ""},
+	"nonPositiveInteger": {"", "0"},
+	"negativeInteger":    {"", "-1"},
+}
+
+// isStructValue reports whether an xsdtypes type is a struct, which encoding/xml cannot omit when empty.
+// The binary types are byte slices and are omitted like any other empty slice.
+func isStructValue(goType string) bool {
+	return strings.HasPrefix(goType, "xsdtypes.") && !strings.HasSuffix(goType, "Binary")
+}
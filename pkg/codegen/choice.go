@@ -0,0 +1,190 @@
+package codegen
+
+import (
+	"strings"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+)
+
+// ChoiceStyle selects how xs:choice groups are represented in the generated code.
+type ChoiceStyle string
+
+const (
+	// ChoiceFields generates one optional field per branch; Validate checks that at most one is set.
+	ChoiceFields ChoiceStyle = "fields"
+	// ChoiceInterface generates a sealed interface with one struct type per branch, so only
+	// a single branch can be held at a time. The parent type gets an UnmarshalXML method
+	// that picks the branch type from the element name.
+	ChoiceInterface ChoiceStyle = "interface"
+)
+
+// occurs tracks whether the model groups enclosing a particle make it optional or repeatable.
+type occurs struct {
+	optional bool
+	repeated bool
+}
+
+// of combines the enclosing occurrence with the minOccurs/maxOccurs of a nested group.
+func (o occurs) of(minOccurs, maxOccurs string) occurs {
+	return occurs{optional: o.optional || minOccurs == "0", repeated: o.repeated || isRepeated(maxOccurs)}
+}
+
+// addSequence flattens a sequence into fields of t, keeping document order.
+func (b *builder) addSequence(t *Type, seq *model.XSDSequence, outer occurs, fieldNames nameSet) {
+	o := outer.of(seq.MinOccurs, seq.MaxOccurs)
+	for _, p := range seq.Particles() {
+		switch {
+		case p.Element != nil:
+			t.Fields = append(t.Fields, b.groupField(t, p.Element, o, fieldNames))
+		case p.Choice != nil:
+			b.addChoice(t, p.Choice, o, fieldNames)
+		case p.Sequence != nil:
+			b.addSequence(t, p.Sequence, o, fieldNames)
+		}
+	}
+}
+
+// groupField describes an element of a model group, loosening its occurrence to the one of the group.
+func (b *builder) groupField(t *Type, el *model.XSDElement, o occurs, fieldNames nameSet) *Field {
+	decl := *el
+	if o.optional {
+		decl.MinOccurs = "0"
+	}
+	if o.repeated && !isRepeated(decl.MaxOccurs) {
+		decl.MaxOccurs = "unbounded"
+	}
+	return b.elementField(t, &decl, fieldNames)
+}
+
+// addChoice adds the branches of a choice to t. Choices with sequence branches cannot be sealed,
+// so they always fall back to optional fields.
+func (b *builder) addChoice(t *Type, ch *model.XSDChoice, outer occurs, fieldNames nameSet) {
+	o := outer.of(ch.MinOccurs, ch.MaxOccurs)
+	if b.choices == ChoiceInterface && !hasSequenceBranch(ch) {
+		b.addSealedChoice(t, ch, o, fieldNames)
+		return
+	}
+	c := &Choice{Required: !o.optional}
+	b.addBranchFields(t, ch, c, occurs{optional: true, repeated: o.repeated}, fieldNames)
+	// Several branches may be present at once in a repeated choice, so there is nothing to check.
+	if !o.repeated {
+		t.Choices = append(t.Choices, c)
+	}
+}
+
+// addBranchFields adds one optional field per branch of a choice, flattening nested groups.
+func (b *builder) addBranchFields(t *Type, ch *model.XSDChoice, c *Choice, o occurs, fieldNames nameSet) {
+	for _, p := range ch.Particles() {
+		switch {
+		case p.Element != nil:
+			c.Required = c.Required && p.Element.MinOccurs != "0"
+			f := b.groupField(t, p.Element, o, fieldNames)
+			t.Fields = append(t.Fields, f)
+			c.Branches = append(c.Branches, &Branch{Field: f})
+		case p.Choice != nil:
+			c.Required = c.Required && p.Choice.MinOccurs != "0"
+			b.addBranchFields(t, p.Choice, c, o.of(p.Choice.MinOccurs, p.Choice.MaxOccurs), fieldNames)
+		case p.Sequence != nil:
+			// The elements of a sequence branch are not tracked by the choice check.
+			c.Required = false
+			b.addSequence(t, p.Sequence, o, fieldNames)
+		}
+	}
+}
+
+// addSealedChoice adds a holder field typed with a sealed interface and one branch type per element.
+// Names join the branch names with "Or": the email|phone choice of Contact is held in field
+// EmailOrPhone of interface type ContactEmailOrPhone, implemented by ContactEmail and ContactPhone.
+func (b *builder) addSealedChoice(t *Type, ch *model.XSDChoice, o occurs, fieldNames nameSet) {
+	elements, required := choiceElements(ch)
+	c := &Choice{Required: required && !o.optional}
+	var goNames, xmlNames []string
+	for _, el := range elements {
+		c.Branches = append(c.Branches, b.sealedBranch(t, el))
+		goNames = append(goNames, GoName(c.Branches[len(c.Branches)-1].Field.Name))
+		xmlNames = append(xmlNames, c.Branches[len(c.Branches)-1].Field.Name)
+	}
+	holder := strings.Join(goNames, "Or")
+	c.Interface = b.names.claim(t.GoName + holder)
+	c.Field = &Field{
+		// The tag name is never written: branch types encode themselves under their own element name.
+		Name:      strings.Join(xmlNames, "|"),
+		GoName:    fieldNames.claim(holder),
+		Type:      c.Interface,
+		MinOccurs: "0",
+		MaxOccurs: ch.MaxOccurs,
+		Choice:    c,
+	}
+	if c.Required {
+		c.Field.MinOccurs = minOccurs(ch.MinOccurs)
+	}
+	if o.repeated && !isRepeated(c.Field.MaxOccurs) {
+		c.Field.MaxOccurs = "unbounded"
+	}
+	t.Fields = append(t.Fields, c.Field)
+	t.Choices = append(t.Choices, c)
+	b.imports["encoding/xml"] = true
+}
+
+// sealedBranch describes the branch type of one element of a sealed choice; its value is held in field Value.
+func (b *builder) sealedBranch(t *Type, el *model.XSDElement) *Branch {
+	decl := *el
+	if !isRepeated(decl.MaxOccurs) {
+		decl.MinOccurs = "" // the value of a chosen branch is always present
+	}
+	f := b.elementField(t, &decl, nameSet{})
+	branch := &Branch{GoName: b.names.claim(t.GoName + f.GoName), Field: f}
+	f.GoName = "Value"
+	if f.Facets != nil {
+		f.Facets.Var = "facets" + branch.GoName
+	}
+	return branch
+}
+
+// choiceElements flattens nested choices into the list of alternative elements and reports whether
+// one of them must be present.
+func choiceElements(ch *model.XSDChoice) ([]*model.XSDElement, bool) {
+	var elements []*model.XSDElement
+	required := true
+	for _, p := range ch.Particles() {
+		switch {
+		case p.Element != nil:
+			elements = append(elements, p.Element)
+			required = required && p.Element.MinOccurs != "0"
+		case p.Choice != nil:
+			nested, nestedRequired := choiceElements(p.Choice)
+			elements = append(elements, nested...)
+			required = required && nestedRequired && p.Choice.MinOccurs != "0"
+		}
+	}
+	return elements, required
+}
+
+// hasSequenceBranch reports whether a choice, or a choice nested in it, has a sequence alternative.
+func hasSequenceBranch(ch *model.XSDChoice) bool {
+	for _, p := range ch.Particles() {
+		if p.Sequence != nil || (p.Choice != nil && hasSequenceBranch(p.Choice)) {
+			return true
+		}
+	}
+	return false
+}
+
+// branchNames lists the element names of the branches of a choice, as in "email, phone".
+func branchNames(c *Choice) string {
+	names := make([]string, len(c.Branches))
+	for i, branch := range c.Branches {
+		names[i] = branch.Field.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// choiceBranch pairs a branch with its choice for the templates decoding it.
+type choiceBranch struct {
+	Choice *Choice
+	Branch *Branch
+}
+
+func branchOf(c *Choice, branch *Branch) choiceBranch {
+	return choiceBranch{Choice: c, Branch: branch}
+}
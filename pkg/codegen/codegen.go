@@ -0,0 +1,205 @@
+// Package codegen generates Go source code from a parsed XSD schema.
+//
+// Named complexTypes become structs, global elements with complex content become root structs
+// carrying an XMLName, and enumerated simpleTypes become named string types with one constant per value.
+//
+// Enumeration constants are named after their type followed by the value, where every run of letters
+// and digits is capitalised and everything else is dropped: "N/A" in type Status becomes StatusNA and
+// "1st" becomes Status1st. A value without letters or digits maps to <Type>Empty. When two values mangle
+// to the same identifier, the later one in schema order gets a "_2", "_3", ... suffix.
+//
+// By default the branches of an xs:choice are optional fields of the enclosing struct. With ChoiceInterface
+// an email|phone choice of Contact becomes field EmailOrPhone of the sealed interface type ContactEmailOrPhone,
+// implemented by ContactEmail and ContactPhone, which hold the element in their Value field.
+//
+// Struct tags carry the namespace of global elements and of qualified local elements and attributes.
+// GeneratePackages splits the types into one package per namespace mapped in TypeMapping.Packages.
+//
+// With Options.Fixtures every type also gets a RandomX function filling it through a helpers.ValueGenerator,
+// and with Options.Builders a NewX constructor applying fixed and default values and an XBuilder type.
+//
+// The source is rendered with text/template from the File data model. Options.TemplateDir lets users
+// replace any of the built-in templates while keeping the same data model and template functions.
+package codegen
+
+//go:generate go run ../../cmd/xsd-codegen -xsd testdata/golden.xsd -go-out internal/golden/golden.go -go-package golden -go-fixtures -go-builders
+//go:generate go run ../../cmd/xsd-codegen -xsd testdata/golden.xsd -go-out internal/sealed/sealed.go -go-package sealed -go-choice interface -go-fixtures
+//go:generate go run ../../cmd/xsd-codegen -xsd testdata/recursive.xsd -go-out internal/recursive/recursive.go -go-package recursive -go-fixtures
+//go:generate go run ../../cmd/xsd-codegen -xsd testdata/multins/orders.xsd -go-types testdata/multins/packages.yaml -go-out-dir internal/multins -go-import-path github.com/Patrick-Ivann/xsd-codegen/pkg/codegen/internal/multins -go-package multins -go-fixtures
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+)
+
+// DefaultPackage is the package name used when Options.Package is empty.
+const DefaultPackage = "schema"
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// Options controls the generated Go file.
+type Options struct {
+	// Package is the name of the generated Go package.
+	Package string
+	// ImportPath is the import path of the generated package. GeneratePackages needs it when other
+	// packages refer to its types.
+	ImportPath string
+	// ChoiceStyle selects the representation of xs:choice groups; the zero value means ChoiceFields.
+	ChoiceStyle ChoiceStyle
+	// TemplateDir is a directory of *.tmpl files loaded after the built-in templates. A definition
+	// with the name of a built-in template ("header", "enum", "type", "validate", "choice", ...)
+	// replaces it, and a file named file.tmpl replaces the whole file layout.
+	TemplateDir string
+	// TypeMapping overrides Go types, field names and the pointer policy; nil keeps the defaults.
+	TypeMapping *TypeMapping
+	// Fixtures adds a RandomX(g helpers.ValueGenerator) X function per generated type, filling it with
+	// random values like the XML generator does.
+	Fixtures bool
+	// Builders adds a NewX() *X function per generated type, holding the fixed and default values of
+	// the schema, and an XBuilder type with one WithField method per field and a Build method checking
+	// that required values were set.
+	Builders bool
+}
+
+// Generate renders the Go source for every type of the schema into a single package and returns it gofmt-ed.
+// If formatting fails the unformatted source is returned alongside the error to ease debugging.
+func Generate(schema *model.XSDSchema, opts Options) ([]byte, error) {
+	file, err := Describe(schema, opts)
+	if err != nil {
+		return nil, err
+	}
+	return render(file, opts.TemplateDir)
+}
+
+// Describe returns the data model Generate hands to the templates, without rendering it. Tools
+// driving the generated code use it to find the Go type of each global element.
+func Describe(schema *model.XSDSchema, opts Options) (*File, error) {
+	b, err := newConfiguredBuilder(schema, opts)
+	if err != nil {
+		return nil, err
+	}
+	file, err := b.build()
+	if err != nil {
+		return nil, err
+	}
+	file.Package = packageNameOr(opts.Package)
+	return file, nil
+}
+
+// newConfiguredBuilder checks opts and returns a builder applying them.
+func newConfiguredBuilder(schema *model.XSDSchema, opts Options) (*builder, error) {
+	switch opts.ChoiceStyle {
+	case "", ChoiceFields, ChoiceInterface:
+	default:
+		return nil, fmt.Errorf("unknown choice style %q", opts.ChoiceStyle)
+	}
+	b := newBuilder(schema)
+	b.choices = opts.ChoiceStyle
+	b.mapping = opts.TypeMapping
+	b.fixture = opts.Fixtures
+	b.builder = opts.Builders
+	return b, nil
+}
+
+// render executes the templates on file and formats the result.
+func render(file *File, templateDir string) ([]byte, error) {
+	tmpl, err := parseTemplates(templateDir)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "file.tmpl", file); err != nil {
+		return nil, fmt.Errorf("execute templates: %w", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return buf.Bytes(), fmt.Errorf("format generated code: %w", err)
+	}
+	return src, nil
+}
+
+// parseTemplates loads the built-in templates, then the user templates of dir, if any.
+func parseTemplates(dir string) (*template.Template, error) {
+	tmpl, err := template.New("codegen").Funcs(funcMap()).ParseFS(templateFS, "templates/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("parse templates: %w", err)
+	}
+	if dir == "" {
+		return tmpl, nil
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, fmt.Errorf("list templates: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no *.tmpl file in template directory %s", dir)
+	}
+	if tmpl, err = tmpl.ParseFiles(files...); err != nil {
+		return nil, fmt.Errorf("parse user templates: %w", err)
+	}
+	return tmpl, nil
+}
+
+// goType returns the Go type of a field, turning repeated elements into slices.
+func goType(typ, maxOccurs string) string {
+	if isRepeated(maxOccurs) {
+		return "[]" + typ
+	}
+	return typ
+}
+
+// unexported lower-cases the first letter of a Go identifier.
+func unexported(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// omit returns the omitempty option for optional elements and attributes.
+func omit(minOccurs string) string {
+	if minOccurs == "0" {
+		return ",omitempty"
+	}
+	return ""
+}
+
+// restrictionTag renders the value facets of a restriction as an `xsd:"..."` struct tag.
+// Enumerations are left out because they are enforced by the generated enum types.
+func restrictionTag(r *model.XSDRestriction) string {
+	if r == nil {
+		return ""
+	}
+	var facets []string
+	for _, facet := range []struct {
+		name  string
+		value *model.XSDValue
+	}{
+		{"minInclusive", r.MinIncl}, {"maxInclusive", r.MaxIncl},
+		{"minExclusive", r.MinExcl}, {"maxExclusive", r.MaxExcl},
+		{"length", r.Length}, {"minLength", r.MinLength}, {"maxLength", r.MaxLength},
+		{"totalDigits", r.TotalDigits}, {"fractionDigits", r.FractionDigits},
+	} {
+		if facet.value != nil {
+			facets = append(facets, facet.name+"="+facet.value.Value)
+		}
+	}
+	if r.Pattern != nil {
+		facets = append(facets, "pattern="+r.Pattern.Value)
+	}
+	if len(facets) == 0 {
+		return ""
+	}
+	// Struct tags live in a raw string literal, so backquotes must be escaped inside the quoted value.
+	quoted := strings.ReplaceAll(strconv.Quote(strings.Join(facets, ";")), "`", `\x60`)
+	return " xsd:" + quoted
+}
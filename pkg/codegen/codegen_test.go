@@ -0,0 +1,327 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoName(t *testing.T) {
+	cases := map[string]string{
+		"purchaseOrder":  "PurchaseOrder",
+		"USPrice":        "USPrice",
+		"ship-date":      "ShipDate",
+		"ship_to.street": "ShipToStreet",
+		"1st":            "X1st",
+		"":               "X",
+	}
+	for input, expected := range cases {
+		assert.Equal(t, expected, GoName(input), "GoName(%q)", input)
+	}
+}
+
+func TestGenerateEnumFromImportedSchema(t *testing.T) {
+	schema, err := parser.ParseXSD(filepath.Join("..", "parser", "testdata", "with_import.xsd"), nil)
+	require.NoError(t, err)
+
+	src, err := Generate(schema, Options{Package: "imported"})
+	require.NoError(t, err)
+
+	out := string(src)
+	assert.Contains(t, out, "package imported")
+	assert.Contains(t, out, "type ImportedType string")
+	assert.Contains(t, out, `ImportedTypeA ImportedType = "A"`)
+	assert.Contains(t, out, `ImportedTypeB ImportedType = "B"`)
+	assert.Contains(t, out, "func (v ImportedType) IsValid() bool")
+	assert.Contains(t, out, "func (v *ImportedType) UnmarshalText(text []byte) error")
+	assert.Contains(t, out, "func (v ImportedType) MarshalText() ([]byte, error)")
+}
+
+func TestGenerateEnumNameMangling(t *testing.T) {
+	schema := &model.XSDSchema{
+		SimpleTypes: []model.XSDSimpleType{
+			{
+				Name: "status",
+				Restriction: &model.XSDRestriction{
+					Base: "xs:string",
+					Enumerations: []model.XSDValue{
+						{Value: "N/A"}, {Value: "NA"}, {Value: "1st"}, {Value: ""}, {Value: "in-progress"},
+					},
+				},
+			},
+		},
+	}
+
+	file, err := newBuilder(schema).build()
+	require.NoError(t, err)
+	require.Len(t, file.Enums, 1)
+
+	var names []string
+	for _, v := range file.Enums[0].Values {
+		names = append(names, v.GoName)
+	}
+	assert.Equal(t, []string{"StatusNA", "StatusNA_2", "Status1st", "StatusEmpty", "StatusInProgress"}, names)
+}
+
+func TestGenerateEnumConstantsAfterTypes(t *testing.T) {
+	schema := &model.XSDSchema{
+		SimpleTypes: []model.XSDSimpleType{{
+			Name: "status",
+			Restriction: &model.XSDRestriction{
+				Base:         "xs:string",
+				Enumerations: []model.XSDValue{{Value: "A"}, {Value: "N/A"}, {Value: "A"}, {Value: "NA"}},
+			},
+		}},
+		ComplexTypes: []model.XSDComplexType{{
+			Name:  "statusNA",
+			Attrs: []model.XSDAttribute{{Name: "status", Type: "status"}},
+		}},
+	}
+
+	file, err := newBuilder(schema).build()
+	require.NoError(t, err)
+	require.Len(t, file.Enums, 1)
+	var names []string
+	for _, v := range file.Enums[0].Values {
+		names = append(names, v.GoName)
+	}
+	assert.Equal(t, []string{"StatusA", "StatusNA_2", "StatusNA_3"}, names, "duplicate values get one constant")
+	assert.Equal(t, "StatusNA", file.Types[0].GoName, "types keep their name over constants")
+
+	src, err := Generate(schema, Options{})
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "case StatusA, StatusNA_2, StatusNA_3:")
+}
+
+func TestGenerateInlineEnumAndStructs(t *testing.T) {
+	schema := &model.XSDSchema{
+		ComplexTypes: []model.XSDComplexType{
+			{
+				Name: "order",
+				Sequence: &model.XSDSequence{
+					Elements: []model.XSDElement{
+						{
+							Name: "priority",
+							SimpleType: &model.XSDSimpleType{
+								Restriction: &model.XSDRestriction{
+									Base:         "xs:string",
+									Enumerations: []model.XSDValue{{Value: "low"}, {Value: "high"}},
+								},
+							},
+						},
+						{Name: "line", Type: "tns:order", MinOccurs: "0"},
+					},
+				},
+				Attrs: []model.XSDAttribute{{Name: "id", Type: "xs:int", Use: "required"}},
+			},
+		},
+	}
+
+	src, err := Generate(schema, Options{})
+	require.NoError(t, err)
+
+	out := string(src)
+	assert.Contains(t, out, "package schema")
+	assert.Contains(t, out, "type OrderPriority string")
+	assert.Contains(t, out, "OrderPriorityHigh OrderPriority = \"high\"")
+	assert.Regexp(t, `Priority\s+OrderPriority\s+`+"`"+`xml:"priority"`+"`", out)
+	assert.Regexp(t, `Line\s+\*Order\s+`+"`"+`xml:"line,omitempty"`+"`", out)
+	assert.Regexp(t, `Id\s+int\s+`+"`"+`xml:"id,attr"`+"`", out)
+}
+
+func TestGenerateCompleteSchema(t *testing.T) {
+	schema, err := parser.ParseXSD(filepath.Join("..", "..", "complete.xsd"), nil)
+	require.NoError(t, err)
+
+	src, err := Generate(schema, Options{})
+	require.NoError(t, err)
+
+	out := string(src)
+	assert.Contains(t, out, "type PurchaseOrder struct")
+	assert.Contains(t, out, "XMLName xml.Name `xml:\"http://tempuri.org/PurchaseOrderSchema.xsd purchaseOrder\"`")
+	assert.Contains(t, out, "Item []ItemsItem `xml:\"http://tempuri.org/PurchaseOrderSchema.xsd item\"`")
+	assert.Regexp(t, `PartNum\s+string\s+`+"`"+`xml:"partNum,attr,omitempty"`+"`", out, "attributes stay unqualified")
+	assert.Contains(t, out, `"github.com/Patrick-Ivann/xsd-codegen/pkg/xsdtypes"`)
+	assert.Regexp(t, `Zip\s+xsdtypes.Decimal\s`, out)
+	assert.Regexp(t, `ShipDate\s+\*xsdtypes.Date\s`, out)
+	assert.Regexp(t, `ConfirmDate\s+xsdtypes.Date\s`, out)
+}
+
+func TestGoldenFilesUpToDate(t *testing.T) {
+	for _, c := range []struct {
+		schema string
+		opts   Options
+	}{
+		{"golden.xsd", Options{Package: "golden", Fixtures: true, Builders: true}},
+		{"golden.xsd", Options{Package: "sealed", ChoiceStyle: ChoiceInterface, Fixtures: true}},
+		{"recursive.xsd", Options{Package: "recursive", Fixtures: true}},
+	} {
+		schema, err := parser.ParseXSD(filepath.Join("testdata", c.schema), nil)
+		require.NoError(t, err)
+		src, err := Generate(schema, c.opts)
+		require.NoError(t, err)
+
+		golden, err := os.ReadFile(filepath.Join("internal", c.opts.Package, c.opts.Package+".go"))
+		require.NoError(t, err)
+		assert.Equal(t, string(golden), string(src), "golden file is stale, run go generate ./pkg/codegen")
+	}
+}
+
+func TestShortestContent(t *testing.T) {
+	schema, err := parser.ParseXSD(filepath.Join("testdata", "recursive.xsd"), nil)
+	require.NoError(t, err)
+	b, err := newConfiguredBuilder(schema, Options{Fixtures: true})
+	require.NoError(t, err)
+	file, err := b.build()
+	require.NoError(t, err)
+
+	endless := map[string]bool{}
+	for _, typ := range file.Types {
+		endless[typ.GoName] = typ.Endless
+		if typ.GoName == "Expr" {
+			require.Len(t, typ.Choices, 1)
+			assert.Equal(t, 1, typ.Choices[0].Shortest, "literal completes an expression")
+		}
+	}
+	assert.Equal(t, map[string]bool{"Node": false, "Expr": false, "ExprSum": false, "Loop": true,
+		"Node_2": false, "Expr_2": false, "Loop_2": true}, endless)
+}
+
+func TestSealedChoiceWithSequenceBranchFallsBackToFields(t *testing.T) {
+	schema, err := parser.ParseXSD(filepath.Join("..", "parser", "testdata", "nested_groups.xsd"), nil)
+	require.NoError(t, err)
+
+	src, err := Generate(schema, Options{ChoiceStyle: ChoiceInterface})
+	require.NoError(t, err)
+
+	out := string(src)
+	assert.NotContains(t, out, "interface {")
+	assert.Regexp(t, `Card\s+\[\]string\s+`+"`"+`xml:"card,omitempty"`+"`", out)
+	assert.Regexp(t, `Iban\s+\[\]string\s+`, out)
+	assert.Regexp(t, `Note\s+string\s+`, out)
+
+	_, err = Generate(schema, Options{ChoiceStyle: "union"})
+	assert.Error(t, err)
+}
+
+func TestUserTemplatesOverrideBuiltins(t *testing.T) {
+	dir := t.TempDir()
+	header := `{{define "header"}}// Code generated by acme-gen. DO NOT EDIT.
+// Source namespace: {{.TargetNamespace}}
+
+package {{.Package}}
+{{if .Imports}}import ({{range .Imports}}
+	{{printf "%q" .}}{{end}}
+){{end}}{{end}}`
+	enum := `{{define "enum"}}
+// {{.GoName}} ({{snake .Name}}) is owned by the billing team.
+type {{.GoName}} string
+{{end}}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "header.tmpl"), []byte(header), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "enum.tmpl"), []byte(enum), 0o600))
+
+	schema, err := parser.ParseXSD(filepath.Join("testdata", "golden.xsd"), nil)
+	require.NoError(t, err)
+	src, err := Generate(schema, Options{Package: "acme", TemplateDir: dir})
+	require.NoError(t, err, string(src))
+
+	out := string(src)
+	assert.Contains(t, out, "// Code generated by acme-gen. DO NOT EDIT.\n// Source namespace: http://example.com/golden")
+	assert.Contains(t, out, "// Status (status) is owned by the billing team.")
+	assert.NotContains(t, out, "func (v Status) IsValid() bool")
+	assert.Contains(t, out, "type Line struct", "templates that are not overridden still apply")
+}
+
+func TestTemplateDirWithoutTemplates(t *testing.T) {
+	_, err := Generate(&model.XSDSchema{}, Options{TemplateDir: t.TempDir()})
+	assert.Error(t, err)
+}
+
+func TestTemplateFunctions(t *testing.T) {
+	assert.Equal(t, "purchase_order", joinLower("purchaseOrder", "_"))
+	assert.Equal(t, "us-price", joinLower("USPrice", "-"))
+	assert.Equal(t, "shipTo", camelCase("ship-to"))
+	assert.Equal(t, "usPrice", camelCase("USPrice"))
+	assert.Equal(t, "// one two\n// three\n//\n// four", comment(12, "one two three\n\nfour"))
+	assert.Equal(t, `xml:"id,attr,omitempty"`, xmlTag(&Field{Name: "id", MinOccurs: "0", IsAttribute: true}))
+	assert.Equal(t, `xml:"note,omitempty"`, xmlTag(&Field{Name: "note", MinOccurs: "0"}))
+	assert.Equal(t, `xml:"urn:a note"`, xmlTag(&Field{Name: "note", Namespace: "urn:a"}))
+}
+
+func TestInitialValue(t *testing.T) {
+	b := newBuilder(&model.XSDSchema{})
+	status := &model.XSDRestriction{Enumerations: []model.XSDValue{{Value: "open"}, {Value: "closed"}}}
+	cases := []struct {
+		field *Field
+		want  string
+	}{
+		{&Field{Type: "int", Default: "007"}, "7"},
+		{&Field{Type: "bool", Fixed: "1"}, "true"},
+		{&Field{Type: "string", Default: "a \"b\""}, `"a \"b\""`},
+		{&Field{Type: "float64", Default: "INF"}, `xsdtypes.MustDecode[float64]("INF")`},
+		{&Field{Type: "xsdtypes.Decimal", Default: "0.00"}, `xsdtypes.MustDecode[xsdtypes.Decimal]("0.00")`},
+		{&Field{Type: "Status", IsEnum: true, Restriction: status, Fixed: "open"}, `Status("open")`},
+		{&Field{Type: "string", MaxOccurs: "unbounded", Default: "x"}, ""},
+		{&Field{Type: "string"}, ""},
+	}
+	for _, c := range cases {
+		got, err := b.initialValue(c.field)
+		require.NoError(t, err, "%+v", c.field)
+		assert.Equal(t, c.want, got, "%+v", c.field)
+	}
+
+	for _, f := range []*Field{
+		{Type: "int32", Default: "abc"},
+		{Type: "uint8", Default: "300"},
+		{Type: "*xsdtypes.Date", Default: "tomorrow"},
+		{Type: "Day", XSDType: "xs:date", Default: "2024-13-01"},
+		{Type: "Status", IsEnum: true, Restriction: status, Fixed: "pending"},
+		{Type: "int", Default: "12", Facets: &Facets{MaxInclusive: "9"}},
+		{Type: "string", Default: "abcd", Facets: &Facets{MaxLength: "3"}},
+	} {
+		_, err := b.initialValue(f)
+		assert.Error(t, err, "%+v", f)
+	}
+}
+
+func TestInvalidInitialValuesFailGeneration(t *testing.T) {
+	const xsd = `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="count" type="xs:int" default="many"/>
+      </xs:sequence>
+      <xs:attribute name="placed" type="xs:date" fixed="tomorrow"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`
+	path := filepath.Join(t.TempDir(), "order.xsd")
+	require.NoError(t, os.WriteFile(path, []byte(xsd), 0o600))
+	schema, err := parser.ParseXSD(path, nil)
+	require.NoError(t, err)
+
+	_, err = Generate(schema, Options{})
+	require.NoError(t, err, "values are only set by the builders")
+	_, err = Generate(schema, Options{Builders: true})
+	assert.ErrorContains(t, err, `order/count: invalid default value "many"`)
+	assert.ErrorContains(t, err, `order/@placed: invalid fixed value "tomorrow"`)
+}
+
+func TestBuildersAreOptIn(t *testing.T) {
+	schema, err := parser.ParseXSD(filepath.Join("testdata", "golden.xsd"), nil)
+	require.NoError(t, err)
+
+	src, err := Generate(schema, Options{})
+	require.NoError(t, err)
+	assert.NotContains(t, string(src), "func NewOrder()")
+
+	src, err = Generate(schema, Options{Builders: true})
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "func NewOrder() *Order")
+	assert.Contains(t, string(src), "func (b *OrderBuilder) Build() (*Order, error)")
+}
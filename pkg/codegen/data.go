@@ -0,0 +1,120 @@
+package codegen
+
+import "github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+
+// The types of this file are the data model handed to the templates, including the user templates
+// loaded with Options.TemplateDir. They are part of the public API: fields are added over time but
+// never renamed or removed.
+
+// File is the root of the data handed to the templates: one generated Go source file.
+type File struct {
+	Package         string
+	Imports         []string
+	TargetNamespace string
+	Namespaces      map[string]string // namespace declarations of the schema by prefix
+	Enums           []*Enum
+	Types           []*Type
+	Fixtures        bool // generate RandomX functions
+	Builders        bool // generate NewX functions and XBuilder types
+}
+
+// Type describes a Go struct generated from a complexType or a global element.
+type Type struct {
+	Name          string // XSD name of the type or element
+	GoName        string
+	XMLName       string // root tag for global elements, empty otherwise
+	Namespace     string // target namespace of the schema declaring the type
+	Path          string // schema path of the type, such as "purchaseOrder/items/item" for inline types
+	Embeds        string // named type embedded by global element wrappers
+	Documentation string
+	Fields        []*Field
+	Attributes    []*Field
+	Choices       []*Choice // xs:choice groups whose branches are checked together
+	Endless       bool      // the required content requires the type itself, so no finite value is valid
+
+	origin *model.XSDOrigin // schema document declaring the type
+}
+
+// SealedChoices reports whether the type holds choices generated as sealed interfaces.
+func (t *Type) SealedChoices() bool {
+	for _, c := range t.Choices {
+		if c.Interface != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Choice describes an xs:choice. With ChoiceFields every branch is an optional field of the parent type;
+// with ChoiceInterface the parent holds a single Field whose type is the sealed Interface.
+type Choice struct {
+	Interface string // sealed interface implemented by the branch types, empty with ChoiceFields
+	Field     *Field // holder field of a sealed choice
+	Required  bool   // exactly one branch must be present
+	Branches  []*Branch
+	Shortest  int // index of the branch with the shortest valid content
+}
+
+// Branch is one alternative element of a choice.
+type Branch struct {
+	GoName string // struct type wrapping the value of a sealed branch
+	Field  *Field // field of the parent with ChoiceFields, Value field of the branch type otherwise
+}
+
+// Field describes a struct field generated from a child element or an attribute.
+type Field struct {
+	Name          string // XML local name
+	Namespace     string // XML namespace of qualified elements and attributes, empty otherwise
+	GoName        string
+	Type          string // Go type of a single occurrence
+	Slice         string // Go type of repeated occurrences when mapped to a custom slice type, []Type otherwise
+	MinOccurs     string
+	MaxOccurs     string
+	Restriction   *model.XSDRestriction
+	XSDType       string // built-in XSD type the simple value derives from, such as "xs:decimal"
+	Fixed         string
+	Default       string
+	Initial       string // Go expression of the fixed or default value set by NewX, empty when there is none
+	Documentation string
+	IsStruct      bool    // Type is a generated struct validated recursively
+	IsEnum        bool    // Type is a generated enum checked with IsValid
+	IsAttribute   bool    // the field maps an XML attribute rather than a child element
+	Facets        *Facets // value constraints, nil when there are none
+	Choice        *Choice // set on the holder field of a sealed choice
+}
+
+// Facets lists the constraining facets of a field in lexical form, rendered as a validation.Facets variable.
+type Facets struct {
+	Var            string // name of the generated package-level variable
+	MinInclusive   string
+	MaxInclusive   string
+	MinExclusive   string
+	MaxExclusive   string
+	Length         string
+	MinLength      string
+	MaxLength      string
+	TotalDigits    string
+	FractionDigits string
+	Pattern        string
+	Enumerations   []string
+	Fixed          string
+}
+
+// Enum describes a named string type generated from an enumerated simpleType.
+type Enum struct {
+	Name          string // XSD name of the simple type
+	GoName        string
+	Namespace     string // target namespace of the schema declaring the type
+	XSDType       string // built-in XSD type the enumeration restricts
+	Documentation string
+	Values        []EnumValue
+
+	origin *model.XSDOrigin
+}
+
+// EnumValue is one enumerated value together with the name of its Go constant.
+type EnumValue struct {
+	Value         string
+	GoName        string
+	Documentation string
+}
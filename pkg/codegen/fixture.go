@@ -0,0 +1,204 @@
+package codegen
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+)
+
+// infinite is the size of content requiring itself.
+const infinite = math.MaxInt / 2
+
+// Runtime packages used by the generated RandomX functions.
+const (
+	fixtureImport = "github.com/Patrick-Ivann/xsd-codegen/pkg/fixture"
+	helpersImport = "github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+)
+
+// fieldTarget names the variable receiving the random value of a field in the generated RandomX functions.
+type fieldTarget struct {
+	Recv  string
+	Field *Field
+}
+
+func fieldOf(recv string, f *Field) fieldTarget {
+	return fieldTarget{Recv: recv, Field: f}
+}
+
+// nodeName returns the name of the values of f given to value generators: its XML name, prefixed with
+// @ for attributes.
+func nodeName(f *Field) string {
+	if f.IsAttribute {
+		return "@" + f.Name
+	}
+	return f.Name
+}
+
+// randomFunc returns the name of the RandomX function of a generated struct or enum type,
+// keeping the package qualifier of types of other packages: "*common.Address" gives "common.RandomAddress".
+func randomFunc(goType string) string {
+	return prefixFunc("Random", goType)
+}
+
+// fieldChoice returns the choice generated as optional fields that f is a branch of, or nil.
+func fieldChoice(t *Type, f *Field) *Choice {
+	for _, c := range t.Choices {
+		if c.Interface != "" {
+			continue
+		}
+		for _, branch := range c.Branches {
+			if branch.Field == f {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// choiceStart returns the choice generated as optional fields whose first branch is f, or nil:
+// the RandomX functions pick the branch of such a choice where its first branch is declared.
+func choiceStart(t *Type, f *Field) *Choice {
+	if c := fieldChoice(t, f); c != nil && c.Branches[0].Field == f {
+		return c
+	}
+	return nil
+}
+
+// contentSizes holds the smallest number of structs in the valid values of each generated type.
+type contentSizes struct {
+	types map[string]*Type // types of the file by Go name
+	size  map[*Type]int
+}
+
+// shortestContent marks the Endless types and the Shortest branch of each choice, which the RandomX
+// functions generate past the limits of package fixture. Sizes start infinite and only decrease until they
+// settle, so recursive types get the size of their shortest non-recursive value. Types of other packages
+// count as one struct.
+func (b *builder) shortestContent() {
+	s := &contentSizes{types: map[string]*Type{}, size: map[*Type]int{}}
+	for _, t := range b.file.Types {
+		s.types[t.GoName] = t
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, t := range b.file.Types {
+			if n := s.content(t); n < s.typeSize(t) {
+				s.size[t] = n
+				changed = true
+			}
+		}
+	}
+	for _, t := range b.file.Types {
+		t.Endless = s.typeSize(t) == infinite
+		for _, c := range t.Choices {
+			c.Shortest, _ = s.shortestBranch(c)
+		}
+	}
+}
+
+func (s *contentSizes) typeSize(t *Type) int {
+	if n, ok := s.size[t]; ok {
+		return n
+	}
+	return infinite
+}
+
+// content returns the size of the shortest value of t: the minOccurs of its fields and the shortest branch
+// of its required choices.
+func (s *contentSizes) content(t *Type) int {
+	n := 0
+	if t.Embeds != "" {
+		n = s.value(t.Embeds)
+	}
+	for _, f := range t.Fields {
+		switch c := fieldChoice(t, f); {
+		case f.Choice != nil:
+			count := 0
+			if isRepeated(f.MaxOccurs) {
+				count = minCount(f)
+			} else if f.Choice.Required {
+				count = 1
+			}
+			_, branch := s.shortestBranch(f.Choice)
+			n = addSizes(n, mulSizes(count, branch))
+		case c != nil:
+			if choiceStart(t, f) == c && c.Required {
+				_, branch := s.shortestBranch(c)
+				n = addSizes(n, branch)
+			}
+		default:
+			n = addSizes(n, mulSizes(minCount(f), s.value(f.Type)))
+		}
+	}
+	return n
+}
+
+// shortestBranch returns the index and the size of the branch of c with the shortest value.
+func (s *contentSizes) shortestBranch(c *Choice) (int, int) {
+	index, size := 0, infinite
+	for i, branch := range c.Branches {
+		if n := s.value(branch.Field.Type); n < size {
+			index, size = i, n
+		}
+	}
+	return index, size
+}
+
+// value returns the size of a value of goType: one plus its content for generated structs, zero otherwise.
+func (s *contentSizes) value(goType string) int {
+	t := s.types[strings.TrimPrefix(goType, "*")]
+	if t == nil {
+		return 0
+	}
+	return addSizes(1, s.typeSize(t))
+}
+
+// addSizes adds sizes, which stay infinite once they are.
+func addSizes(a, b int) int {
+	return min(a+b, infinite)
+}
+
+// mulSizes returns count times size, which stays infinite once it is.
+func mulSizes(count, size int) int {
+	if count > 0 && size >= infinite/count {
+		return infinite
+	}
+	return count * size
+}
+
+// minCount returns the minOccurs of f, 1 when it does not parse.
+func minCount(f *Field) int {
+	n, err := strconv.Atoi(f.MinOccurs)
+	if err != nil {
+		return 1
+	}
+	return n
+}
+
+// xsdBase follows the restrictions of simple types from qname down to the built-in type they derive from.
+// Values of types that do not resolve to a built-in are generated as xs:string.
+func (b *builder) xsdBase(qname string) string {
+	seen := map[string]bool{}
+	for !isBuiltinQName(qname) && !seen[qname] {
+		seen[qname] = true
+		st := b.simpleType(localName(qname))
+		if st == nil || st.Restriction == nil {
+			return "xs:string"
+		}
+		qname = st.Restriction.Base
+	}
+	if !isBuiltinQName(qname) {
+		return "xs:string"
+	}
+	return qname
+}
+
+// elementXSDType returns the built-in type the simple content of an element declaration derives from.
+func (b *builder) elementXSDType(el *model.XSDElement) string {
+	if el.Type == "" && el.SimpleType != nil && el.SimpleType.Restriction != nil {
+		return b.xsdBase(el.SimpleType.Restriction.Base)
+	}
+	return b.xsdBase(el.Type)
+}
@@ -0,0 +1,183 @@
+package codegen
+
+import (
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// commentWidth is the column at which documentation comments are wrapped.
+const commentWidth = 100
+
+// funcMap exposes the helpers used by the templates. User templates get the same functions,
+// so every entry is part of the public API.
+func funcMap() template.FuncMap {
+	return template.FuncMap{
+		// casing
+		"title":      GoName,
+		"camel":      camelCase,
+		"snake":      func(name string) string { return joinLower(name, "_") },
+		"kebab":      func(name string) string { return joinLower(name, "-") },
+		"unexported": unexported,
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		// Go types and struct tags
+		"goType":         goType,
+		"fieldType":      fieldType,
+		"xmlTag":         xmlTag,
+		"xmlName":        xmlName,
+		"unqualified":    unqualified,
+		"omit":           omit,
+		"restrictionTag": restrictionTag,
+		"isRepeated":     isRepeated,
+		"isPointer":      func(typ string) bool { return strings.HasPrefix(typ, "*") },
+		// comments
+		"comment": func(text string) string { return comment(commentWidth, text) },
+		"wrap":    wrap,
+		// validation
+		"minOccurs":   minOccurs,
+		"maxOccurs":   maxOccurs,
+		"facetsVar":   facetsVar,
+		"fieldPath":   fieldPath,
+		"branchNames": branchNames,
+		"branchOf":    branchOf,
+		// fixtures
+		"randomFunc":  randomFunc,
+		"fieldOf":     fieldOf,
+		"fieldChoice": fieldChoice,
+		"choiceStart": choiceStart,
+		"deref":       func(typ string) string { return strings.TrimPrefix(typ, "*") },
+		"nodeName":    nodeName,
+		// builders
+		"newFunc":     newFunc,
+		"required":    required,
+		"fieldOfType": fieldOfType,
+	}
+}
+
+// fieldType returns the Go type of a field declaration, honouring custom slice types.
+func fieldType(f *Field) string {
+	if f.Slice != "" && isRepeated(f.MaxOccurs) {
+		return f.Slice
+	}
+	return goType(f.Type, f.MaxOccurs)
+}
+
+// xmlTag renders the struct tag of a field without the surrounding backquotes, e.g. xml:"name,omitempty".
+// Qualified elements and attributes carry their namespace, as in xml:"http://example.com/ns name".
+func xmlTag(f *Field) string {
+	switch {
+	case f.Choice != nil:
+		return `xml:"` + f.Name + `"`
+	case f.IsAttribute:
+		return `xml:"` + xmlName(f.Namespace, f.Name) + ",attr" + omit(f.MinOccurs) + `"`
+	}
+	return `xml:"` + xmlName(f.Namespace, f.Name) + omit(f.MinOccurs) + `"` + restrictionTag(f.Restriction)
+}
+
+// xmlName prefixes a local name with its namespace, separated by a space as encoding/xml expects.
+func xmlName(namespace, local string) string {
+	if namespace == "" {
+		return local
+	}
+	return namespace + " " + local
+}
+
+// unqualified strips the package qualifier of a type name, giving the name of an embedded field.
+func unqualified(typ string) string {
+	return typ[strings.LastIndex(typ, ".")+1:]
+}
+
+// comment renders text as a Go line comment wrapped at width columns; paragraphs stay separated.
+func comment(width int, text string) string {
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(wrap(width-3, text), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("// "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrap breaks each blank-line separated paragraph of text into lines of at most width characters.
+// Words longer than width get a line of their own.
+func wrap(width int, text string) string {
+	var paragraphs []string
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		var lines []string
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			if line != "" && len(line)+1+len(word) > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		paragraphs = append(paragraphs, strings.Join(append(lines, line), "\n"))
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// words splits a name at non-alphanumeric characters and at case changes:
+// "purchaseOrder", "purchase-order" and "PurchaseOrder" all give [purchase Order], "USPrice" gives [US Price].
+func words(name string) []string {
+	var result []string
+	runes := []rune(name)
+	start := -1
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				result = append(result, string(runes[start:i]))
+			}
+			start = -1
+			continue
+		}
+		if start >= 0 && startsWord(runes, i) {
+			result = append(result, string(runes[start:i]))
+			start = i
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		result = append(result, string(runes[start:]))
+	}
+	return result
+}
+
+// startsWord reports whether the upper-case rune at i begins a new word: after a lower-case letter or digit,
+// or as the last capital of an acronym followed by a lower-case letter.
+func startsWord(runes []rune, i int) bool {
+	if !unicode.IsUpper(runes[i]) {
+		return false
+	}
+	prev := runes[i-1]
+	if !unicode.IsUpper(prev) {
+		return true
+	}
+	return i+1 < len(runes) && unicode.IsLower(runes[i+1])
+}
+
+// joinLower lower-cases the words of name and joins them with sep, as in snake_case or kebab-case.
+func joinLower(name, sep string) string {
+	parts := words(name)
+	for i, w := range parts {
+		parts[i] = strings.ToLower(w)
+	}
+	return strings.Join(parts, sep)
+}
+
+// camelCase turns a name into a lower camel case identifier, such as "purchaseOrder" or "usPrice".
+func camelCase(name string) string {
+	ident := GoName(strings.Join(words(name), " "))
+	parts := words(ident)
+	if len(parts) == 0 {
+		return ident
+	}
+	return strings.ToLower(parts[0]) + strings.TrimPrefix(ident, parts[0])
+}
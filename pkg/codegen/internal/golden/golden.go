@@ -0,0 +1,541 @@
+// Code generated by xsd-codegen. DO NOT EDIT.
+
+package golden
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/fixture"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/xsdtypes"
+)
+
+// Status enumerates the values allowed by the status simple type.
+//
+// Processing state of an order line.
+type Status string
+
+const (
+	// The line is waiting to be shipped.
+	StatusOpen Status = "open"
+	StatusNA   Status = "N/A"
+	Status1st  Status = "1st"
+)
+
+// IsValid reports whether v is one of the enumerated Status values.
+func (v Status) IsValid() bool {
+	switch v {
+	case StatusOpen, StatusNA, Status1st:
+		return true
+	}
+	return false
+}
+
+// MarshalText implements encoding.TextMarshaler and rejects unknown values.
+func (v Status) MarshalText() ([]byte, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("invalid Status value %q", string(v))
+	}
+	return []byte(v), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler and rejects unknown values.
+func (v *Status) UnmarshalText(text []byte) error {
+	candidate := Status(text)
+	if !candidate.IsValid() {
+		return fmt.Errorf("invalid Status value %q", string(text))
+	}
+	*v = candidate
+	return nil
+}
+
+// RandomStatus picks a Status value with g.
+func RandomStatus(g helpers.ValueGenerator) Status {
+	return fixture.Enum(g, "status", "xs:string", StatusOpen, StatusNA, Status1st)
+}
+
+// One ordered article. The price is the unit price, so the amount due for the line is the price
+// multiplied by the quantity.
+type Line struct {
+	Code string `xml:"code" xsd:"pattern=[A-Z]{3}-\\d{2}"`
+	// Default: 1
+	Quantity int              `xml:"quantity" xsd:"minInclusive=1;maxInclusive=99"`
+	Price    xsdtypes.Decimal `xml:"price" xsd:"totalDigits=7;fractionDigits=2"`
+	// Free text printed on the delivery note.
+	Note     string            `xml:"note,omitempty" xsd:"maxLength=20"`
+	Status   Status            `xml:"status,attr"`
+	Discount *xsdtypes.Decimal `xml:"discount,attr,omitempty"`
+}
+
+// Validate checks v against the cardinalities and facets declared in the schema
+// and reports every violation at once.
+func (v *Line) Validate() error {
+	var errs validation.Errors
+	v.ValidateAt("", &errs)
+	return errs.Err()
+}
+
+// ValidateAt appends the violations found in v to errs, using path as the location of v.
+func (v *Line) ValidateAt(path string, errs *validation.Errors) {
+	validation.CheckValue(path+"/code", v.Code, facetsLineCode, false, errs)
+	validation.CheckValue(path+"/quantity", v.Quantity, facetsLineQuantity, false, errs)
+	validation.CheckValue(path+"/price", v.Price, facetsLinePrice, false, errs)
+	validation.CheckValue(path+"/note", v.Note, facetsLineNote, true, errs)
+	validation.CheckValue(path+"/@status", v.Status, nil, false, errs)
+}
+
+var facetsLineCode = &validation.Facets{
+	Pattern: "[A-Z]{3}-\\d{2}",
+}
+
+var facetsLineQuantity = &validation.Facets{
+	MinInclusive: "1",
+	MaxInclusive: "99",
+}
+
+var facetsLinePrice = &validation.Facets{
+	TotalDigits:    "7",
+	FractionDigits: "2",
+}
+
+var facetsLineNote = &validation.Facets{
+	MaxLength: "20",
+}
+
+// RandomLine fills a new Line with values generated by g, picking occurrences
+// and choice branches at random within the bounds of the schema. Past the limits of package
+// fixture, the content nested in it is the shortest valid one.
+func RandomLine(g helpers.ValueGenerator) Line {
+	var v Line
+	g = fixture.Nest(g)
+	v.Code = fixture.Value[string](g, "code", "xs:string", facetsLineCode)
+	v.Quantity = fixture.Value[int](g, "quantity", "xs:int", facetsLineQuantity)
+	v.Price = fixture.Value[xsdtypes.Decimal](g, "price", "xs:decimal", facetsLinePrice)
+	if fixture.Occurs(g, "0", "1") > 0 {
+		v.Note = fixture.Value[string](g, "note", "xs:string", facetsLineNote)
+	}
+	v.Status = RandomStatus(g)
+	v.Discount = fixture.Ptr(fixture.Value[xsdtypes.Decimal](g, "@discount", "xs:decimal", nil))
+	return v
+}
+
+// NewLine returns a new Line holding the fixed and default values of the schema.
+// Required child structs are initialized the same way.
+func NewLine() *Line {
+	var v Line
+	v.Quantity = 1
+	v.Discount = xsdtypes.MustDecode[*xsdtypes.Decimal]("0.00")
+	return &v
+}
+
+// LineBuilder builds Line values step by step, starting from NewLine.
+type LineBuilder struct {
+	value Line
+	set   map[string]bool // Go names of the fields set so far
+}
+
+// NewLineBuilder returns a builder of Line values.
+func NewLineBuilder() *LineBuilder {
+	return &LineBuilder{value: *NewLine(), set: map[string]bool{}}
+}
+
+// WithCode sets the code element.
+func (b *LineBuilder) WithCode(v string) *LineBuilder {
+	b.value.Code = v
+	b.set["Code"] = true
+	return b
+}
+
+// WithQuantity sets the quantity element.
+func (b *LineBuilder) WithQuantity(v int) *LineBuilder {
+	b.value.Quantity = v
+	b.set["Quantity"] = true
+	return b
+}
+
+// WithPrice sets the price element.
+func (b *LineBuilder) WithPrice(v xsdtypes.Decimal) *LineBuilder {
+	b.value.Price = v
+	b.set["Price"] = true
+	return b
+}
+
+// WithNote sets the note element.
+func (b *LineBuilder) WithNote(v string) *LineBuilder {
+	b.value.Note = v
+	b.set["Note"] = true
+	return b
+}
+
+// WithStatus sets the status attribute.
+func (b *LineBuilder) WithStatus(v Status) *LineBuilder {
+	b.value.Status = v
+	b.set["Status"] = true
+	return b
+}
+
+// WithDiscount sets the discount attribute.
+func (b *LineBuilder) WithDiscount(v xsdtypes.Decimal) *LineBuilder {
+	b.value.Discount = &v
+	b.set["Discount"] = true
+	return b
+}
+
+// Build returns the Line built so far, or a validation.Errors listing the required values that were
+// never set and the repeated elements lacking occurrences. Build does not run Validate.
+func (b *LineBuilder) Build() (*Line, error) {
+	var errs validation.Errors
+	if !b.set["Code"] {
+		errs.Add("/code", "missing required value")
+	}
+	if !b.set["Price"] {
+		errs.Add("/price", "missing required value")
+	}
+	if !b.set["Status"] {
+		errs.Add("/@status", "missing required value")
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	v := b.value
+	return &v, nil
+}
+
+type Contact struct {
+	Email string `xml:"email,omitempty"`
+	Phone string `xml:"phone,omitempty"`
+}
+
+// Validate checks v against the cardinalities and facets declared in the schema
+// and reports every violation at once.
+func (v *Contact) Validate() error {
+	var errs validation.Errors
+	v.ValidateAt("", &errs)
+	return errs.Err()
+}
+
+// ValidateAt appends the violations found in v to errs, using path as the location of v.
+func (v *Contact) ValidateAt(path string, errs *validation.Errors) {
+	validation.CheckChoice(path, true, errs,
+		validation.Branch{Name: "email", Value: v.Email},
+		validation.Branch{Name: "phone", Value: v.Phone},
+	)
+}
+
+// RandomContact fills a new Contact with values generated by g, picking occurrences
+// and choice branches at random within the bounds of the schema. Past the limits of package
+// fixture, the content nested in it is the shortest valid one.
+func RandomContact(g helpers.ValueGenerator) Contact {
+	var v Contact
+	g = fixture.Nest(g)
+	switch fixture.Branch(g, 2, 0) {
+	case 0:
+		v.Email = fixture.Value[string](g, "email", "xs:string", nil)
+	case 1:
+		v.Phone = fixture.Value[string](g, "phone", "xs:string", nil)
+	}
+	return v
+}
+
+// NewContact returns a new Contact holding the fixed and default values of the schema.
+// Required child structs are initialized the same way.
+func NewContact() *Contact {
+	var v Contact
+	return &v
+}
+
+// ContactBuilder builds Contact values step by step, starting from NewContact.
+type ContactBuilder struct {
+	value Contact
+	set   map[string]bool // Go names of the fields set so far
+}
+
+// NewContactBuilder returns a builder of Contact values.
+func NewContactBuilder() *ContactBuilder {
+	return &ContactBuilder{value: *NewContact(), set: map[string]bool{}}
+}
+
+// WithEmail sets the email element.
+func (b *ContactBuilder) WithEmail(v string) *ContactBuilder {
+	b.value.Email = v
+	b.set["Email"] = true
+	return b
+}
+
+// WithPhone sets the phone element.
+func (b *ContactBuilder) WithPhone(v string) *ContactBuilder {
+	b.value.Phone = v
+	b.set["Phone"] = true
+	return b
+}
+
+// Build returns the Contact built so far, or a validation.Errors listing the required values that were
+// never set and the repeated elements lacking occurrences. Build does not run Validate.
+func (b *ContactBuilder) Build() (*Contact, error) {
+	var errs validation.Errors
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	v := b.value
+	return &v, nil
+}
+
+type Payment struct {
+	Amount  xsdtypes.Decimal `xml:"amount"`
+	Card    string           `xml:"card,omitempty" xsd:"pattern=[A-Z]{3}-\\d{2}"`
+	Voucher string           `xml:"voucher,omitempty"`
+	Tag     []string         `xml:"tag,omitempty"`
+	Flag    []Status         `xml:"flag,omitempty"`
+}
+
+// Validate checks v against the cardinalities and facets declared in the schema
+// and reports every violation at once.
+func (v *Payment) Validate() error {
+	var errs validation.Errors
+	v.ValidateAt("", &errs)
+	return errs.Err()
+}
+
+// ValidateAt appends the violations found in v to errs, using path as the location of v.
+func (v *Payment) ValidateAt(path string, errs *validation.Errors) {
+	validation.CheckValue(path+"/card", v.Card, facetsPaymentCard, true, errs)
+	validation.CheckOccurs(path+"/tag", len(v.Tag), 0, validation.Unbounded, errs)
+	validation.CheckOccurs(path+"/flag", len(v.Flag), 0, validation.Unbounded, errs)
+	for i := range v.Flag {
+		validation.CheckValue(validation.Index(path+"/flag", i), v.Flag[i], nil, false, errs)
+	}
+	validation.CheckChoice(path, true, errs,
+		validation.Branch{Name: "card", Value: v.Card},
+		validation.Branch{Name: "voucher", Value: v.Voucher},
+	)
+}
+
+var facetsPaymentCard = &validation.Facets{
+	Pattern: "[A-Z]{3}-\\d{2}",
+}
+
+// RandomPayment fills a new Payment with values generated by g, picking occurrences
+// and choice branches at random within the bounds of the schema. Past the limits of package
+// fixture, the content nested in it is the shortest valid one.
+func RandomPayment(g helpers.ValueGenerator) Payment {
+	var v Payment
+	g = fixture.Nest(g)
+	v.Amount = fixture.Value[xsdtypes.Decimal](g, "amount", "xs:decimal", nil)
+	switch fixture.Branch(g, 2, 0) {
+	case 0:
+		v.Card = fixture.Value[string](g, "card", "xs:string", facetsPaymentCard)
+	case 1:
+		v.Voucher = fixture.Value[string](g, "voucher", "xs:string", nil)
+	}
+	for n := fixture.Occurs(g, "0", "unbounded"); n > 0; n-- {
+		v.Tag = append(v.Tag, fixture.Value[string](g, "tag", "xs:string", nil))
+	}
+	for n := fixture.Occurs(g, "0", "unbounded"); n > 0; n-- {
+		v.Flag = append(v.Flag, RandomStatus(g))
+	}
+	return v
+}
+
+// NewPayment returns a new Payment holding the fixed and default values of the schema.
+// Required child structs are initialized the same way.
+func NewPayment() *Payment {
+	var v Payment
+	return &v
+}
+
+// PaymentBuilder builds Payment values step by step, starting from NewPayment.
+type PaymentBuilder struct {
+	value Payment
+	set   map[string]bool // Go names of the fields set so far
+}
+
+// NewPaymentBuilder returns a builder of Payment values.
+func NewPaymentBuilder() *PaymentBuilder {
+	return &PaymentBuilder{value: *NewPayment(), set: map[string]bool{}}
+}
+
+// WithAmount sets the amount element.
+func (b *PaymentBuilder) WithAmount(v xsdtypes.Decimal) *PaymentBuilder {
+	b.value.Amount = v
+	b.set["Amount"] = true
+	return b
+}
+
+// WithCard sets the card element.
+func (b *PaymentBuilder) WithCard(v string) *PaymentBuilder {
+	b.value.Card = v
+	b.set["Card"] = true
+	return b
+}
+
+// WithVoucher sets the voucher element.
+func (b *PaymentBuilder) WithVoucher(v string) *PaymentBuilder {
+	b.value.Voucher = v
+	b.set["Voucher"] = true
+	return b
+}
+
+// WithTag appends occurrences of the tag element.
+func (b *PaymentBuilder) WithTag(v ...string) *PaymentBuilder {
+	b.value.Tag = append(b.value.Tag, v...)
+	return b
+}
+
+// WithFlag appends occurrences of the flag element.
+func (b *PaymentBuilder) WithFlag(v ...Status) *PaymentBuilder {
+	b.value.Flag = append(b.value.Flag, v...)
+	return b
+}
+
+// Build returns the Payment built so far, or a validation.Errors listing the required values that were
+// never set and the repeated elements lacking occurrences. Build does not run Validate.
+func (b *PaymentBuilder) Build() (*Payment, error) {
+	var errs validation.Errors
+	if !b.set["Amount"] {
+		errs.Add("/amount", "missing required value")
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	v := b.value
+	return &v, nil
+}
+
+type Order struct {
+	XMLName   xml.Name          `xml:"http://example.com/golden order"`
+	Created   xsdtypes.DateTime `xml:"created"`
+	Contact   Contact           `xml:"contact"`
+	Line      []Line            `xml:"line"`
+	Payment   *Payment          `xml:"payment,omitempty"`
+	Version   string            `xml:"version,attr,omitempty"`
+	Reference *xsdtypes.Integer `xml:"reference,attr,omitempty"`
+}
+
+// Validate checks v against the cardinalities and facets declared in the schema
+// and reports every violation at once.
+func (v *Order) Validate() error {
+	var errs validation.Errors
+	v.ValidateAt("/order", &errs)
+	return errs.Err()
+}
+
+// ValidateAt appends the violations found in v to errs, using path as the location of v.
+func (v *Order) ValidateAt(path string, errs *validation.Errors) {
+	v.Contact.ValidateAt(path+"/contact", errs)
+	validation.CheckOccurs(path+"/line", len(v.Line), 1, 3, errs)
+	for i := range v.Line {
+		v.Line[i].ValidateAt(validation.Index(path+"/line", i), errs)
+	}
+	if v.Payment != nil {
+		v.Payment.ValidateAt(path+"/payment", errs)
+	}
+	validation.CheckValue(path+"/@version", v.Version, facetsOrderVersion, true, errs)
+	validation.CheckValue(path+"/@reference", v.Reference, facetsOrderReference, true, errs)
+}
+
+var facetsOrderVersion = &validation.Facets{
+	Fixed: "1.0",
+}
+
+var facetsOrderReference = &validation.Facets{
+	MinInclusive: "1",
+}
+
+// RandomOrder fills a new Order with values generated by g, picking occurrences
+// and choice branches at random within the bounds of the schema. Past the limits of package
+// fixture, the content nested in it is the shortest valid one.
+func RandomOrder(g helpers.ValueGenerator) Order {
+	var v Order
+	g = fixture.Nest(g)
+	v.Created = fixture.Value[xsdtypes.DateTime](g, "created", "xs:dateTime", nil)
+	v.Contact = RandomContact(g)
+	for n := fixture.Occurs(g, "", "3"); n > 0; n-- {
+		v.Line = append(v.Line, RandomLine(g))
+	}
+	if fixture.Occurs(g, "0", "1") > 0 {
+		v.Payment = fixture.Ptr(RandomPayment(g))
+	}
+	v.Version = fixture.Value[string](g, "@version", "xs:string", facetsOrderVersion)
+	v.Reference = fixture.Ptr(fixture.Value[xsdtypes.Integer](g, "@reference", "xs:positiveInteger", facetsOrderReference))
+	return v
+}
+
+// NewOrder returns a new Order holding the fixed and default values of the schema.
+// Required child structs are initialized the same way.
+func NewOrder() *Order {
+	var v Order
+	v.Contact = *NewContact()
+	v.Version = "1.0"
+	return &v
+}
+
+// OrderBuilder builds Order values step by step, starting from NewOrder.
+type OrderBuilder struct {
+	value Order
+	set   map[string]bool // Go names of the fields set so far
+}
+
+// NewOrderBuilder returns a builder of Order values.
+func NewOrderBuilder() *OrderBuilder {
+	return &OrderBuilder{value: *NewOrder(), set: map[string]bool{}}
+}
+
+// WithCreated sets the created element.
+func (b *OrderBuilder) WithCreated(v xsdtypes.DateTime) *OrderBuilder {
+	b.value.Created = v
+	b.set["Created"] = true
+	return b
+}
+
+// WithContact sets the contact element.
+func (b *OrderBuilder) WithContact(v Contact) *OrderBuilder {
+	b.value.Contact = v
+	b.set["Contact"] = true
+	return b
+}
+
+// WithLine appends occurrences of the line element.
+func (b *OrderBuilder) WithLine(v ...Line) *OrderBuilder {
+	b.value.Line = append(b.value.Line, v...)
+	return b
+}
+
+// WithPayment sets the payment element.
+func (b *OrderBuilder) WithPayment(v Payment) *OrderBuilder {
+	b.value.Payment = &v
+	b.set["Payment"] = true
+	return b
+}
+
+// WithVersion sets the version attribute.
+func (b *OrderBuilder) WithVersion(v string) *OrderBuilder {
+	b.value.Version = v
+	b.set["Version"] = true
+	return b
+}
+
+// WithReference sets the reference attribute.
+func (b *OrderBuilder) WithReference(v xsdtypes.Integer) *OrderBuilder {
+	b.value.Reference = &v
+	b.set["Reference"] = true
+	return b
+}
+
+// Build returns the Order built so far, or a validation.Errors listing the required values that were
+// never set and the repeated elements lacking occurrences. Build does not run Validate.
+func (b *OrderBuilder) Build() (*Order, error) {
+	var errs validation.Errors
+	if !b.set["Created"] {
+		errs.Add("/order/created", "missing required value")
+	}
+	if !b.set["Contact"] {
+		errs.Add("/order/contact", "missing required value")
+	}
+	validation.CheckOccurs("/order/line", len(b.value.Line), 1, validation.Unbounded, &errs)
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	v := b.value
+	return &v, nil
+}
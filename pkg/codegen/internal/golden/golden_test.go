@@ -0,0 +1,169 @@
+package golden
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/fixture"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/xsdtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validOrder() Order {
+	return Order{
+		Created: xsdtypes.NewDateTime(time.Date(2024, 2, 29, 10, 0, 0, 0, time.UTC)),
+		Contact: Contact{Email: "jane@example.com"},
+		Line: []Line{
+			{Code: "ABC-12", Quantity: 3, Price: xsdtypes.NewDecimal(1999, 2), Status: StatusOpen},
+		},
+		Version: "1.0",
+	}
+}
+
+func TestEnumRejectsUnknownValues(t *testing.T) {
+	var s Status
+	require.NoError(t, s.UnmarshalText([]byte("N/A")))
+	assert.Equal(t, StatusNA, s)
+	assert.Error(t, s.UnmarshalText([]byte("closed")))
+
+	_, err := Status("closed").MarshalText()
+	assert.Error(t, err)
+	assert.True(t, Status1st.IsValid())
+}
+
+func TestEnumInXMLDocument(t *testing.T) {
+	var l Line
+	err := xml.Unmarshal([]byte(`<line status="closed"><code>ABC-12</code></line>`), &l)
+	assert.Error(t, err)
+}
+
+func TestValidateAcceptsValidOrder(t *testing.T) {
+	order := validOrder()
+	assert.NoError(t, order.Validate())
+}
+
+func TestValidateReportsEveryViolation(t *testing.T) {
+	order := validOrder()
+	order.Version = "2.0"
+	order.Contact.Phone = "555-0100"
+	order.Line = append(order.Line, make([]Line, 3)...)
+	order.Line[0].Code = "abc-12"
+	order.Line[0].Quantity = 100
+	order.Line[0].Price = xsdtypes.NewDecimal(123456789, 3)
+	order.Line[0].Note = "this note is far too long"
+
+	err := order.Validate()
+	require.Error(t, err)
+
+	var errs validation.Errors
+	require.ErrorAs(t, err, &errs)
+	paths := map[string]bool{}
+	for _, e := range errs {
+		paths[e.Path] = true
+	}
+	for _, want := range []string{
+		"/order/@version",
+		"/order/contact",
+		"/order/line",
+		"/order/line[1]/code",
+		"/order/line[1]/quantity",
+		"/order/line[1]/price",
+		"/order/line[1]/note",
+		"/order/line[2]/@status",
+	} {
+		assert.True(t, paths[want], "expected a violation at %s, got:\n%v", want, err)
+	}
+}
+
+// validValues generates values satisfying every facet of the golden schema.
+type validValues struct{}
+
+func (validValues) Generate(vc *helpers.ValueContext) (string, bool) {
+	switch r := vc.Restriction; {
+	case r != nil && len(r.Enumerations) > 0:
+		return r.Enumerations[len(r.Enumerations)-1].Value, true
+	case r != nil && r.Pattern != nil:
+		return "ABC-12", true
+	}
+	switch vc.Base {
+	case "xs:int":
+		return "7", true
+	case "xs:decimal":
+		return "19.99", true
+	case "xs:positiveInteger":
+		return "123456789012345678901234567890", true
+	case "xs:dateTime":
+		return "2024-02-29T10:00:00Z", true
+	}
+	return "text", true
+}
+
+func TestRandomOrderFollowsTheSchema(t *testing.T) {
+	for range 20 {
+		order := RandomOrder(validValues{})
+		require.NoError(t, order.Validate())
+		assert.NotEmpty(t, order.Line)
+		assert.LessOrEqual(t, len(order.Line), 3)
+		assert.Equal(t, "1.0", order.Version, "fixed values are kept")
+		assert.Equal(t, Status1st, order.Line[0].Status)
+		assert.Equal(t, 7, order.Line[0].Quantity)
+		assert.Equal(t, "123456789012345678901234567890", order.Reference.String(), "integers keep every digit")
+		assert.NotEqual(t, order.Contact.Email == "", order.Contact.Phone == "", "exactly one contact branch")
+	}
+}
+
+func TestUnboundedIntegers(t *testing.T) {
+	var order Order
+	in := `<order xmlns="http://example.com/golden" reference="18446744073709551616"></order>`
+	require.NoError(t, xml.Unmarshal([]byte(in), &order))
+	assert.Equal(t, "18446744073709551616", order.Reference.String())
+
+	order = validOrder()
+	order.Reference = fixture.Ptr(xsdtypes.NewInteger(0))
+	assert.ErrorContains(t, order.Validate(), "/order/@reference", "positive integers start at 1")
+}
+
+func TestNewAppliesDefaultsAndFixedValues(t *testing.T) {
+	line := NewLine()
+	assert.Equal(t, 1, line.Quantity)
+	require.NotNil(t, line.Discount)
+	assert.Equal(t, "0.00", line.Discount.String())
+	assert.Equal(t, "1.0", NewOrder().Version)
+}
+
+func TestBuilderReportsMissingValues(t *testing.T) {
+	_, err := NewOrderBuilder().Build()
+	var errs validation.Errors
+	require.ErrorAs(t, err, &errs)
+	paths := map[string]bool{}
+	for _, e := range errs {
+		paths[e.Path] = true
+	}
+	for _, want := range []string{"/order/created", "/order/contact", "/order/line"} {
+		assert.True(t, paths[want], "expected a missing value at %s, got:\n%v", want, err)
+	}
+	assert.False(t, paths["/order/@version"], "fixed values count as set")
+}
+
+func TestBuilderBuildsValidOrder(t *testing.T) {
+	want := validOrder()
+	line, err := NewLineBuilder().
+		WithCode("ABC-12").
+		WithQuantity(3).
+		WithPrice(xsdtypes.NewDecimal(1999, 2)).
+		WithStatus(StatusOpen).
+		Build()
+	require.NoError(t, err)
+	order, err := NewOrderBuilder().
+		WithCreated(want.Created).
+		WithContact(want.Contact).
+		WithLine(*line).
+		Build()
+	require.NoError(t, err)
+	require.NoError(t, order.Validate())
+	assert.Equal(t, "0.00", order.Line[0].Discount.String())
+}
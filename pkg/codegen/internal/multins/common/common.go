@@ -0,0 +1,81 @@
+// Code generated by xsd-codegen. DO NOT EDIT.
+
+package common
+
+import (
+	"fmt"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/fixture"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+)
+
+// Country enumerates the values allowed by the country simple type.
+type Country string
+
+const (
+	CountryFR Country = "FR"
+	CountryUS Country = "US"
+)
+
+// IsValid reports whether v is one of the enumerated Country values.
+func (v Country) IsValid() bool {
+	switch v {
+	case CountryFR, CountryUS:
+		return true
+	}
+	return false
+}
+
+// MarshalText implements encoding.TextMarshaler and rejects unknown values.
+func (v Country) MarshalText() ([]byte, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("invalid Country value %q", string(v))
+	}
+	return []byte(v), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler and rejects unknown values.
+func (v *Country) UnmarshalText(text []byte) error {
+	candidate := Country(text)
+	if !candidate.IsValid() {
+		return fmt.Errorf("invalid Country value %q", string(text))
+	}
+	*v = candidate
+	return nil
+}
+
+// RandomCountry picks a Country value with g.
+func RandomCountry(g helpers.ValueGenerator) Country {
+	return fixture.Enum(g, "country", "xs:string", CountryFR, CountryUS)
+}
+
+type Address struct {
+	Street  string  `xml:"http://example.com/common street"`
+	Country Country `xml:"http://example.com/common country"`
+	Kind    string  `xml:"kind,attr,omitempty"`
+}
+
+// Validate checks v against the cardinalities and facets declared in the schema
+// and reports every violation at once.
+func (v *Address) Validate() error {
+	var errs validation.Errors
+	v.ValidateAt("", &errs)
+	return errs.Err()
+}
+
+// ValidateAt appends the violations found in v to errs, using path as the location of v.
+func (v *Address) ValidateAt(path string, errs *validation.Errors) {
+	validation.CheckValue(path+"/country", v.Country, nil, false, errs)
+}
+
+// RandomAddress fills a new Address with values generated by g, picking occurrences
+// and choice branches at random within the bounds of the schema. Past the limits of package
+// fixture, the content nested in it is the shortest valid one.
+func RandomAddress(g helpers.ValueGenerator) Address {
+	var v Address
+	g = fixture.Nest(g)
+	v.Street = fixture.Value[string](g, "street", "xs:string", nil)
+	v.Country = RandomCountry(g)
+	v.Kind = fixture.Value[string](g, "@kind", "xs:string", nil)
+	return v
+}
@@ -0,0 +1,84 @@
+// Code generated by xsd-codegen. DO NOT EDIT.
+
+package multins
+
+import (
+	"encoding/xml"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/codegen/internal/multins/common"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/fixture"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+)
+
+type Customer struct {
+	Name        string         `xml:"http://example.com/orders name"`
+	Address     common.Address `xml:"http://example.com/orders address"`
+	Nationality common.Country `xml:"http://example.com/orders nationality,omitempty"`
+	Id          string         `xml:"id,attr"`
+	Ref         string         `xml:"http://example.com/orders ref,attr,omitempty"`
+}
+
+// Validate checks v against the cardinalities and facets declared in the schema
+// and reports every violation at once.
+func (v *Customer) Validate() error {
+	var errs validation.Errors
+	v.ValidateAt("", &errs)
+	return errs.Err()
+}
+
+// ValidateAt appends the violations found in v to errs, using path as the location of v.
+func (v *Customer) ValidateAt(path string, errs *validation.Errors) {
+	v.Address.ValidateAt(path+"/address", errs)
+	validation.CheckValue(path+"/nationality", v.Nationality, nil, true, errs)
+}
+
+// RandomCustomer fills a new Customer with values generated by g, picking occurrences
+// and choice branches at random within the bounds of the schema. Past the limits of package
+// fixture, the content nested in it is the shortest valid one.
+func RandomCustomer(g helpers.ValueGenerator) Customer {
+	var v Customer
+	g = fixture.Nest(g)
+	v.Name = fixture.Value[string](g, "name", "xs:string", nil)
+	v.Address = common.RandomAddress(g)
+	if fixture.Occurs(g, "0", "1") > 0 {
+		v.Nationality = common.RandomCountry(g)
+	}
+	v.Id = fixture.Value[string](g, "@id", "xs:string", nil)
+	v.Ref = fixture.Value[string](g, "@ref", "xs:string", nil)
+	return v
+}
+
+type Order struct {
+	XMLName  xml.Name        `xml:"http://example.com/orders order"`
+	Customer Customer        `xml:"http://example.com/orders customer"`
+	ShipTo   *common.Address `xml:"http://example.com/orders shipTo,omitempty"`
+}
+
+// Validate checks v against the cardinalities and facets declared in the schema
+// and reports every violation at once.
+func (v *Order) Validate() error {
+	var errs validation.Errors
+	v.ValidateAt("/order", &errs)
+	return errs.Err()
+}
+
+// ValidateAt appends the violations found in v to errs, using path as the location of v.
+func (v *Order) ValidateAt(path string, errs *validation.Errors) {
+	v.Customer.ValidateAt(path+"/customer", errs)
+	if v.ShipTo != nil {
+		v.ShipTo.ValidateAt(path+"/shipTo", errs)
+	}
+}
+
+// RandomOrder fills a new Order with values generated by g, picking occurrences
+// and choice branches at random within the bounds of the schema. Past the limits of package
+// fixture, the content nested in it is the shortest valid one.
+func RandomOrder(g helpers.ValueGenerator) Order {
+	var v Order
+	g = fixture.Nest(g)
+	v.Customer = RandomCustomer(g)
+	if fixture.Occurs(g, "0", "1") > 0 {
+		v.ShipTo = fixture.Ptr(common.RandomAddress(g))
+	}
+	return v
+}
@@ -0,0 +1,56 @@
+package multins
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/codegen/internal/multins/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orderXML = `<o:order xmlns:o="http://example.com/orders" xmlns:c="http://example.com/common" o:ref="r-1">` +
+	`<o:customer id="42" o:ref="r-2">` +
+	`<o:name>Jane</o:name>` +
+	`<o:address kind="home"><c:street>1 Main St</c:street><c:country>FR</c:country></o:address>` +
+	`</o:customer>` +
+	`</o:order>`
+
+func TestUnmarshalChecksNamespaces(t *testing.T) {
+	var order Order
+	require.NoError(t, xml.Unmarshal([]byte(orderXML), &order))
+
+	assert.Equal(t, "Jane", order.Customer.Name)
+	assert.Equal(t, "42", order.Customer.Id)
+	assert.Equal(t, "r-2", order.Customer.Ref)
+	assert.Equal(t, common.Address{Street: "1 Main St", Country: common.CountryFR, Kind: "home"}, order.Customer.Address)
+	assert.NoError(t, order.Validate())
+
+	// The street of an address is in the common namespace, not in the orders one.
+	wrong := `<order xmlns="http://example.com/orders"><customer id="1"><name>Jane</name>` +
+		`<address><street>1 Main St</street></address></customer></order>`
+	var misplaced Order
+	require.NoError(t, xml.Unmarshal([]byte(wrong), &misplaced))
+	assert.Empty(t, misplaced.Customer.Address.Street)
+
+	assert.Error(t, xml.Unmarshal([]byte(`<order><customer/></order>`), &misplaced), "root element outside its namespace")
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	order := Order{
+		Customer: Customer{
+			Name:    "Jane",
+			Address: common.Address{Street: "1 Main St", Country: common.CountryUS},
+			Id:      "42",
+		},
+		ShipTo: &common.Address{Street: "2 Dock Rd", Country: common.CountryFR},
+	}
+	out, err := xml.Marshal(order)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `<street xmlns="http://example.com/common">1 Main St</street>`)
+
+	var decoded Order
+	require.NoError(t, xml.Unmarshal(out, &decoded))
+	decoded.XMLName = xml.Name{}
+	assert.Equal(t, order, decoded)
+}
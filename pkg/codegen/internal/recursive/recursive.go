@@ -0,0 +1,247 @@
+// Code generated by xsd-codegen. DO NOT EDIT.
+
+package recursive
+
+import (
+	"encoding/xml"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/fixture"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+)
+
+type Node struct {
+	Label string `xml:"label"`
+	Node  []Node `xml:"node,omitempty"`
+}
+
+// Validate checks v against the cardinalities and facets declared in the schema
+// and reports every violation at once.
+func (v *Node) Validate() error {
+	var errs validation.Errors
+	v.ValidateAt("", &errs)
+	return errs.Err()
+}
+
+// ValidateAt appends the violations found in v to errs, using path as the location of v.
+func (v *Node) ValidateAt(path string, errs *validation.Errors) {
+	validation.CheckOccurs(path+"/node", len(v.Node), 0, validation.Unbounded, errs)
+	for i := range v.Node {
+		v.Node[i].ValidateAt(validation.Index(path+"/node", i), errs)
+	}
+}
+
+// RandomNode fills a new Node with values generated by g, picking occurrences
+// and choice branches at random within the bounds of the schema. Past the limits of package
+// fixture, the content nested in it is the shortest valid one.
+func RandomNode(g helpers.ValueGenerator) Node {
+	var v Node
+	g = fixture.Nest(g)
+	v.Label = fixture.Value[string](g, "label", "xs:string", nil)
+	for n := fixture.Occurs(g, "0", "unbounded"); n > 0; n-- {
+		v.Node = append(v.Node, RandomNode(g))
+	}
+	return v
+}
+
+type Expr struct {
+	Sum     *ExprSum `xml:"sum,omitempty"`
+	Literal int      `xml:"literal,omitempty"`
+}
+
+// Validate checks v against the cardinalities and facets declared in the schema
+// and reports every violation at once.
+func (v *Expr) Validate() error {
+	var errs validation.Errors
+	v.ValidateAt("", &errs)
+	return errs.Err()
+}
+
+// ValidateAt appends the violations found in v to errs, using path as the location of v.
+func (v *Expr) ValidateAt(path string, errs *validation.Errors) {
+	if v.Sum != nil {
+		v.Sum.ValidateAt(path+"/sum", errs)
+	}
+	validation.CheckChoice(path, true, errs,
+		validation.Branch{Name: "sum", Value: v.Sum},
+		validation.Branch{Name: "literal", Value: v.Literal},
+	)
+}
+
+// RandomExpr fills a new Expr with values generated by g, picking occurrences
+// and choice branches at random within the bounds of the schema. Past the limits of package
+// fixture, the content nested in it is the shortest valid one.
+func RandomExpr(g helpers.ValueGenerator) Expr {
+	var v Expr
+	g = fixture.Nest(g)
+	switch fixture.Branch(g, 2, 1) {
+	case 0:
+		v.Sum = fixture.Ptr(RandomExprSum(g))
+	case 1:
+		v.Literal = fixture.Value[int](g, "literal", "xs:int", nil)
+	}
+	return v
+}
+
+type Loop struct {
+	Loop *Loop `xml:"loop,omitempty"`
+	Next *Loop `xml:"next,omitempty"`
+}
+
+// Validate checks v against the cardinalities and facets declared in the schema
+// and reports every violation at once.
+func (v *Loop) Validate() error {
+	var errs validation.Errors
+	v.ValidateAt("", &errs)
+	return errs.Err()
+}
+
+// ValidateAt appends the violations found in v to errs, using path as the location of v.
+func (v *Loop) ValidateAt(path string, errs *validation.Errors) {
+	if v.Loop != nil {
+		v.Loop.ValidateAt(path+"/loop", errs)
+	}
+	if v.Next != nil {
+		v.Next.ValidateAt(path+"/next", errs)
+	}
+	validation.CheckChoice(path, true, errs,
+		validation.Branch{Name: "loop", Value: v.Loop},
+		validation.Branch{Name: "next", Value: v.Next},
+	)
+}
+
+// RandomLoop fills a new Loop with values generated by g, picking occurrences
+// and choice branches at random within the bounds of the schema. Past the limits of package
+// fixture, the content nested in it is the shortest valid one.
+func RandomLoop(g helpers.ValueGenerator) Loop {
+	var v Loop
+	g = fixture.Nest(g)
+	if fixture.Deep(g) {
+		return v
+	}
+	switch fixture.Branch(g, 2, 0) {
+	case 0:
+		v.Loop = fixture.Ptr(RandomLoop(g))
+	case 1:
+		v.Next = fixture.Ptr(RandomLoop(g))
+	}
+	return v
+}
+
+type ExprSum struct {
+	Expr []Expr `xml:"expr"`
+}
+
+// Validate checks v against the cardinalities and facets declared in the schema
+// and reports every violation at once.
+func (v *ExprSum) Validate() error {
+	var errs validation.Errors
+	v.ValidateAt("", &errs)
+	return errs.Err()
+}
+
+// ValidateAt appends the violations found in v to errs, using path as the location of v.
+func (v *ExprSum) ValidateAt(path string, errs *validation.Errors) {
+	validation.CheckOccurs(path+"/expr", len(v.Expr), 2, validation.Unbounded, errs)
+	for i := range v.Expr {
+		v.Expr[i].ValidateAt(validation.Index(path+"/expr", i), errs)
+	}
+}
+
+// RandomExprSum fills a new ExprSum with values generated by g, picking occurrences
+// and choice branches at random within the bounds of the schema. Past the limits of package
+// fixture, the content nested in it is the shortest valid one.
+func RandomExprSum(g helpers.ValueGenerator) ExprSum {
+	var v ExprSum
+	g = fixture.Nest(g)
+	for n := fixture.Occurs(g, "2", "unbounded"); n > 0; n-- {
+		v.Expr = append(v.Expr, RandomExpr(g))
+	}
+	return v
+}
+
+type Node_2 struct {
+	XMLName xml.Name `xml:"node"`
+	Node
+}
+
+// Validate checks v against the cardinalities and facets declared in the schema
+// and reports every violation at once.
+func (v *Node_2) Validate() error {
+	var errs validation.Errors
+	v.ValidateAt("/node", &errs)
+	return errs.Err()
+}
+
+// ValidateAt appends the violations found in v to errs, using path as the location of v.
+func (v *Node_2) ValidateAt(path string, errs *validation.Errors) {
+	v.Node.ValidateAt(path, errs)
+}
+
+// RandomNode_2 fills a new Node_2 with values generated by g, picking occurrences
+// and choice branches at random within the bounds of the schema. Past the limits of package
+// fixture, the content nested in it is the shortest valid one.
+func RandomNode_2(g helpers.ValueGenerator) Node_2 {
+	var v Node_2
+	g = fixture.Nest(g)
+	v.Node = RandomNode(g)
+	return v
+}
+
+type Expr_2 struct {
+	XMLName xml.Name `xml:"expr"`
+	Expr
+}
+
+// Validate checks v against the cardinalities and facets declared in the schema
+// and reports every violation at once.
+func (v *Expr_2) Validate() error {
+	var errs validation.Errors
+	v.ValidateAt("/expr", &errs)
+	return errs.Err()
+}
+
+// ValidateAt appends the violations found in v to errs, using path as the location of v.
+func (v *Expr_2) ValidateAt(path string, errs *validation.Errors) {
+	v.Expr.ValidateAt(path, errs)
+}
+
+// RandomExpr_2 fills a new Expr_2 with values generated by g, picking occurrences
+// and choice branches at random within the bounds of the schema. Past the limits of package
+// fixture, the content nested in it is the shortest valid one.
+func RandomExpr_2(g helpers.ValueGenerator) Expr_2 {
+	var v Expr_2
+	g = fixture.Nest(g)
+	v.Expr = RandomExpr(g)
+	return v
+}
+
+type Loop_2 struct {
+	XMLName xml.Name `xml:"loop"`
+	Loop
+}
+
+// Validate checks v against the cardinalities and facets declared in the schema
+// and reports every violation at once.
+func (v *Loop_2) Validate() error {
+	var errs validation.Errors
+	v.ValidateAt("/loop", &errs)
+	return errs.Err()
+}
+
+// ValidateAt appends the violations found in v to errs, using path as the location of v.
+func (v *Loop_2) ValidateAt(path string, errs *validation.Errors) {
+	v.Loop.ValidateAt(path, errs)
+}
+
+// RandomLoop_2 fills a new Loop_2 with values generated by g, picking occurrences
+// and choice branches at random within the bounds of the schema. Past the limits of package
+// fixture, the content nested in it is the shortest valid one.
+func RandomLoop_2(g helpers.ValueGenerator) Loop_2 {
+	var v Loop_2
+	g = fixture.Nest(g)
+	if fixture.Deep(g) {
+		return v
+	}
+	v.Loop = RandomLoop(g)
+	return v
+}
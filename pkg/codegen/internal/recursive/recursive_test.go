@@ -0,0 +1,67 @@
+package recursive
+
+import (
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/fixture"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seeded returns a value generator drawing from seed.
+func seeded(seed uint64) helpers.ValueGenerator {
+	return helpers.DefaultValueGenerator{Source: helpers.NewRand(seed)}
+}
+
+// nodeSize returns the number of nodes of n and its depth, n being at depth 1.
+func nodeSize(n Node) (count, depth int) {
+	count, depth = 1, 1
+	for _, child := range n.Node {
+		c, d := nodeSize(child)
+		count, depth = count+c, max(depth, d+1)
+	}
+	return count, depth
+}
+
+// exprDepth returns the number of nested structs of e, e being at depth 1.
+func exprDepth(e Expr) int {
+	depth := 1
+	if e.Sum != nil {
+		for _, child := range e.Sum.Expr {
+			depth = max(depth, exprDepth(child)+2)
+		}
+	}
+	return depth
+}
+
+func TestRandomNodeStaysWithinTheLimits(t *testing.T) {
+	for seed := range uint64(200) {
+		node := RandomNode(seeded(seed))
+		require.NoError(t, node.Validate())
+		count, depth := nodeSize(node)
+		assert.LessOrEqual(t, depth, fixture.MaxDepth+1, "seed %d", seed)
+		assert.LessOrEqual(t, count, fixture.MaxStructs+3*fixture.MaxDepth, "seed %d", seed)
+	}
+}
+
+func TestRandomExprCompletesWithLiterals(t *testing.T) {
+	for seed := range uint64(200) {
+		expr := RandomExpr(seeded(seed))
+		require.NoError(t, expr.Validate(), "seed %d", seed)
+		assert.LessOrEqual(t, exprDepth(expr), fixture.MaxDepth+2, "seed %d", seed)
+	}
+}
+
+func TestRandomLoopStopsPastTheLimits(t *testing.T) {
+	loop, depth := RandomLoop(seeded(1)), 0
+	for next := &loop; next != nil; depth++ {
+		if next.Loop != nil {
+			next = next.Loop
+		} else {
+			next = next.Next
+		}
+	}
+	assert.Equal(t, fixture.MaxDepth+1, depth, "endless types are left empty past the limits")
+	assert.Error(t, loop.Validate())
+}
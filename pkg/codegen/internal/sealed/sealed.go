@@ -0,0 +1,479 @@
+// Code generated by xsd-codegen. DO NOT EDIT.
+
+package sealed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/fixture"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/xsdtypes"
+)
+
+// Status enumerates the values allowed by the status simple type.
+//
+// Processing state of an order line.
+type Status string
+
+const (
+	// The line is waiting to be shipped.
+	StatusOpen Status = "open"
+	StatusNA   Status = "N/A"
+	Status1st  Status = "1st"
+)
+
+// IsValid reports whether v is one of the enumerated Status values.
+func (v Status) IsValid() bool {
+	switch v {
+	case StatusOpen, StatusNA, Status1st:
+		return true
+	}
+	return false
+}
+
+// MarshalText implements encoding.TextMarshaler and rejects unknown values.
+func (v Status) MarshalText() ([]byte, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("invalid Status value %q", string(v))
+	}
+	return []byte(v), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler and rejects unknown values.
+func (v *Status) UnmarshalText(text []byte) error {
+	candidate := Status(text)
+	if !candidate.IsValid() {
+		return fmt.Errorf("invalid Status value %q", string(text))
+	}
+	*v = candidate
+	return nil
+}
+
+// RandomStatus picks a Status value with g.
+func RandomStatus(g helpers.ValueGenerator) Status {
+	return fixture.Enum(g, "status", "xs:string", StatusOpen, StatusNA, Status1st)
+}
+
+// One ordered article. The price is the unit price, so the amount due for the line is the price
+// multiplied by the quantity.
+type Line struct {
+	Code string `xml:"code" xsd:"pattern=[A-Z]{3}-\\d{2}"`
+	// Default: 1
+	Quantity int              `xml:"quantity" xsd:"minInclusive=1;maxInclusive=99"`
+	Price    xsdtypes.Decimal `xml:"price" xsd:"totalDigits=7;fractionDigits=2"`
+	// Free text printed on the delivery note.
+	Note     string            `xml:"note,omitempty" xsd:"maxLength=20"`
+	Status   Status            `xml:"status,attr"`
+	Discount *xsdtypes.Decimal `xml:"discount,attr,omitempty"`
+}
+
+// Validate checks v against the cardinalities and facets declared in the schema
+// and reports every violation at once.
+func (v *Line) Validate() error {
+	var errs validation.Errors
+	v.ValidateAt("", &errs)
+	return errs.Err()
+}
+
+// ValidateAt appends the violations found in v to errs, using path as the location of v.
+func (v *Line) ValidateAt(path string, errs *validation.Errors) {
+	validation.CheckValue(path+"/code", v.Code, facetsLineCode, false, errs)
+	validation.CheckValue(path+"/quantity", v.Quantity, facetsLineQuantity, false, errs)
+	validation.CheckValue(path+"/price", v.Price, facetsLinePrice, false, errs)
+	validation.CheckValue(path+"/note", v.Note, facetsLineNote, true, errs)
+	validation.CheckValue(path+"/@status", v.Status, nil, false, errs)
+}
+
+var facetsLineCode = &validation.Facets{
+	Pattern: "[A-Z]{3}-\\d{2}",
+}
+
+var facetsLineQuantity = &validation.Facets{
+	MinInclusive: "1",
+	MaxInclusive: "99",
+}
+
+var facetsLinePrice = &validation.Facets{
+	TotalDigits:    "7",
+	FractionDigits: "2",
+}
+
+var facetsLineNote = &validation.Facets{
+	MaxLength: "20",
+}
+
+// RandomLine fills a new Line with values generated by g, picking occurrences
+// and choice branches at random within the bounds of the schema. Past the limits of package
+// fixture, the content nested in it is the shortest valid one.
+func RandomLine(g helpers.ValueGenerator) Line {
+	var v Line
+	g = fixture.Nest(g)
+	v.Code = fixture.Value[string](g, "code", "xs:string", facetsLineCode)
+	v.Quantity = fixture.Value[int](g, "quantity", "xs:int", facetsLineQuantity)
+	v.Price = fixture.Value[xsdtypes.Decimal](g, "price", "xs:decimal", facetsLinePrice)
+	if fixture.Occurs(g, "0", "1") > 0 {
+		v.Note = fixture.Value[string](g, "note", "xs:string", facetsLineNote)
+	}
+	v.Status = RandomStatus(g)
+	v.Discount = fixture.Ptr(fixture.Value[xsdtypes.Decimal](g, "@discount", "xs:decimal", nil))
+	return v
+}
+
+type Contact struct {
+	EmailOrPhone ContactEmailOrPhone `xml:"email|phone"`
+}
+
+// Validate checks v against the cardinalities and facets declared in the schema
+// and reports every violation at once.
+func (v *Contact) Validate() error {
+	var errs validation.Errors
+	v.ValidateAt("", &errs)
+	return errs.Err()
+}
+
+// ValidateAt appends the violations found in v to errs, using path as the location of v.
+func (v *Contact) ValidateAt(path string, errs *validation.Errors) {
+	if v.EmailOrPhone != nil {
+		v.EmailOrPhone.ValidateAt(path, errs)
+	} else {
+		errs.Add(path, "choice requires one of %s", "email, phone")
+	}
+}
+
+// ContactEmailOrPhone holds one branch of the email, phone choice.
+type ContactEmailOrPhone interface {
+	ValidateAt(path string, errs *validation.Errors)
+	isContactEmailOrPhone()
+}
+
+// ContactEmail is the email branch of ContactEmailOrPhone.
+type ContactEmail struct {
+	Value string
+}
+
+func (ContactEmail) isContactEmailOrPhone() {}
+
+// MarshalXML encodes the value under the email element.
+func (v ContactEmail) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	return e.EncodeElement(v.Value, xml.StartElement{Name: xml.Name{Local: "email"}})
+}
+
+// ValidateAt appends the violations found in the value to errs, using path as the location of the choice.
+func (v ContactEmail) ValidateAt(path string, errs *validation.Errors) {
+}
+
+// ContactPhone is the phone branch of ContactEmailOrPhone.
+type ContactPhone struct {
+	Value string
+}
+
+func (ContactPhone) isContactEmailOrPhone() {}
+
+// MarshalXML encodes the value under the phone element.
+func (v ContactPhone) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	return e.EncodeElement(v.Value, xml.StartElement{Name: xml.Name{Local: "phone"}})
+}
+
+// ValidateAt appends the violations found in the value to errs, using path as the location of the choice.
+func (v ContactPhone) ValidateAt(path string, errs *validation.Errors) {
+}
+
+// UnmarshalXML decodes v, handing the elements of its choices to contactBranches.
+func (v *Contact) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Contact
+	aux := struct {
+		Branches contactBranches `xml:",any"`
+		*plain
+	}{contactBranches{v}, (*plain)(v)}
+	return d.DecodeElement(&aux, &start)
+}
+
+// contactBranches decodes the branch elements of Contact into their branch types.
+type contactBranches struct{ v *Contact }
+
+func (c contactBranches) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	switch start.Name.Local {
+	case "email":
+		var b ContactEmail
+		if err := d.DecodeElement(&b.Value, &start); err != nil {
+			return err
+		}
+		c.v.EmailOrPhone = b
+		return nil
+	case "phone":
+		var b ContactPhone
+		if err := d.DecodeElement(&b.Value, &start); err != nil {
+			return err
+		}
+		c.v.EmailOrPhone = b
+		return nil
+	}
+	return d.Skip()
+}
+
+// RandomContact fills a new Contact with values generated by g, picking occurrences
+// and choice branches at random within the bounds of the schema. Past the limits of package
+// fixture, the content nested in it is the shortest valid one.
+func RandomContact(g helpers.ValueGenerator) Contact {
+	var v Contact
+	g = fixture.Nest(g)
+	switch fixture.Branch(g, 2, 0) {
+	case 0:
+		var b ContactEmail
+		b.Value = fixture.Value[string](g, "email", "xs:string", nil)
+		v.EmailOrPhone = b
+	case 1:
+		var b ContactPhone
+		b.Value = fixture.Value[string](g, "phone", "xs:string", nil)
+		v.EmailOrPhone = b
+	}
+	return v
+}
+
+type Payment struct {
+	Amount        xsdtypes.Decimal     `xml:"amount"`
+	CardOrVoucher PaymentCardOrVoucher `xml:"card|voucher"`
+	TagOrFlag     []PaymentTagOrFlag   `xml:"tag|flag"`
+}
+
+// Validate checks v against the cardinalities and facets declared in the schema
+// and reports every violation at once.
+func (v *Payment) Validate() error {
+	var errs validation.Errors
+	v.ValidateAt("", &errs)
+	return errs.Err()
+}
+
+// ValidateAt appends the violations found in v to errs, using path as the location of v.
+func (v *Payment) ValidateAt(path string, errs *validation.Errors) {
+	if v.CardOrVoucher != nil {
+		v.CardOrVoucher.ValidateAt(path, errs)
+	} else {
+		errs.Add(path, "choice requires one of %s", "card, voucher")
+	}
+	validation.CheckOccurs(path, len(v.TagOrFlag), 0, validation.Unbounded, errs)
+	for _, branch := range v.TagOrFlag {
+		branch.ValidateAt(path, errs)
+	}
+}
+
+// PaymentCardOrVoucher holds one branch of the card, voucher choice.
+type PaymentCardOrVoucher interface {
+	ValidateAt(path string, errs *validation.Errors)
+	isPaymentCardOrVoucher()
+}
+
+// PaymentCard is the card branch of PaymentCardOrVoucher.
+type PaymentCard struct {
+	Value string
+}
+
+func (PaymentCard) isPaymentCardOrVoucher() {}
+
+// MarshalXML encodes the value under the card element.
+func (v PaymentCard) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	return e.EncodeElement(v.Value, xml.StartElement{Name: xml.Name{Local: "card"}})
+}
+
+// ValidateAt appends the violations found in the value to errs, using path as the location of the choice.
+func (v PaymentCard) ValidateAt(path string, errs *validation.Errors) {
+	validation.CheckValue(path+"/card", v.Value, facetsPaymentCard, false, errs)
+}
+
+var facetsPaymentCard = &validation.Facets{
+	Pattern: "[A-Z]{3}-\\d{2}",
+}
+
+// PaymentVoucher is the voucher branch of PaymentCardOrVoucher.
+type PaymentVoucher struct {
+	Value string
+}
+
+func (PaymentVoucher) isPaymentCardOrVoucher() {}
+
+// MarshalXML encodes the value under the voucher element.
+func (v PaymentVoucher) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	return e.EncodeElement(v.Value, xml.StartElement{Name: xml.Name{Local: "voucher"}})
+}
+
+// ValidateAt appends the violations found in the value to errs, using path as the location of the choice.
+func (v PaymentVoucher) ValidateAt(path string, errs *validation.Errors) {
+}
+
+// PaymentTagOrFlag holds one branch of the tag, flag choice.
+type PaymentTagOrFlag interface {
+	ValidateAt(path string, errs *validation.Errors)
+	isPaymentTagOrFlag()
+}
+
+// PaymentTag is the tag branch of PaymentTagOrFlag.
+type PaymentTag struct {
+	Value string
+}
+
+func (PaymentTag) isPaymentTagOrFlag() {}
+
+// MarshalXML encodes the value under the tag element.
+func (v PaymentTag) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	return e.EncodeElement(v.Value, xml.StartElement{Name: xml.Name{Local: "tag"}})
+}
+
+// ValidateAt appends the violations found in the value to errs, using path as the location of the choice.
+func (v PaymentTag) ValidateAt(path string, errs *validation.Errors) {
+}
+
+// PaymentFlag is the flag branch of PaymentTagOrFlag.
+type PaymentFlag struct {
+	Value Status
+}
+
+func (PaymentFlag) isPaymentTagOrFlag() {}
+
+// MarshalXML encodes the value under the flag element.
+func (v PaymentFlag) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	return e.EncodeElement(v.Value, xml.StartElement{Name: xml.Name{Local: "flag"}})
+}
+
+// ValidateAt appends the violations found in the value to errs, using path as the location of the choice.
+func (v PaymentFlag) ValidateAt(path string, errs *validation.Errors) {
+	validation.CheckValue(path+"/flag", v.Value, nil, false, errs)
+}
+
+// UnmarshalXML decodes v, handing the elements of its choices to paymentBranches.
+func (v *Payment) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Payment
+	aux := struct {
+		Branches paymentBranches `xml:",any"`
+		*plain
+	}{paymentBranches{v}, (*plain)(v)}
+	return d.DecodeElement(&aux, &start)
+}
+
+// paymentBranches decodes the branch elements of Payment into their branch types.
+type paymentBranches struct{ v *Payment }
+
+func (c paymentBranches) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	switch start.Name.Local {
+	case "card":
+		var b PaymentCard
+		if err := d.DecodeElement(&b.Value, &start); err != nil {
+			return err
+		}
+		c.v.CardOrVoucher = b
+		return nil
+	case "voucher":
+		var b PaymentVoucher
+		if err := d.DecodeElement(&b.Value, &start); err != nil {
+			return err
+		}
+		c.v.CardOrVoucher = b
+		return nil
+	case "tag":
+		var b PaymentTag
+		if err := d.DecodeElement(&b.Value, &start); err != nil {
+			return err
+		}
+		c.v.TagOrFlag = append(c.v.TagOrFlag, b)
+		return nil
+	case "flag":
+		var b PaymentFlag
+		if err := d.DecodeElement(&b.Value, &start); err != nil {
+			return err
+		}
+		c.v.TagOrFlag = append(c.v.TagOrFlag, b)
+		return nil
+	}
+	return d.Skip()
+}
+
+// RandomPayment fills a new Payment with values generated by g, picking occurrences
+// and choice branches at random within the bounds of the schema. Past the limits of package
+// fixture, the content nested in it is the shortest valid one.
+func RandomPayment(g helpers.ValueGenerator) Payment {
+	var v Payment
+	g = fixture.Nest(g)
+	v.Amount = fixture.Value[xsdtypes.Decimal](g, "amount", "xs:decimal", nil)
+	switch fixture.Branch(g, 2, 0) {
+	case 0:
+		var b PaymentCard
+		b.Value = fixture.Value[string](g, "card", "xs:string", facetsPaymentCard)
+		v.CardOrVoucher = b
+	case 1:
+		var b PaymentVoucher
+		b.Value = fixture.Value[string](g, "voucher", "xs:string", nil)
+		v.CardOrVoucher = b
+	}
+	for n := fixture.Occurs(g, "0", "unbounded"); n > 0; n-- {
+		switch fixture.Branch(g, 2, 0) {
+		case 0:
+			var b PaymentTag
+			b.Value = fixture.Value[string](g, "tag", "xs:string", nil)
+			v.TagOrFlag = append(v.TagOrFlag, b)
+		case 1:
+			var b PaymentFlag
+			b.Value = RandomStatus(g)
+			v.TagOrFlag = append(v.TagOrFlag, b)
+		}
+	}
+	return v
+}
+
+type Order struct {
+	XMLName   xml.Name          `xml:"http://example.com/golden order"`
+	Created   xsdtypes.DateTime `xml:"created"`
+	Contact   Contact           `xml:"contact"`
+	Line      []Line            `xml:"line"`
+	Payment   *Payment          `xml:"payment,omitempty"`
+	Version   string            `xml:"version,attr,omitempty"`
+	Reference *xsdtypes.Integer `xml:"reference,attr,omitempty"`
+}
+
+// Validate checks v against the cardinalities and facets declared in the schema
+// and reports every violation at once.
+func (v *Order) Validate() error {
+	var errs validation.Errors
+	v.ValidateAt("/order", &errs)
+	return errs.Err()
+}
+
+// ValidateAt appends the violations found in v to errs, using path as the location of v.
+func (v *Order) ValidateAt(path string, errs *validation.Errors) {
+	v.Contact.ValidateAt(path+"/contact", errs)
+	validation.CheckOccurs(path+"/line", len(v.Line), 1, 3, errs)
+	for i := range v.Line {
+		v.Line[i].ValidateAt(validation.Index(path+"/line", i), errs)
+	}
+	if v.Payment != nil {
+		v.Payment.ValidateAt(path+"/payment", errs)
+	}
+	validation.CheckValue(path+"/@version", v.Version, facetsOrderVersion, true, errs)
+	validation.CheckValue(path+"/@reference", v.Reference, facetsOrderReference, true, errs)
+}
+
+var facetsOrderVersion = &validation.Facets{
+	Fixed: "1.0",
+}
+
+var facetsOrderReference = &validation.Facets{
+	MinInclusive: "1",
+}
+
+// RandomOrder fills a new Order with values generated by g, picking occurrences
+// and choice branches at random within the bounds of the schema. Past the limits of package
+// fixture, the content nested in it is the shortest valid one.
+func RandomOrder(g helpers.ValueGenerator) Order {
+	var v Order
+	g = fixture.Nest(g)
+	v.Created = fixture.Value[xsdtypes.DateTime](g, "created", "xs:dateTime", nil)
+	v.Contact = RandomContact(g)
+	for n := fixture.Occurs(g, "", "3"); n > 0; n-- {
+		v.Line = append(v.Line, RandomLine(g))
+	}
+	if fixture.Occurs(g, "0", "1") > 0 {
+		v.Payment = fixture.Ptr(RandomPayment(g))
+	}
+	v.Version = fixture.Value[string](g, "@version", "xs:string", facetsOrderVersion)
+	v.Reference = fixture.Ptr(fixture.Value[xsdtypes.Integer](g, "@reference", "xs:positiveInteger", facetsOrderReference))
+	return v
+}
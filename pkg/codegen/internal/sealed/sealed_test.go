@@ -0,0 +1,71 @@
+package sealed
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/xsdtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orderXML = `<order xmlns="http://example.com/golden" version="1.0">` +
+	`<created>2024-02-29T10:00:00Z</created>` +
+	`<contact><phone>555-0100</phone></contact>` +
+	`<line status="open"><code>ABC-12</code><quantity>3</quantity><price>19.99</price></line>` +
+	`<payment><amount>19.99</amount><card>XYZ-01</card><tag>gift</tag><flag>N/A</flag><tag>rush</tag></payment>` +
+	`</order>`
+
+func TestChoicesRoundTrip(t *testing.T) {
+	var order Order
+	require.NoError(t, xml.Unmarshal([]byte(orderXML), &order))
+
+	assert.Equal(t, ContactPhone{Value: "555-0100"}, order.Contact.EmailOrPhone)
+	require.NotNil(t, order.Payment)
+	assert.Equal(t, PaymentCard{Value: "XYZ-01"}, order.Payment.CardOrVoucher)
+	assert.Equal(t, []PaymentTagOrFlag{
+		PaymentTag{Value: "gift"}, PaymentFlag{Value: StatusNA}, PaymentTag{Value: "rush"},
+	}, order.Payment.TagOrFlag)
+	assert.NoError(t, order.Validate())
+
+	out, err := xml.Marshal(order)
+	require.NoError(t, err)
+	assert.Equal(t, orderXML, string(out))
+}
+
+func TestChoiceValidation(t *testing.T) {
+	order := Order{
+		Created: xsdtypes.NewDateTime(time.Date(2024, 2, 29, 10, 0, 0, 0, time.UTC)),
+		Line:    []Line{{Code: "ABC-12", Quantity: 3, Price: xsdtypes.NewDecimal(1999, 2), Status: StatusOpen}},
+		Payment: &Payment{CardOrVoucher: PaymentCard{Value: "bad"}},
+	}
+	err := order.Validate()
+	require.Error(t, err)
+
+	var errs validation.Errors
+	require.ErrorAs(t, err, &errs)
+	paths := map[string]bool{}
+	for _, e := range errs {
+		paths[e.Path] = true
+	}
+	assert.True(t, paths["/order/contact"], "missing choice not reported:\n%v", err)
+	assert.True(t, paths["/order/payment/card"], "branch facets not checked:\n%v", err)
+}
+
+func TestRandomOrderRoundTrip(t *testing.T) {
+	for range 20 {
+		order := RandomOrder(helpers.DefaultValueGenerator{})
+		require.NotNil(t, order.Contact.EmailOrPhone)
+		assert.NotEmpty(t, order.Line)
+
+		out, err := xml.Marshal(order)
+		require.NoError(t, err)
+		var decoded Order
+		require.NoError(t, xml.Unmarshal(out, &decoded))
+		assert.Equal(t, order.Contact, decoded.Contact)
+		assert.Equal(t, order.Payment, decoded.Payment)
+	}
+}
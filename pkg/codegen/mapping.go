@@ -0,0 +1,165 @@
+package codegen
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TypeMapping customises the Go types and field names chosen by the generator.
+// It is usually loaded from a YAML or JSON file with LoadTypeMapping:
+//
+//	types:
+//	  tns:MoneyType: github.com/acme/money.Amount
+//	  xs:decimal:
+//	    type: github.com/shopspring/decimal.Decimal
+//	    optional: pointer
+//	  xs:string:
+//	    type: string
+//	    slice: github.com/acme/text.Lines
+//	fields:
+//	  USAddress/zip: PostalCode
+//	  USAddress/@country: CountryCode
+//	  purchaseOrder/items/item/USPrice: UnitPrice
+//	optional: auto
+//	packages:
+//	  http://example.com/common: github.com/acme/schemas/common
+type TypeMapping struct {
+	// Types overrides the Go type of schema types by QName, as written in the schema ("tns:MoneyType").
+	// Built-in types match with either the xs: or the xsd: prefix.
+	Types map[string]GoType `json:"types" yaml:"types"`
+	// Fields overrides the Go name of fields by schema path: the name of the named complexType or global
+	// element declaring the field, followed by the names of the elements with an inline type leading to
+	// it and by the field name; attributes are written "@name". Type.Path holds the prefix of every type.
+	Fields map[string]string `json:"fields" yaml:"fields"`
+	// Optional is the default pointer policy of optional single-valued fields.
+	Optional PointerPolicy `json:"optional" yaml:"optional"`
+	// Packages maps target namespaces to the import path of the Go package generated for them
+	// by GeneratePackages.
+	Packages map[string]string `json:"packages" yaml:"packages"`
+}
+
+// GoType is the Go type a schema type maps to. In a file it is either written as a string holding
+// the type, or as an object with the fields below.
+type GoType struct {
+	// Type is a predeclared type ("float64"), a type of the generated package, or a type qualified
+	// by its full import path ("github.com/acme/money.Amount"), in which case the import is added.
+	Type string `json:"type" yaml:"type"`
+	// Slice is the Go type of repeated occurrences, written like Type. It must be a slice of Type;
+	// by default repeated occurrences are a []Type.
+	Slice string `json:"slice" yaml:"slice"`
+	// Optional overrides TypeMapping.Optional for fields of this type.
+	Optional PointerPolicy `json:"optional" yaml:"optional"`
+}
+
+// PointerPolicy decides whether optional single-valued fields are pointers.
+type PointerPolicy string
+
+const (
+	// PointerAuto uses pointers only where encoding/xml could not tell an absent value from an empty one:
+	// generated structs and the struct types of pkg/xsdtypes. It is the default.
+	PointerAuto PointerPolicy = "auto"
+	// PointerAlways makes every optional single-valued field a pointer.
+	PointerAlways PointerPolicy = "pointer"
+	// PointerNever keeps every optional single-valued field a value.
+	PointerNever PointerPolicy = "value"
+)
+
+// UnmarshalYAML accepts both the short form, a plain string, and the object form of a GoType.
+func (t *GoType) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&t.Type)
+	}
+	type plain GoType
+	return node.Decode((*plain)(t))
+}
+
+// LoadTypeMapping reads a type mapping from a YAML file. JSON being a subset of YAML, JSON files are accepted too.
+func LoadTypeMapping(path string) (*TypeMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read type mapping: %w", err)
+	}
+	var m TypeMapping
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse type mapping %s: %w", path, err)
+	}
+	if err := m.check(); err != nil {
+		return nil, fmt.Errorf("type mapping %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// check rejects unknown pointer policies and empty types.
+func (m *TypeMapping) check() error {
+	if !m.Optional.valid() {
+		return fmt.Errorf("unknown pointer policy %q", m.Optional)
+	}
+	for qname, t := range m.Types {
+		if t.Type == "" {
+			return fmt.Errorf("no Go type given for %s", qname)
+		}
+		if !t.Optional.valid() {
+			return fmt.Errorf("unknown pointer policy %q for %s", t.Optional, qname)
+		}
+	}
+	return nil
+}
+
+func (p PointerPolicy) valid() bool {
+	switch p {
+	case "", PointerAuto, PointerAlways, PointerNever:
+		return true
+	}
+	return false
+}
+
+// lookup returns the override of a type QName, trying both conventional prefixes for built-ins.
+func (m *TypeMapping) lookup(qname string) (GoType, bool) {
+	if m == nil {
+		return GoType{}, false
+	}
+	if t, ok := m.Types[qname]; ok {
+		return t, true
+	}
+	if isBuiltinQName(qname) {
+		local := localName(qname)
+		for _, prefix := range []string{"xs:", "xsd:"} {
+			if t, ok := m.Types[prefix+local]; ok {
+				return t, true
+			}
+		}
+	}
+	return GoType{}, false
+}
+
+// fieldName returns the Go name configured for the field at path, if any.
+func (m *TypeMapping) fieldName(path string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	name, ok := m.Fields[path]
+	return name, ok && name != ""
+}
+
+var (
+	// qualifiedType matches an optional "*" or "[]" prefix, an import path and a type name,
+	// as in "*github.com/acme/money.Amount" or "time.Time".
+	qualifiedType = regexp.MustCompile(`^((?:\*|\[\])*)([^*\[\]]+)\.([A-Za-z_]\w*)$`)
+	// versionSuffix matches the major version of an import path, as in "/v2" or gopkg.in's ".v3".
+	versionSuffix = regexp.MustCompile(`[/.]v\d+$`)
+)
+
+// goTypeRef splits a configured Go type into the type expression used in the source and its import path.
+// "github.com/acme/money.Amount" gives "money.Amount" and "github.com/acme/money"; types without an
+// import path are returned unchanged. The package name is derived by packageName, so the import path must
+// not need an alias.
+func goTypeRef(typ string) (string, string) {
+	m := qualifiedType.FindStringSubmatch(typ)
+	if m == nil {
+		return typ, ""
+	}
+	return m[1] + packageName(m[2]) + "." + m[3], m[2]
+}
@@ -0,0 +1,68 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateWithTypeMapping(t *testing.T) {
+	mapping, err := LoadTypeMapping(filepath.Join("testdata", "mapping.yaml"))
+	require.NoError(t, err)
+	schema, err := parser.ParseXSD(filepath.Join("testdata", "mapping.xsd"), nil)
+	require.NoError(t, err)
+
+	src, err := Generate(schema, Options{TypeMapping: mapping})
+	require.NoError(t, err, string(src))
+
+	out := string(src)
+	for _, imp := range []string{`"github.com/acme/money"`, `"github.com/acme/text"`, `"github.com/shopspring/decimal"`} {
+		assert.Contains(t, out, imp)
+	}
+	assert.Regexp(t, `Amount\s+money\.Amount\s+`+"`"+`xml:"total" xsd:"minInclusive=0"`+"`", out)
+	assert.Regexp(t, `Discount\s+\*money\.Amount\s`, out, "the global policy makes optional fields pointers")
+	assert.Regexp(t, `Rate\s+decimal\.Decimal\s`, out, "the per-type policy wins over the global one")
+	assert.Regexp(t, `Memo\s+text\.Lines\s`, out)
+	assert.Regexp(t, `Number\s+\*int\s+`+"`"+`xml:"no,attr,omitempty"`+"`", out)
+	assert.Contains(t, out, "validation.CheckValue(path+\"/total\", v.Amount, facetsInvoiceAmount, false, errs)")
+	assert.NotContains(t, out, "xsdtypes")
+}
+
+func TestLoadTypeMappingFromJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "types.json")
+	data := `{"types": {"xsd:dateTime": "time.Time", "tns:id": {"type": "gopkg.in/guregu/null.v4.String"}}}`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+
+	mapping, err := LoadTypeMapping(path)
+	require.NoError(t, err)
+	got, ok := mapping.lookup("xs:dateTime")
+	require.True(t, ok, "built-ins match whatever their prefix")
+	assert.Equal(t, "time.Time", got.Type)
+	assert.Equal(t, "gopkg.in/guregu/null.v4.String", mapping.Types["tns:id"].Type)
+}
+
+func TestLoadTypeMappingRejectsUnknownPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "types.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("optional: sometimes\n"), 0o600))
+	_, err := LoadTypeMapping(path)
+	assert.Error(t, err)
+}
+
+func TestGoTypeRef(t *testing.T) {
+	cases := map[string][2]string{
+		"float64":                         {"float64", ""},
+		"time.Time":                       {"time.Time", "time"},
+		"*github.com/acme/money.Amount":   {"*money.Amount", "github.com/acme/money"},
+		"github.com/acme/money/v2.Amount": {"money.Amount", "github.com/acme/money/v2"},
+		"gopkg.in/guregu/null.v4.String":  {"null.String", "gopkg.in/guregu/null.v4"},
+		"github.com/acme/go-money.Amount": {"money.Amount", "github.com/acme/go-money"},
+	}
+	for input, want := range cases {
+		ref, importPath := goTypeRef(input)
+		assert.Equal(t, want, [2]string{ref, importPath}, input)
+	}
+}
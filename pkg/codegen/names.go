@@ -0,0 +1,60 @@
+package codegen
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GoName turns an XSD name into an exported Go identifier.
+// Characters that are neither letters nor digits act as word separators and every word is capitalised,
+// so "purchase-order" and "purchaseOrder" both become "PurchaseOrder". Names starting with a digit get an "X" prefix.
+func GoName(name string) string {
+	ident := joinWords(name)
+	if ident == "" {
+		return "X"
+	}
+	if unicode.IsDigit([]rune(ident)[0]) {
+		return "X" + ident
+	}
+	return ident
+}
+
+// joinWords capitalises each letter/digit run of s and concatenates them.
+func joinWords(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var sb strings.Builder
+	for _, w := range words {
+		runes := []rune(w)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	return sb.String()
+}
+
+// enumConstName builds the constant name of one enumeration value.
+// The value is mangled like GoName and prefixed with the enum type name, which keeps values
+// such as "1st" legal. A value without any letter or digit (e.g. "" or "/") becomes "Empty".
+func enumConstName(typeName, value string) string {
+	suffix := joinWords(value)
+	if suffix == "" {
+		suffix = "Empty"
+	}
+	return typeName + suffix
+}
+
+// nameSet hands out unique identifiers in a deterministic way.
+type nameSet map[string]bool
+
+// claim reserves name, appending "_2", "_3", ... when it is already taken.
+// Generated identifiers never contain underscores otherwise, so the suffixed form cannot collide.
+func (n nameSet) claim(name string) string {
+	candidate := name
+	for i := 2; n[candidate]; i++ {
+		candidate = name + "_" + strconv.Itoa(i)
+	}
+	n[candidate] = true
+	return candidate
+}
@@ -0,0 +1,173 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+)
+
+// Package is one generated Go package.
+type Package struct {
+	ImportPath string
+	Name       string
+	Source     []byte
+}
+
+// GeneratePackages generates one Go package per namespace listed in TypeMapping.Packages, plus the package
+// named Options.Package at Options.ImportPath holding the types of every other namespace. Types of other
+// packages are referenced through their import path. Packages without any type are skipped, and import
+// cycles between the generated packages are reported as errors.
+// Without a Packages mapping it generates a single package, like Generate.
+func GeneratePackages(schema *model.XSDSchema, opts Options) ([]*Package, error) {
+	if opts.TypeMapping == nil || len(opts.TypeMapping.Packages) == 0 {
+		src, err := Generate(schema, opts)
+		if err != nil {
+			return nil, err
+		}
+		return []*Package{{ImportPath: opts.ImportPath, Name: packageNameOr(opts.Package), Source: src}}, nil
+	}
+	if opts.ImportPath == "" {
+		return nil, fmt.Errorf("an import path for package %s is required to split namespaces into packages",
+			packageNameOr(opts.Package))
+	}
+	layout := &packageLayout{defaultPath: opts.ImportPath, paths: opts.TypeMapping.Packages}
+	var packages []*Package
+	imports := map[string][]string{}
+	for _, importPath := range layout.importPaths() {
+		b, err := newConfiguredBuilder(schema, opts)
+		if err != nil {
+			return nil, err
+		}
+		b.layout, b.pkg = layout, importPath
+		file, err := b.build()
+		if err != nil {
+			return nil, fmt.Errorf("package %s: %w", importPath, err)
+		}
+		if len(file.Types) == 0 && len(file.Enums) == 0 {
+			continue
+		}
+		file.Package = packageName(importPath)
+		if importPath == opts.ImportPath {
+			file.Package = packageNameOr(opts.Package)
+		}
+		src, err := render(file, opts.TemplateDir)
+		if err != nil {
+			return nil, fmt.Errorf("package %s: %w", importPath, err)
+		}
+		packages = append(packages, &Package{ImportPath: importPath, Name: file.Package, Source: src})
+		imports[importPath] = file.Imports
+	}
+	if err := checkImportCycles(imports); err != nil {
+		return nil, err
+	}
+	return packages, nil
+}
+
+// packageLayout assigns the namespaces of a schema to Go packages.
+type packageLayout struct {
+	defaultPath string            // import path of the package holding unmapped namespaces
+	paths       map[string]string // import path by namespace
+}
+
+// importPath returns the import path of the package holding the types of namespace.
+func (l *packageLayout) importPath(namespace string) string {
+	if path, ok := l.paths[namespace]; ok && path != "" {
+		return path
+	}
+	return l.defaultPath
+}
+
+// importPaths lists the default package first, then the mapped packages in lexical order.
+func (l *packageLayout) importPaths() []string {
+	paths := map[string]bool{}
+	for _, path := range l.paths {
+		if path != "" && path != l.defaultPath {
+			paths[path] = true
+		}
+	}
+	return append([]string{l.defaultPath}, sortedKeys(paths)...)
+}
+
+// originOf returns the origin of a top-level component. Hand-built schemas carry no origin,
+// in which case the components belong to the root schema.
+func (b *builder) originOf(origin *model.XSDOrigin) *model.XSDOrigin {
+	if origin != nil {
+		return origin
+	}
+	return b.root
+}
+
+// owns reports whether components of origin are generated into the package being built.
+func (b *builder) owns(origin *model.XSDOrigin) bool {
+	return b.layout == nil || b.layout.importPath(origin.TargetNamespace) == b.pkg
+}
+
+// qualify returns the Go expression referring to a generated type, qualified with its package name when
+// it lives in another package. kinds records the qualified expression as a struct or an enum.
+func (b *builder) qualify(goName string, origin *model.XSDOrigin, kinds map[string]bool) string {
+	if b.owns(origin) {
+		return goName
+	}
+	importPath := b.layout.importPath(origin.TargetNamespace)
+	b.imports[importPath] = true
+	ref := packageName(importPath) + "." + goName
+	kinds[ref] = true
+	return ref
+}
+
+// packageName derives the name of a Go package from its import path: the last element, without
+// a major version suffix or a "go-" prefix, and with other characters invalid in identifiers replaced.
+func packageName(importPath string) string {
+	path := versionSuffix.ReplaceAllString(importPath, "")
+	name := path[strings.LastIndex(path, "/")+1:]
+	return strings.NewReplacer("-", "_", ".", "_").Replace(strings.TrimPrefix(name, "go-"))
+}
+
+func packageNameOr(name string) string {
+	if name == "" {
+		return DefaultPackage
+	}
+	return name
+}
+
+// checkImportCycles reports an error when generated packages import each other in a cycle,
+// which happens when the types of two namespaces refer to each other.
+func checkImportCycles(imports map[string][]string) error {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+	var visit func(path string, stack []string) error
+	visit = func(path string, stack []string) error {
+		switch state[path] {
+		case visiting:
+			return fmt.Errorf("import cycle between generated packages: %s", strings.Join(append(stack, path), " -> "))
+		case done:
+			return nil
+		}
+		state[path] = visiting
+		for _, imp := range imports[path] {
+			if _, generated := imports[imp]; generated {
+				if err := visit(imp, append(stack, path)); err != nil {
+					return err
+				}
+			}
+		}
+		state[path] = done
+		return nil
+	}
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := visit(path, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const multinsImportPath = "github.com/Patrick-Ivann/xsd-codegen/pkg/codegen/internal/multins"
+
+func TestMultiNamespaceGoldenFilesUpToDate(t *testing.T) {
+	schema, err := parser.ParseXSD(filepath.Join("testdata", "multins", "orders.xsd"), nil)
+	require.NoError(t, err)
+	mapping, err := LoadTypeMapping(filepath.Join("testdata", "multins", "packages.yaml"))
+	require.NoError(t, err)
+
+	packages, err := GeneratePackages(schema, Options{
+		Package:     "multins",
+		ImportPath:  multinsImportPath,
+		TypeMapping: mapping,
+		Fixtures:    true,
+	})
+	require.NoError(t, err)
+	require.Len(t, packages, 2)
+	assert.Equal(t, "multins", packages[0].Name)
+	assert.Equal(t, "common", packages[1].Name)
+
+	for _, pkg := range packages {
+		rel, err := filepath.Rel(multinsImportPath, pkg.ImportPath)
+		require.NoError(t, err)
+		golden, err := os.ReadFile(filepath.Join("internal", "multins", rel, pkg.Name+".go"))
+		require.NoError(t, err)
+		assert.Equal(t, string(golden), string(pkg.Source), "golden file is stale, run go generate ./pkg/codegen")
+	}
+}
+
+func TestGeneratePackagesWithoutMappingGeneratesOnePackage(t *testing.T) {
+	schema, err := parser.ParseXSD(filepath.Join("testdata", "multins", "orders.xsd"), nil)
+	require.NoError(t, err)
+
+	packages, err := GeneratePackages(schema, Options{Package: "orders"})
+	require.NoError(t, err)
+	require.Len(t, packages, 1)
+	assert.Contains(t, string(packages[0].Source), "type Address struct")
+
+	mapping := &TypeMapping{Packages: map[string]string{"http://example.com/common": "example.com/common"}}
+	_, err = GeneratePackages(schema, Options{TypeMapping: mapping})
+	assert.ErrorContains(t, err, "import path")
+}
+
+func TestGeneratePackagesRejectsImportCycles(t *testing.T) {
+	a := &model.XSDOrigin{TargetNamespace: "urn:a"}
+	b := &model.XSDOrigin{TargetNamespace: "urn:b"}
+	schema := &model.XSDSchema{
+		ComplexTypes: []model.XSDComplexType{
+			{Name: "left", Origin: a, Sequence: &model.XSDSequence{Elements: []model.XSDElement{{Name: "right", Type: "b:right"}}}},
+			{Name: "right", Origin: b, Sequence: &model.XSDSequence{Elements: []model.XSDElement{
+				{Name: "left", Type: "a:left", MinOccurs: "0"},
+			}}},
+		},
+	}
+	mapping := &TypeMapping{Packages: map[string]string{"urn:b": "example.com/b"}}
+
+	_, err := GeneratePackages(schema, Options{ImportPath: "example.com/a", TypeMapping: mapping})
+	assert.ErrorContains(t, err, "import cycle between generated packages: example.com/a -> example.com/b -> example.com/a")
+}
+
+func TestPackageName(t *testing.T) {
+	assert.Equal(t, "common", packageName("example.com/schemas/common"))
+	assert.Equal(t, "money", packageName("github.com/acme/go-money/v2"))
+	assert.Equal(t, "yaml", packageName("gopkg.in/yaml.v3"))
+}
@@ -0,0 +1,115 @@
+package codegen
+
+import (
+	"strconv"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+)
+
+// validationImport is the runtime package used by the generated Validate methods.
+const validationImport = "github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+
+// newFacets collects the facets of a restriction plus a fixed value, returning nil when there is nothing to check.
+// Enumerations of generated enum types are not repeated here since IsValid already enforces them. The bounds of
+// the built-in type xsdType, such as xs:positiveInteger, are added when the restriction sets none.
+func newFacets(varName string, r *model.XSDRestriction, fixed, xsdType string) *Facets {
+	f := &Facets{Var: varName, Fixed: fixed}
+	if r != nil {
+		f.MinInclusive = facetValue(r.MinIncl)
+		f.MaxInclusive = facetValue(r.MaxIncl)
+		f.MinExclusive = facetValue(r.MinExcl)
+		f.MaxExclusive = facetValue(r.MaxExcl)
+		f.Length = facetValue(r.Length)
+		f.MinLength = facetValue(r.MinLength)
+		f.MaxLength = facetValue(r.MaxLength)
+		f.TotalDigits = facetValue(r.TotalDigits)
+		f.FractionDigits = facetValue(r.FractionDigits)
+		if r.Pattern != nil {
+			f.Pattern = r.Pattern.Value
+		}
+		if !isEnumeration(&model.XSDSimpleType{Restriction: r}) {
+			for _, e := range r.Enumerations {
+				f.Enumerations = append(f.Enumerations, e.Value)
+			}
+		}
+	}
+	if bounds, ok := integerBounds[localName(xsdType)]; ok {
+		if f.MinInclusive == "" && f.MinExclusive == "" {
+			f.MinInclusive = bounds[0]
+		}
+		if f.MaxInclusive == "" && f.MaxExclusive == "" {
+			f.MaxInclusive = bounds[1]
+		}
+	}
+	if f.empty() {
+		return nil
+	}
+	return f
+}
+
+// empty reports whether no facet is set.
+func (f *Facets) empty() bool {
+	lexical := f.MinInclusive + f.MaxInclusive + f.MinExclusive + f.MaxExclusive + f.Length + f.MinLength +
+		f.MaxLength + f.TotalDigits + f.FractionDigits + f.Pattern + f.Fixed
+	return lexical == "" && len(f.Enumerations) == 0
+}
+
+// validation returns the facets as the validation package checks them, to check values at code generation.
+func (f *Facets) validation() *validation.Facets {
+	return &validation.Facets{
+		MinInclusive: f.MinInclusive, MaxInclusive: f.MaxInclusive,
+		MinExclusive: f.MinExclusive, MaxExclusive: f.MaxExclusive,
+		Length: f.Length, MinLength: f.MinLength, MaxLength: f.MaxLength,
+		TotalDigits: f.TotalDigits, FractionDigits: f.FractionDigits,
+		Pattern: f.Pattern, Enumerations: f.Enumerations, Fixed: f.Fixed,
+	}
+}
+
+func facetValue(v *model.XSDValue) string {
+	if v == nil {
+		return ""
+	}
+	return v.Value
+}
+
+// minOccurs renders the minimum number of occurrences of a field as a Go expression.
+func minOccurs(val string) string {
+	if val == "" {
+		return "1"
+	}
+	return val
+}
+
+// maxOccurs renders the maximum number of occurrences of a field as a Go expression.
+func maxOccurs(val string) string {
+	switch val {
+	case "":
+		return "1"
+	case "unbounded":
+		return "validation.Unbounded"
+	}
+	if _, err := strconv.Atoi(val); err != nil {
+		return "1"
+	}
+	return val
+}
+
+// facetsVar returns the name of the facets variable of a field, or nil when it has none.
+func facetsVar(f *Field) string {
+	if f.Facets == nil {
+		return "nil"
+	}
+	return f.Facets.Var
+}
+
+// fieldLocation pairs a field with the path segment locating it below its parent, such as "/name" or "/@id".
+type fieldLocation struct {
+	Field *Field
+	Path  string
+}
+
+// fieldPath builds the fieldLocation of f, prefixing its XML name with sep.
+func fieldPath(f *Field, sep string) fieldLocation {
+	return fieldLocation{Field: f, Path: sep + f.Name}
+}
@@ -0,0 +1,172 @@
+// Package fixture fills the types generated by pkg/codegen with random values.
+//
+// The RandomX functions generated with codegen.Options.Fixtures call it with the value generator of the
+// caller, so typed fixtures follow the same value logic and occurrence rules as the XML generator of pkg/xmlgen.
+package fixture
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/xsdtypes"
+)
+
+// Value generates a value of the built-in XSD type xsdType with g, constrained by facets when not nil,
+// and decodes it into a T the way encoding/xml decodes element content. A fixed value is always used as is.
+// name is the name of the field in XML, prefixed with @ for attributes, which g gets as the name and path
+// of the value; fixtures do not know where their values are nested. The values g declines are random, and
+// so are the values g produces that are not a valid T. Value panics with name and the text when even that
+// text does not decode, which happens only when facets or the fixed value contradict xsdType.
+func Value[T any](g helpers.ValueGenerator, name, xsdType string, facets *validation.Facets) T {
+	vc := &helpers.ValueContext{
+		Path:        name,
+		Name:        xml.Name{Local: strings.TrimPrefix(name, "@")},
+		Attribute:   strings.HasPrefix(name, "@"),
+		Base:        xsdType,
+		Restriction: restriction(xsdType, facets),
+		Rand:        helpers.RandOf(g),
+	}
+	var text string
+	if facets != nil && facets.Fixed != "" {
+		text = facets.Fixed
+	} else {
+		text = generate(g, vc)
+	}
+	var v T
+	err := xsdtypes.Decode(text, &v)
+	if err != nil && (facets == nil || facets.Fixed == "") {
+		text = vc.Rand.GenerateValue(xsdType, vc.Restriction)
+		err = xsdtypes.Decode(text, &v)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("fixture: %s is not a valid %s: %v", name, xsdType, err))
+	}
+	return v
+}
+
+// Enum picks one of values with g, which receives them as the enumeration of the simple type typeName
+// restricting the built-in type xsdType.
+func Enum[T ~string](g helpers.ValueGenerator, typeName, xsdType string, values ...T) T {
+	r := &model.XSDRestriction{Base: xsdType}
+	for _, v := range values {
+		r.Enumerations = append(r.Enumerations, model.XSDValue{Value: string(v)})
+	}
+	return T(generate(g, &helpers.ValueContext{Type: typeName, Base: xsdType, Restriction: r, Rand: helpers.RandOf(g)}))
+}
+
+// generate asks g for the value described by vc, and draws a random one when g declines.
+func generate(g helpers.ValueGenerator, vc *helpers.ValueContext) string {
+	if value, ok := g.Generate(vc); ok {
+		return value
+	}
+	return vc.Rand.GenerateValue(vc.Base, vc.Restriction)
+}
+
+// Limits of the content generated by one call of a RandomX function, the root type being at depth 1.
+// Past MaxDepth, or once MaxStructs structs are generated, RandomX functions complete their value in the
+// shortest valid way: minOccurs occurrences of each particle and the shortest branch of each choice, which
+// is the non-recursive one, so that recursive types stay finite. Types whose required content requires
+// themselves, which no finite value satisfies, are left empty there.
+const (
+	MaxDepth   = 12
+	MaxStructs = 1000
+)
+
+// nested is the generator of the content of a type at depth, counting the structs generated.
+type nested struct {
+	helpers.ValueGenerator
+	depth   int
+	structs *int
+}
+
+// Rand implements helpers.Randomized with the Rand of the generator it wraps.
+func (n nested) Rand() *helpers.Rand {
+	return helpers.RandOf(n.ValueGenerator)
+}
+
+// Nest returns the generator of the content of a struct generated with g, one level deeper than the struct
+// whose content g generates. The generated RandomX functions call it first, so that Occurs and Branch know
+// how deep and how large the value is.
+func Nest(g helpers.ValueGenerator) helpers.ValueGenerator {
+	if n, ok := g.(nested); ok {
+		*n.structs++
+		n.depth++
+		return n
+	}
+	structs := 1
+	return nested{ValueGenerator: g, depth: 1, structs: &structs}
+}
+
+// Deep reports whether g generates content past the limits, which gets the shortest valid content.
+func Deep(g helpers.ValueGenerator) bool {
+	n, ok := g.(nested)
+	return ok && (n.depth > MaxDepth || *n.structs >= MaxStructs)
+}
+
+// Occurs returns how many occurrences of a particle to generate, between minOccurs and maxOccurs as
+// written in the schema. Like in generated XML, unbounded particles get at most three occurrences.
+// The count is drawn from the Rand of g when it implements helpers.Randomized, and is minOccurs past the
+// limits.
+func Occurs(g helpers.ValueGenerator, minOccurs, maxOccurs string) int {
+	r := helpers.RandOf(g)
+	if Deep(g) {
+		return r.ParseOccurs(minOccurs, 1)
+	}
+	return r.RandomBetween(r.ParseOccurs(minOccurs, 1), r.ParseOccurs(maxOccurs, 1))
+}
+
+// Choose returns the index of the branch to generate among the n branches of a choice, drawn like Occurs.
+func Choose(g helpers.ValueGenerator, n int) int {
+	return helpers.RandOf(g).RandomBetween(0, n-1)
+}
+
+// Branch returns the index of the branch to generate among the n branches of a choice, drawn like Choose,
+// or shortest, the branch with the shortest valid content, past the limits.
+func Branch(g helpers.ValueGenerator, n, shortest int) int {
+	if Deep(g) {
+		return shortest
+	}
+	return Choose(g, n)
+}
+
+// Ptr returns a pointer to v, for optional fields.
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// restriction turns facets back into the restriction of xsdType expected by value generators.
+func restriction(xsdType string, facets *validation.Facets) *model.XSDRestriction {
+	if facets == nil {
+		return nil
+	}
+	r := &model.XSDRestriction{
+		Base:           xsdType,
+		MinIncl:        value(facets.MinInclusive),
+		MaxIncl:        value(facets.MaxInclusive),
+		MinExcl:        value(facets.MinExclusive),
+		MaxExcl:        value(facets.MaxExclusive),
+		Length:         value(facets.Length),
+		MinLength:      value(facets.MinLength),
+		MaxLength:      value(facets.MaxLength),
+		TotalDigits:    value(facets.TotalDigits),
+		FractionDigits: value(facets.FractionDigits),
+	}
+	if facets.Pattern != "" {
+		r.Pattern = &model.XSDPattern{Value: facets.Pattern}
+	}
+	for _, e := range facets.Enumerations {
+		r.Enumerations = append(r.Enumerations, model.XSDValue{Value: e})
+	}
+	return r
+}
+
+func value(lexical string) *model.XSDValue {
+	if lexical == "" {
+		return nil
+	}
+	return &model.XSDValue{Value: lexical}
+}
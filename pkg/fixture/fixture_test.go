@@ -0,0 +1,124 @@
+package fixture
+
+import (
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/xmlgen/mocks"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/xsdtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestValueDecodesGeneratedText(t *testing.T) {
+	g := new(mocks.MockValueGenerator)
+	g.On("Generate", mocks.Base("xs:int")).Return("42", true)
+	g.On("Generate", mocks.Base("xs:decimal")).Return("12.50", true)
+	g.On("Generate", mocks.Base("xs:boolean")).Return("oops", true)
+
+	assert.Equal(t, 42, Value[int](g, "quantity", "xs:int", nil))
+	assert.Equal(t, "12.50", Value[xsdtypes.Decimal](g, "price", "xs:decimal", nil).String())
+	assert.NotPanics(t, func() { Value[bool](g, "@paid", "xs:boolean", nil) }, "invalid text is replaced by a random value")
+	assert.Equal(t, "1.0", Value[string](g, "@version", "xs:string", &validation.Facets{Fixed: "1.0"}), "fixed values skip g")
+	g.AssertExpectations(t)
+}
+
+func TestValueReplacesInvalidText(t *testing.T) {
+	g := new(mocks.MockValueGenerator)
+	g.On("Generate", mock.Anything).Return("many", true)
+
+	for range 20 {
+		n := Value[int](g, "quantity", "xs:int", &validation.Facets{MinInclusive: "1", MaxInclusive: "9"})
+		assert.True(t, n >= 1 && n <= 9, "quantity %d outside 1..9", n)
+	}
+	assert.PanicsWithValue(t, `fixture: @count is not a valid xs:int: decode "many": strconv.ParseInt: parsing "many": invalid syntax`,
+		func() { Value[int](g, "@count", "xs:int", &validation.Facets{Fixed: "many"}) }, "fixed values are never replaced")
+}
+
+func TestValuePassesFacetsToGenerator(t *testing.T) {
+	g := new(mocks.MockValueGenerator)
+	expected := &model.XSDRestriction{
+		Base:      "xs:string",
+		MaxLength: &model.XSDValue{Value: "3"},
+		Pattern:   &model.XSDPattern{Value: "[a-z]+"},
+	}
+	g.On("Generate", mock.MatchedBy(func(vc *helpers.ValueContext) bool {
+		return vc.Name.Local == "code" && vc.Attribute && vc.Base == "xs:string" && assert.ObjectsAreEqual(expected, vc.Restriction)
+	})).Return("abc", true)
+
+	assert.Equal(t, "abc", Value[string](g, "@code", "xs:string", &validation.Facets{MaxLength: "3", Pattern: "[a-z]+"}))
+	g.AssertExpectations(t)
+}
+
+func TestValueIsRandomWhenGeneratorDeclines(t *testing.T) {
+	g := new(mocks.MockValueGenerator)
+	g.On("Generate", mock.Anything).Return("", false)
+
+	for range 20 {
+		assert.Regexp(t, `^[A-Z]{3}$`, Value[string](g, "code", "xs:string", &validation.Facets{Pattern: "[A-Z]{3}"}))
+	}
+}
+
+func TestRandomValuesHonourBoundedFacets(t *testing.T) {
+	g := new(mocks.MockValueGenerator)
+	g.On("Generate", mock.Anything).Return("", false)
+
+	for range 50 {
+		n := Value[int32](g, "quantity", "xs:int", &validation.Facets{MinInclusive: "1", MaxInclusive: "9"})
+		assert.True(t, n >= 1 && n <= 9, "quantity %d outside 1..9", n)
+		price := Value[xsdtypes.Decimal](g, "price", "xs:decimal", &validation.Facets{TotalDigits: "4", FractionDigits: "2"})
+		total, fraction, _ := validation.Digits(price.String())
+		assert.True(t, total <= 4 && fraction <= 2, "price %s has more than 4.2 digits", price)
+		assert.LessOrEqual(t, len(Value[string](g, "@code", "xs:string", &validation.Facets{MaxLength: "3"})), 3)
+	}
+}
+
+func TestEnumOffersEveryValue(t *testing.T) {
+	type color string
+	g := new(mocks.MockValueGenerator)
+	expected := &model.XSDRestriction{
+		Base:         "xs:token",
+		Enumerations: []model.XSDValue{{Value: "red"}, {Value: "blue"}},
+	}
+	g.On("Generate", mock.MatchedBy(func(vc *helpers.ValueContext) bool {
+		return vc.Type == "Color" && assert.ObjectsAreEqual(expected, vc.Restriction)
+	})).Return("blue", true)
+
+	assert.Equal(t, color("blue"), Enum(g, "Color", "xs:token", color("red"), color("blue")))
+	assert.Contains(t, []color{"red", "blue"}, Enum(helpers.DefaultValueGenerator{}, "Color", "xs:token", color("red"), color("blue")))
+}
+
+func TestNestedContentIsShortestPastTheLimits(t *testing.T) {
+	g := Nest(helpers.DefaultValueGenerator{Source: helpers.NewRand(1)})
+	assert.NotNil(t, helpers.RandOf(g), "nested generators keep the Rand of g")
+	for range MaxDepth - 1 {
+		g = Nest(g)
+	}
+	assert.False(t, Deep(g))
+	deep := Nest(g)
+	assert.True(t, Deep(deep))
+	for range 20 {
+		assert.Equal(t, 2, Occurs(deep, "2", "unbounded"))
+		assert.Equal(t, 0, Occurs(deep, "0", "1"))
+		assert.Equal(t, 1, Branch(deep, 3, 1))
+	}
+
+	wide := Nest(helpers.DefaultValueGenerator{})
+	for range MaxStructs - 1 {
+		Nest(wide)
+	}
+	assert.True(t, Deep(wide), "the structs of a value are counted across its fields")
+}
+
+func TestOccursStaysWithinBounds(t *testing.T) {
+	g := helpers.DefaultValueGenerator{}
+	for range 50 {
+		assert.Equal(t, 1, Occurs(g, "", ""))
+		assert.InDelta(t, 1, Occurs(g, "0", "2"), 1)
+		n := Occurs(g, "1", "unbounded")
+		assert.True(t, n >= 1 && n <= 3, "unbounded gives at most three occurrences, got %d", n)
+		assert.Less(t, Choose(g, 3), 3)
+	}
+}
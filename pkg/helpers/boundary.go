@@ -0,0 +1,248 @@
+package helpers
+
+import (
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+)
+
+// defaultFractionDigits is the number of fraction digits of the step between decimal boundary values
+// when the type sets no fractionDigits facet.
+const defaultFractionDigits = 2
+
+// integerRanges are the value spaces of the bounded built-in integer types.
+var integerRanges = map[string][2]string{
+	"byte":               {"-128", "127"},
+	"short":              {"-32768", "32767"},
+	"int":                {"-2147483648", "2147483647"},
+	"long":               {"-9223372036854775808", "9223372036854775807"},
+	"unsignedByte":       {"0", "255"},
+	"unsignedShort":      {"0", "65535"},
+	"unsignedInt":        {"0", "4294967295"},
+	"unsignedLong":       {"0", "18446744073709551615"},
+	"nonNegativeInteger": {"0", ""},
+	"positiveInteger":    {"1", ""},
+	"nonPositiveInteger": {"", "0"},
+	"negativeInteger":    {"", "-1"},
+}
+
+// calendarEdges are leap days, century years and the extreme timezones of the calendar types.
+var calendarEdges = map[string][]string{
+	"date": {"2024-02-29", "2000-02-29", "1900-02-28", "2024-02-29+14:00", "2024-02-29-14:00", "1999-12-31Z"},
+	"dateTime": {
+		"2024-02-29T00:00:00Z", "2000-02-29T23:59:59+14:00", "2024-02-29T00:00:00-14:00",
+		"1999-12-31T23:59:59Z", "2024-12-31T23:59:59-14:00",
+	},
+	"time":       {"00:00:00", "23:59:59", "00:00:00+14:00", "23:59:59-14:00", "12:00:00Z"},
+	"gYear":      {"2000", "1900", "2024"},
+	"gYearMonth": {"2024-02", "2000-02", "1999-12"},
+}
+
+// BoundaryProvider lists the boundary values of a type, as BoundaryValueGenerator does.
+type BoundaryProvider interface {
+	BoundaryValues(xsdType string, restriction *model.XSDRestriction) []string
+}
+
+// BoundaryValueGenerator generates values on the edges of the value space of their type, where
+// consumer bugs live, instead of values spread uniformly inside it. Each value is picked at random among
+// the boundary values of its type, and types without any, such as plain strings, get the values of
+// DefaultValueGenerator.
+type BoundaryValueGenerator struct {
+	Source *Rand
+}
+
+// Generate returns a boundary value of the type of the node, or a random one when the type has no
+// boundary. It never declines. Like DefaultValueGenerator, it draws from the Rand of vc when Source is nil.
+func (b BoundaryValueGenerator) Generate(vc *ValueContext) (string, bool) {
+	r := DefaultValueGenerator(b).rand(vc)
+	values := r.BoundaryValues(vc.Base, vc.Restriction)
+	if len(values) == 0 {
+		return r.GenerateValue(vc.Base, vc.Restriction), true
+	}
+	return values[r.Intn(len(values))], true
+}
+
+// BoundaryValues implements BoundaryProvider.
+func (b BoundaryValueGenerator) BoundaryValues(xsdType string, restriction *model.XSDRestriction) []string {
+	return b.Source.BoundaryValues(xsdType, restriction)
+}
+
+// Rand implements Randomized.
+func (b BoundaryValueGenerator) Rand() *Rand {
+	return b.Source
+}
+
+// BoundaryValues returns the valid values on the edges of a built-in type restricted by restriction,
+// which may be nil, facet by facet:
+//
+//   - min, min+1, max-1 and max for numbers, from the bounds or the range of the built-in integer
+//     types, with a step of 10^-fractionDigits for decimals;
+//   - the largest number of totalDigits and fractionDigits digits;
+//   - strings of exactly minLength, maxLength or length characters;
+//   - leap days, century years and the extreme timezones, +14:00 and -14:00, for calendar types, and
+//     the inclusive bounds of calendar facets.
+//
+// Candidates breaking another facet, such as the pattern, are left out. Enumerated types have no
+// boundary values: their values are all edges already.
+func (r *Rand) BoundaryValues(xsdType string, restriction *model.XSDRestriction) []string {
+	if restriction != nil && len(restriction.Enumerations) > 0 {
+		return nil
+	}
+	if restriction == nil {
+		restriction = &model.XSDRestriction{}
+	}
+	local := localTypeName(xsdType)
+	var candidates []string
+	switch {
+	case isIntegerType(local) || isDecimalType(local):
+		candidates = numericBoundaries(local, restriction)
+	case calendarEdges[local] != nil:
+		candidates = append(candidates, calendarEdges[local]...)
+		for _, v := range []*model.XSDValue{restriction.MinIncl, restriction.MaxIncl} {
+			if v != nil {
+				candidates = append(candidates, v.Value)
+			}
+		}
+	case isStringType(local):
+		for _, v := range []*model.XSDValue{restriction.MinLength, restriction.MaxLength, restriction.Length} {
+			if n, err := facetInt(v); err == nil && n >= 0 {
+				candidates = append(candidates, r.RandomString(n))
+			}
+		}
+	}
+
+	facets := FacetsOf(restriction)
+	var values []string
+	for _, c := range candidates {
+		var errs validation.Errors
+		validation.CheckValue("", c, facets, false, &errs)
+		if len(errs) == 0 && !slices.Contains(values, c) {
+			values = append(values, c)
+		}
+	}
+	return values
+}
+
+// numericBoundaries returns min, min+1, max-1 and max, and the values with the most digits.
+func numericBoundaries(local string, restriction *model.XSDRestriction) []string {
+	step := big.NewRat(1, 1)
+	fraction := 0
+	if isDecimalType(local) {
+		fraction = defaultFractionDigits
+		if n, err := facetInt(restriction.FractionDigits); err == nil {
+			fraction = n
+		}
+		step.SetFrac(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(fraction)), nil))
+	}
+	lower, upper := integerRanges[local][0], integerRanges[local][1]
+	low, lowOK := bound(restriction.MinIncl, restriction.MinExcl, lower, step)
+	high, highOK := bound(restriction.MaxIncl, restriction.MaxExcl, upper, new(big.Rat).Neg(step))
+
+	var candidates []*big.Rat
+	if lowOK {
+		candidates = append(candidates, low, new(big.Rat).Add(low, step))
+	}
+	if highOK {
+		candidates = append(candidates, new(big.Rat).Sub(high, step), high)
+	}
+	if total, err := facetInt(restriction.TotalDigits); err == nil && total > fraction {
+		largest := nines(total-fraction, fraction)
+		candidates = append(candidates, largest, new(big.Rat).Neg(largest))
+	} else if restriction.FractionDigits != nil && fraction > 0 {
+		candidates = append(candidates, nines(1, fraction))
+	}
+
+	values := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		if c.IsInt() {
+			values = append(values, c.Num().String())
+		} else {
+			values = append(values, c.FloatString(fraction))
+		}
+	}
+	return values
+}
+
+// nines returns the number made of integer nines, a dot and fraction nines.
+func nines(integer, fraction int) *big.Rat {
+	digits := strings.Repeat("9", integer)
+	if fraction > 0 {
+		digits += "." + strings.Repeat("9", fraction)
+	}
+	v, _ := new(big.Rat).SetString(digits)
+	return v
+}
+
+// bound returns the inclusive bound given by an inclusive or exclusive facet, or by the range of the
+// built-in type. The step moves an exclusive bound inside the value space.
+func bound(inclusive, exclusive *model.XSDValue, builtin string, step *big.Rat) (*big.Rat, bool) {
+	switch {
+	case inclusive != nil:
+		return new(big.Rat).SetString(inclusive.Value)
+	case exclusive != nil:
+		v, ok := new(big.Rat).SetString(exclusive.Value)
+		if !ok {
+			return nil, false
+		}
+		return v.Add(v, step), true
+	case builtin != "":
+		return new(big.Rat).SetString(builtin)
+	}
+	return nil, false
+}
+
+// FacetsOf converts a restriction into the facets checked by package validation.
+func FacetsOf(restriction *model.XSDRestriction) *validation.Facets {
+	value := func(v *model.XSDValue) string {
+		if v == nil {
+			return ""
+		}
+		return v.Value
+	}
+	f := &validation.Facets{
+		MinInclusive:   value(restriction.MinIncl),
+		MaxInclusive:   value(restriction.MaxIncl),
+		MinExclusive:   value(restriction.MinExcl),
+		MaxExclusive:   value(restriction.MaxExcl),
+		Length:         value(restriction.Length),
+		MinLength:      value(restriction.MinLength),
+		MaxLength:      value(restriction.MaxLength),
+		TotalDigits:    value(restriction.TotalDigits),
+		FractionDigits: value(restriction.FractionDigits),
+	}
+	if restriction.Pattern != nil {
+		f.Pattern = restriction.Pattern.Value
+	}
+	for _, e := range restriction.Enumerations {
+		f.Enumerations = append(f.Enumerations, e.Value)
+	}
+	return f
+}
+
+func facetInt(v *model.XSDValue) (int, error) {
+	if v == nil {
+		return 0, strconv.ErrSyntax
+	}
+	return strconv.Atoi(v.Value)
+}
+
+func isStringType(local string) bool {
+	switch local {
+	case "string", "normalizedString", "token", "anyURI":
+		return true
+	}
+	return false
+}
+
+func isIntegerType(local string) bool {
+	_, bounded := integerRanges[local]
+	return bounded || local == "integer"
+}
+
+func isDecimalType(local string) bool {
+	return local == "decimal" || local == "float" || local == "double"
+}
@@ -0,0 +1,91 @@
+package helpers_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+)
+
+func TestBoundaryValues(t *testing.T) {
+	v := func(s string) *model.XSDValue { return &model.XSDValue{Value: s} }
+	cases := []struct {
+		name        string
+		xsdType     string
+		restriction *model.XSDRestriction
+		want        []string
+	}{
+		{"inclusive bounds", "xs:int", &model.XSDRestriction{MinIncl: v("1"), MaxIncl: v("10")}, []string{"1", "2", "9", "10"}},
+		{"exclusive bounds", "xs:integer", &model.XSDRestriction{MinExcl: v("0"), MaxExcl: v("5")}, []string{"1", "2", "3", "4"}},
+		{"built-in range", "xs:byte", nil, []string{"-128", "-127", "126", "127"}},
+		{"unbounded above", "xs:positiveInteger", nil, []string{"1", "2"}},
+		{"fraction digits", "xs:decimal", &model.XSDRestriction{MinIncl: v("0"), MaxIncl: v("1"), FractionDigits: v("1")},
+			[]string{"0", "0.1", "0.9", "1"}},
+		{"total digits", "xs:decimal", &model.XSDRestriction{TotalDigits: v("4"), FractionDigits: v("2")},
+			[]string{"99.99", "-99.99"}},
+		{"enumeration", "xs:int", &model.XSDRestriction{Enumerations: []model.XSDValue{{Value: "1"}}}, nil},
+		{"plain string", "xs:string", nil, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := helpers.NewRand(1).BoundaryValues(c.xsdType, c.restriction)
+			if !slices.Equal(got, c.want) {
+				t.Errorf("BoundaryValues(%s) = %q, want %q", c.xsdType, got, c.want)
+			}
+		})
+	}
+}
+
+func TestBoundaryValuesOfStrings(t *testing.T) {
+	r := helpers.NewRand(1)
+	values := r.BoundaryValues("xs:string", &model.XSDRestriction{
+		MinLength: &model.XSDValue{Value: "2"},
+		MaxLength: &model.XSDValue{Value: "8"},
+	})
+	var lengths []int
+	for _, s := range values {
+		lengths = append(lengths, len(s))
+	}
+	if !slices.Equal(lengths, []int{2, 8}) {
+		t.Errorf("lengths of %q = %v, want [2 8]", values, lengths)
+	}
+
+	values = r.BoundaryValues("xs:string", &model.XSDRestriction{
+		Length:  &model.XSDValue{Value: "3"},
+		Pattern: &model.XSDPattern{Value: `\d+`},
+	})
+	if len(values) != 0 {
+		t.Errorf("values breaking the pattern should be left out, got %q", values)
+	}
+}
+
+func TestBoundaryValuesOfCalendarTypes(t *testing.T) {
+	r := helpers.NewRand(1)
+	dates := r.BoundaryValues("xs:date", nil)
+	for _, want := range []string{"2024-02-29", "2000-02-29", "2024-02-29+14:00", "2024-02-29-14:00"} {
+		if !slices.Contains(dates, want) {
+			t.Errorf("date boundaries %q lack %s", dates, want)
+		}
+	}
+	bounded := r.BoundaryValues("xs:date", &model.XSDRestriction{MinIncl: &model.XSDValue{Value: "2020-01-01"}})
+	if !slices.Contains(bounded, "2020-01-01") || slices.Contains(bounded, "2000-02-29") {
+		t.Errorf("date boundaries %q should include the bound and respect it", bounded)
+	}
+}
+
+func TestBoundaryValueGeneratorFallsBack(t *testing.T) {
+	gen := helpers.BoundaryValueGenerator{Source: helpers.NewRand(1)}
+	for range 20 {
+		value, _ := gen.Generate(&helpers.ValueContext{Base: "xs:int", Restriction: &model.XSDRestriction{
+			MinIncl: &model.XSDValue{Value: "1"},
+			MaxIncl: &model.XSDValue{Value: "10"},
+		}})
+		if !slices.Contains([]string{"1", "2", "9", "10"}, value) {
+			t.Fatalf("Generate returned %s, not a boundary value", value)
+		}
+	}
+	if value, ok := gen.Generate(&helpers.ValueContext{Base: "xs:string"}); !ok || value == "" {
+		t.Error("types without boundary values should get a random value")
+	}
+}
@@ -1,11 +1,25 @@
 package helpers
 
-import "github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+// DefaultValueGenerator is the production implementation. Source, when set, makes the generated values
+// reproducible; the zero value draws from the Rand of the context, or else from the default source.
+type DefaultValueGenerator struct {
+	Source *Rand
+}
+
+// Generate returns a random value of the base type of the node, within its restriction. It never declines.
+func (d DefaultValueGenerator) Generate(vc *ValueContext) (string, bool) {
+	return d.rand(vc).GenerateValue(vc.Base, vc.Restriction), true
+}
 
-// DefaultValueGenerator is the production implementation.
-type DefaultValueGenerator struct{}
+// Rand implements Randomized.
+func (d DefaultValueGenerator) Rand() *Rand {
+	return d.Source
+}
 
-// Generate returns a random value based on type and restriction.
-func (d DefaultValueGenerator) Generate(xsdType string, restriction *model.XSDRestriction) string {
-	return GenerateValue(xsdType, restriction)
+// rand returns Source, or else the Rand of vc.
+func (d DefaultValueGenerator) rand(vc *ValueContext) *Rand {
+	if d.Source != nil {
+		return d.Source
+	}
+	return vc.Rand
 }
@@ -1,24 +1,27 @@
 package helpers
 
 import (
-	"crypto/rand"
 	"fmt"
-	"math/big"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/xsdtypes"
 )
 
 // Constants for default values and magic numbers.
 const (
-	defaultMinInt       = 1
-	defaultMaxInt       = 100
 	defaultStringLength = 5
+	defaultStringSpan   = 10 // number of lengths drawn from when maxLength is not set
+	patternAttempts     = 20 // values drawn from a pattern until one fits the length facets
 )
 
 // NormalizeType returns a Go-friendly version of the type string.
+//
+// Deprecated: the Go generator maps types with codegen.TypeMapping, which can be configured
+// per schema type; NormalizeType only knows a handful of built-ins and is no longer used.
 func NormalizeType(xsdType string) string {
 	switch strings.ToLower(xsdType) {
 	case "string":
@@ -42,200 +45,225 @@ func EscapeQuotes(s string) string {
 }
 
 // GenerateValue returns a sample value for a given XSD type and restriction.
-// It handles enumerations, patterns, and type-specific value generation.
-func GenerateValue(xsdType string, restriction *model.XSDRestriction) string {
+// It handles enumerations, patterns, and type-specific value generation, within the range, length and
+// digits facets of the restriction.
+func (r *Rand) GenerateValue(xsdType string, restriction *model.XSDRestriction) string {
 	// Handle enumerations if present
 	if restriction != nil && len(restriction.Enumerations) > 0 {
-		return pickRandomEnumeration(restriction)
-	}
-
-	// Dispatch based on the XSD type
-	switch xsdType {
-	case "xsd:string":
-		return generateStringValue(restriction)
-	case "xsd:decimal", "xsd:double", "xsd:float":
-		return generateFloatValue()
-	case "xsd:positiveInteger", "xsd:nonNegativeInteger":
-		return generatePositiveIntegerValue(restriction)
-	case "xsd:integer", "xsd:int", "xsd:long", "xsd:short", "xsd:byte":
-		return generateIntegerValue()
-	case "xsd:NMTOKEN":
-		return RandomIdentifier()
-	case "xsd:date":
-		return RandomDate()
-	case "xsd:time":
-		return RandomTime()
-	case "xsd:dateTime":
-		return randomDateTime()
-	case "xsd:duration":
-		return randomDuration()
-	case "xsd:boolean":
-		return randomBoolean()
-	default:
-		return generateDefaultValue(restriction)
+		return r.pickRandomEnumeration(restriction)
 	}
-}
 
-func secureIntn(n int) int {
-	if n <= 0 {
-		return 0
+	// Dispatch based on the local name of the XSD type, whatever prefix the schema binds to XML Schema
+	local := localTypeName(xsdType)
+	if isIntegerType(local) || isDecimalType(local) {
+		return r.numberValue(local, restriction)
 	}
-	bn := big.NewInt(int64(n))
-	r, err := rand.Int(rand.Reader, bn)
-	if err != nil {
-		return 0
-	}
-	return int(r.Int64())
-}
-
-func secureIntBetween(minVal, maxVal int) int {
-	if minVal >= maxVal {
-		return minVal
+	switch local {
+	case "string", "normalizedString", "token", "anyURI":
+		return r.generateStringValue(restriction)
+	case "NMTOKEN":
+		return r.RandomIdentifier()
+	case "date":
+		if v, ok := r.calendarInRange(local, restriction); ok {
+			return v
+		}
+		return r.RandomDate()
+	case "time":
+		return r.RandomTime()
+	case "dateTime":
+		if v, ok := r.calendarInRange(local, restriction); ok {
+			return v
+		}
+		return r.randomDateTime()
+	case "duration":
+		return r.randomDuration()
+	case "gYear":
+		return r.randomGYear()
+	case "gYearMonth":
+		return r.randomGYearMonth()
+	case "hexBinary":
+		return xsdtypes.HexBinary(r.randomBytes()).String()
+	case "base64Binary":
+		return xsdtypes.Base64Binary(r.randomBytes()).String()
+	case "QName":
+		return xsdtypes.QName{Local: r.RandomIdentifier()}.String()
+	case "boolean":
+		return r.randomBoolean()
+	default:
+		return r.generateDefaultValue(restriction)
 	}
-	return secureIntn(maxVal-minVal+1) + minVal
 }
 
-func secureFloatBetween(minVal, maxVal float64) float64 {
-	r, err := rand.Int(rand.Reader, big.NewInt(1e9))
-	if err != nil {
-		return minVal
+// localTypeName strips the namespace prefix of a type QName.
+func localTypeName(xsdType string) string {
+	if i := strings.LastIndex(xsdType, ":"); i >= 0 {
+		return xsdType[i+1:]
 	}
-	f := float64(r.Int64()) / 1e9
-	return minVal + f*(maxVal-minVal)
+	return xsdType
 }
 
 // pickRandomEnumeration selects a random value from enumeration restrictions.
-func pickRandomEnumeration(restriction *model.XSDRestriction) string {
-	return restriction.Enumerations[secureIntn(len(restriction.Enumerations))].Value
+func (r *Rand) pickRandomEnumeration(restriction *model.XSDRestriction) string {
+	return restriction.Enumerations[r.Intn(len(restriction.Enumerations))].Value
 }
 
-// generateStringValue generates a string value, using pattern if present.
-func generateStringValue(restriction *model.XSDRestriction) string {
+// generateStringValue generates a string value, using pattern if present, of a length allowed by the
+// length facets.
+func (r *Rand) generateStringValue(restriction *model.XSDRestriction) string {
 	if restriction != nil && restriction.Pattern != nil {
-		return generateStringFromPattern(restriction.Pattern.Value)
+		return r.patternValue(restriction)
 	}
-	return RandomString(secureIntn(10) + defaultStringLength)
-}
-
-// generateFloatValue generates a random floating point number as a string.
-func generateFloatValue() string {
-	return strconv.FormatFloat(RandomFloat(-1e6, 1e6), 'f', 6, 64)
-}
-
-// generatePositiveIntegerValue generates a positive integer value considering restrictions.
-func generatePositiveIntegerValue(restriction *model.XSDRestriction) string {
-	return strconv.Itoa(randomIntFromRestriction(restriction, defaultMinInt, defaultMaxInt))
+	length, _ := r.stringLength(restriction)
+	return r.RandomString(length)
 }
 
-// generateIntegerValue generates an integer value in a fixed range.
-func generateIntegerValue() string {
-	return strconv.Itoa(RandomInt(-10000, 10000))
+// stringLength returns a random length allowed by the length facets of restriction, which may be nil,
+// and whether it sets any of them. Lengths stay within defaultStringSpan of minLength when maxLength is
+// not set.
+func (r *Rand) stringLength(restriction *model.XSDRestriction) (int, bool) {
+	low, high := defaultStringLength, defaultStringLength+defaultStringSpan-1
+	if restriction == nil {
+		return r.RandomBetween(low, high), false
+	}
+	if n, err := facetInt(restriction.Length); err == nil {
+		return n, true
+	}
+	minLength, minErr := facetInt(restriction.MinLength)
+	if minErr == nil {
+		low, high = minLength, max(high, minLength+defaultStringSpan-1)
+	}
+	maxLength, maxErr := facetInt(restriction.MaxLength)
+	if maxErr == nil {
+		high, low = maxLength, min(low, maxLength)
+	}
+	return r.RandomBetween(low, high), minErr == nil || maxErr == nil
 }
 
 // randomBoolean randomly returns "true" or "false".
-func randomBoolean() string {
-	n, err := rand.Int(rand.Reader, big.NewInt(2))
-	if err != nil {
-		// fallback or panic if crypto/rand fails, depending on your error policy
-		panic("crypto/rand failed: " + err.Error())
-	}
-	if n.Int64() == 0 {
+func (r *Rand) randomBoolean() string {
+	if r.Intn(2) == 0 {
 		return "false"
 	}
 	return "true"
 }
 
-// generateDefaultValue generates a default value when no specific type matched.
-func generateDefaultValue(restriction *model.XSDRestriction) string {
+// generateDefaultValue generates a default value when no specific type matched, honouring the pattern,
+// range and length facets of the restriction.
+func (r *Rand) generateDefaultValue(restriction *model.XSDRestriction) string {
 	if restriction != nil {
 		if restriction.Pattern != nil {
-			return generateStringFromPattern(restriction.Pattern.Value)
+			return r.patternValue(restriction)
 		}
-		if restriction.MinIncl != nil && restriction.MaxExcl != nil {
-			minVal, _ := strconv.Atoi(restriction.MinIncl.Value)
-			maxVal, _ := strconv.Atoi(restriction.MaxExcl.Value)
-			return strconv.Itoa(RandomInt(minVal, maxVal-defaultMinInt))
+		if restriction.MinIncl != nil || restriction.MaxIncl != nil || restriction.MinExcl != nil ||
+			restriction.MaxExcl != nil {
+			return r.numberValue("integer", restriction)
+		}
+		if length, ok := r.stringLength(restriction); ok {
+			return r.RandomString(length)
 		}
 	}
 	return "default"
 }
 
-// randomIntFromRestriction returns a random integer within the given restriction bounds.
-// If no restriction is provided, it uses the provided default values.
-func randomIntFromRestriction(r *model.XSDRestriction, defMin, defMax int) int {
-	minVal, maxVal := defMin, defMax
-	if r != nil {
-		if r.MinIncl != nil {
-			if v, err := strconv.Atoi(r.MinIncl.Value); err == nil {
-				minVal = v
-			}
-		}
-		if r.MaxExcl != nil {
-			if v, err := strconv.Atoi(r.MaxExcl.Value); err == nil {
-				maxVal = v
-			}
-		}
+// patternValue generates a string matching the pattern of restriction, drawing it again, up to
+// patternAttempts times, while it breaks the length facets.
+func (r *Rand) patternValue(restriction *model.XSDRestriction) string {
+	value := r.generateStringFromPattern(restriction.Pattern.Value)
+	if restriction.Length == nil && restriction.MinLength == nil && restriction.MaxLength == nil {
+		return value
 	}
-	return RandomInt(minVal, maxVal)
+	facets := FacetsOf(restriction)
+	for i := 1; i < patternAttempts && !Satisfies(value, facets); i++ {
+		value = r.generateStringFromPattern(restriction.Pattern.Value)
+	}
+	return value
 }
 
-// generateStringFromPattern generates a string that matches the given regex pattern.
-// If pattern generation fails, it returns a default value.
-func generateStringFromPattern(pattern string) string {
+// generateStringFromPattern generates a string that matches the given XSD pattern, whose escapes keep
+// their XSD meaning. If pattern generation fails, it returns a default value.
+func (r *Rand) generateStringFromPattern(pattern string) string {
 	pattern = strings.ReplaceAll(pattern, `\\`, `\`)
-	xeger, err := NewXeger(pattern)
+	translated, err := validation.GeneratorPattern(pattern)
+	if err != nil {
+		return "match123"
+	}
+	xeger, err := NewXeger(translated)
 	if err != nil {
 		return "match123"
 	}
+	xeger.rand = r
 	return xeger.Generate()
 }
 
 // RandomString generates a random string of the specified length.
-func RandomString(length int) string {
+func (r *Rand) RandomString(length int) string {
 	const chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	var sb strings.Builder
 	for i := 0; i < length; i++ {
-		sb.WriteByte(chars[secureIntn(len(chars))])
+		sb.WriteByte(chars[r.Intn(len(chars))])
 	}
 	return sb.String()
 }
 
 // RandomIdentifier generates a random identifier string.
 // The first character is always a letter, followed by letters, numbers, or certain symbols.
-func RandomIdentifier() string {
+func (r *Rand) RandomIdentifier() string {
 	const start = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
 	const rest = start + "0123456789_-."
 	var sb strings.Builder
-	sb.WriteByte(start[secureIntn(len(start))])
-	for i := 0; i < secureIntn(8)+2; i++ {
-		sb.WriteByte(rest[secureIntn(len(rest))])
+	sb.WriteByte(start[r.Intn(len(start))])
+	for i := 0; i < r.Intn(8)+2; i++ {
+		sb.WriteByte(rest[r.Intn(len(rest))])
 	}
 	return sb.String()
 }
 
 // RandomFloat generates a random float64 between min and max.
-func RandomFloat(minVal, maxVal float64) float64 {
-	return secureFloatBetween(minVal, maxVal)
+func (r *Rand) RandomFloat(minVal, maxVal float64) float64 {
+	return minVal + r.Float64()*(maxVal-minVal)
 }
 
 // RandomInt generates a random integer between min and max, inclusive.
-func RandomInt(minVal, maxVal int) int {
-	return secureIntBetween(minVal, maxVal)
+func (r *Rand) RandomInt(minVal, maxVal int) int {
+	return r.RandomBetween(minVal, maxVal)
 }
 
 // RandomDate generates a random date string in YYYY-MM-DD format.
-func RandomDate() string {
+func (r *Rand) RandomDate() string {
+	return xsdtypes.Date{Time: r.randomInstant()}.String()
+}
+
+// randomInstant returns a random second between 1980-01-01 and 2030-12-31 in UTC.
+func (r *Rand) randomInstant() time.Time {
 	start := time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
 	end := time.Date(2030, 12, 31, 0, 0, 0, 0, time.UTC)
 	delta := end.Sub(start)
-	seconds := secureIntn(int(delta.Seconds()))
-	randomTime := start.Add(time.Duration(seconds) * time.Second)
-	return randomTime.Format("2006-01-02")
+	seconds := r.Intn(int(delta.Seconds()))
+	return start.Add(time.Duration(seconds) * time.Second)
+}
+
+// randomGYear generates a random gYear string in YYYY format.
+func (r *Rand) randomGYear() string {
+	return xsdtypes.GYear{Time: r.randomInstant()}.String()
+}
+
+// randomGYearMonth generates a random gYearMonth string in YYYY-MM format.
+func (r *Rand) randomGYearMonth() string {
+	return xsdtypes.GYearMonth{Time: r.randomInstant()}.String()
+}
+
+// randomBytes returns between 1 and 16 random bytes for the binary types.
+func (r *Rand) randomBytes() []byte {
+	b := make([]byte, r.Intn(16)+1)
+	for i := range b {
+		b[i] = byte(r.Intn(256))
+	}
+	return b
 }
 
 // RandomTime generates a random time string in HH:MM:SS format.
-func RandomTime() string {
-	return fmtTime(secureIntn(24), secureIntn(60), secureIntn(60))
+func (r *Rand) RandomTime() string {
+	return fmtTime(r.Intn(24), r.Intn(60), r.Intn(60))
 }
 
 // fmtTime formats hours, minutes, and seconds into a time string.
@@ -244,27 +272,27 @@ func fmtTime(h, m, s int) string {
 }
 
 // randomDateTime generates a random dateTime string in ISO 8601 format.
-func randomDateTime() string {
-	return RandomDate() + "T" + RandomTime()
+func (r *Rand) randomDateTime() string {
+	return xsdtypes.DateTime{Time: r.randomInstant()}.String()
 }
 
 // randomDuration generates a random duration string in ISO 8601 format.
-func randomDuration() string {
-	return fmt.Sprintf("P%dY%dM%dDT%dH%dM%dS",
-		secureIntn(10), secureIntn(12), secureIntn(28),
-		secureIntn(24), secureIntn(60), secureIntn(60))
+func (r *Rand) randomDuration() string {
+	return xsdtypes.Duration{
+		Years: r.Intn(10), Months: r.Intn(12), Days: r.Intn(28),
+		Hours: r.Intn(24), Minutes: r.Intn(60), Seconds: r.Intn(60),
+	}.String()
 }
 
 // ParseOccurs parses an XSD occurrence value into an integer.
 // If the value is "unbounded", it returns a random value between 1 and 3.
 // If parsing fails, it returns the default value.
-// ParseOccurs parses an XSD occurrence value.
-func ParseOccurs(val string, defaultVal int) int {
+func (r *Rand) ParseOccurs(val string, defaultVal int) int {
 	switch val {
 	case "":
 		return defaultVal
 	case "unbounded":
-		return secureIntn(3) + 1
+		return r.Intn(3) + 1
 	default:
 		i, err := strconv.Atoi(val)
 		if err != nil {
@@ -276,9 +304,56 @@ func ParseOccurs(val string, defaultVal int) int {
 
 // RandomBetween returns a random integer between min and max, inclusive.
 // If min >= max, it returns min.
-func RandomBetween(minVal, maxVal int) int {
+func (r *Rand) RandomBetween(minVal, maxVal int) int {
 	if minVal >= maxVal {
 		return minVal
 	}
-	return secureIntn(maxVal-minVal+1) + minVal
+	return r.Intn(maxVal-minVal+1) + minVal
+}
+
+// The package-level functions below draw from the default source; see Rand.
+
+// GenerateValue returns a sample value for a given XSD type and restriction.
+func GenerateValue(xsdType string, restriction *model.XSDRestriction) string {
+	return defaultRand.GenerateValue(xsdType, restriction)
+}
+
+// RandomString generates a random string of the specified length.
+func RandomString(length int) string {
+	return defaultRand.RandomString(length)
+}
+
+// RandomIdentifier generates a random identifier string.
+func RandomIdentifier() string {
+	return defaultRand.RandomIdentifier()
+}
+
+// RandomFloat generates a random float64 between min and max.
+func RandomFloat(minVal, maxVal float64) float64 {
+	return defaultRand.RandomFloat(minVal, maxVal)
+}
+
+// RandomInt generates a random integer between min and max, inclusive.
+func RandomInt(minVal, maxVal int) int {
+	return defaultRand.RandomInt(minVal, maxVal)
+}
+
+// RandomDate generates a random date string in YYYY-MM-DD format.
+func RandomDate() string {
+	return defaultRand.RandomDate()
+}
+
+// RandomTime generates a random time string in HH:MM:SS format.
+func RandomTime() string {
+	return defaultRand.RandomTime()
+}
+
+// ParseOccurs parses an XSD occurrence value; "unbounded" gives a random value between 1 and 3.
+func ParseOccurs(val string, defaultVal int) int {
+	return defaultRand.ParseOccurs(val, defaultVal)
+}
+
+// RandomBetween returns a random integer between min and max, inclusive.
+func RandomBetween(minVal, maxVal int) int {
+	return defaultRand.RandomBetween(minVal, maxVal)
 }
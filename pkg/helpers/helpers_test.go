@@ -2,6 +2,7 @@ package helpers_test
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -94,6 +95,63 @@ func TestGenerateValue_Types(t *testing.T) {
 	}
 }
 
+func TestGenerateValueHonoursFacets(t *testing.T) {
+	v := func(s string) *model.XSDValue { return &model.XSDValue{Value: s} }
+	cases := []struct {
+		xsdType     string
+		restriction *model.XSDRestriction
+	}{
+		{"xs:int", &model.XSDRestriction{MinIncl: v("1"), MaxIncl: v("9")}},
+		{"xs:integer", &model.XSDRestriction{MinExcl: v("0"), MaxExcl: v("5")}},
+		{"xs:long", &model.XSDRestriction{MinIncl: v("100000000000")}},
+		{"xs:positiveInteger", &model.XSDRestriction{MaxIncl: v("3")}},
+		{"xs:int", &model.XSDRestriction{TotalDigits: v("2")}},
+		{"xs:decimal", &model.XSDRestriction{TotalDigits: v("4"), FractionDigits: v("2")}},
+		{"xs:decimal", &model.XSDRestriction{MinIncl: v("0.5"), MaxExcl: v("0.75")}},
+		{"xs:decimal", &model.XSDRestriction{MaxIncl: v("-20"), TotalDigits: v("3")}},
+		{"xs:double", &model.XSDRestriction{MinExcl: v("-1"), MaxExcl: v("1")}},
+		{"xs:string", &model.XSDRestriction{MinLength: v("20")}},
+		{"xs:string", &model.XSDRestriction{MaxLength: v("3")}},
+		{"xs:token", &model.XSDRestriction{Length: v("8")}},
+		{"xs:anyURI", &model.XSDRestriction{MinLength: v("1"), MaxLength: v("2")}},
+		{"xs:date", &model.XSDRestriction{MinExcl: v("2024-01-01"), MaxExcl: v("2024-01-03")}},
+		{"xs:dateTime", &model.XSDRestriction{MinIncl: v("2030-06-01T00:00:00Z")}},
+		{"xs:language", &model.XSDRestriction{MaxLength: v("2")}},
+		{"xs:custom", &model.XSDRestriction{MinIncl: v("10"), MaxExcl: v("12")}},
+		{"xs:string", &model.XSDRestriction{Pattern: &model.XSDPattern{Value: `\d{3}\w{3}\i\c*`}}},
+		{"xs:string", &model.XSDRestriction{Pattern: &model.XSDPattern{Value: "[a-z]+"}, MinLength: v("2"), MaxLength: v("4")}},
+	}
+	r := helpers.NewRand(1)
+	for _, c := range cases {
+		facets := helpers.FacetsOf(c.restriction)
+		for range 200 {
+			if val := r.GenerateValue(c.xsdType, c.restriction); !helpers.Satisfies(val, facets) {
+				t.Fatalf("GenerateValue(%s, %+v) = %q, which breaks the facets", c.xsdType, facets, val)
+			}
+		}
+	}
+}
+
+func TestGenerateValueStaysInBuiltinRanges(t *testing.T) {
+	r := helpers.NewRand(2)
+	for xsdType, bounds := range map[string][2]int{
+		"xs:byte": {-128, 127}, "xs:unsignedByte": {0, 255}, "xs:positiveInteger": {1, 10000},
+		"xs:negativeInteger": {-10000, -1}, "xs:int": {-10000, 10000},
+	} {
+		for range 200 {
+			val := r.GenerateValue(xsdType, nil)
+			n, err := strconv.Atoi(val)
+			if err != nil || n < bounds[0] || n > bounds[1] {
+				t.Fatalf("GenerateValue(%s) = %q, outside %d..%d", xsdType, val, bounds[0], bounds[1])
+			}
+		}
+	}
+	if val := r.GenerateValue("xs:date", &model.XSDRestriction{MinIncl: &model.XSDValue{Value: "2024-02-29"},
+		MaxIncl: &model.XSDValue{Value: "2024-02-29"}}); val != "2024-02-29" {
+		t.Errorf("date pinned by its bounds = %q", val)
+	}
+}
+
 func TestRandomString(t *testing.T) {
 	s := helpers.RandomBetween(5, 10)
 	val := helpers.RandomString(s)
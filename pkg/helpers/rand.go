@@ -0,0 +1,73 @@
+package helpers
+
+import "math/rand/v2"
+
+// Rand is the source of randomness of the generators. Two Rand values created with the same seed draw
+// the same sequence of values, so a document generated from a seed can be generated again.
+//
+// The zero value and a nil *Rand draw from the automatically seeded source of math/rand/v2, which is
+// what the package-level functions use. A Rand is not safe for concurrent use.
+type Rand struct {
+	rng *rand.Rand
+}
+
+// defaultRand is the nil Rand used by the package-level functions.
+var defaultRand *Rand
+
+// NewRand returns a Rand drawing from a PCG generator seeded with seed.
+func NewRand(seed uint64) *Rand {
+	//nolint:gosec // generated documents are test data, not secrets
+	return &Rand{rng: rand.New(rand.NewPCG(seed, seed))}
+}
+
+// NewSeed returns a random seed for NewRand, for callers that want to report the seed they used.
+func NewSeed() uint64 {
+	//nolint:gosec // seeds of test data, not secrets
+	return rand.Uint64()
+}
+
+// Intn returns a uniform random integer in [0, n), or 0 when n <= 0.
+func (r *Rand) Intn(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	if r == nil || r.rng == nil {
+		//nolint:gosec // generated documents are test data, not secrets
+		return rand.IntN(n)
+	}
+	return r.rng.IntN(n)
+}
+
+// Float64 returns a uniform random float in [0.0, 1.0).
+func (r *Rand) Float64() float64 {
+	if r == nil || r.rng == nil {
+		//nolint:gosec // generated documents are test data, not secrets
+		return rand.Float64()
+	}
+	return r.rng.Float64()
+}
+
+// Uint64 returns a uniform random 64-bit integer, such as a seed for another generator.
+func (r *Rand) Uint64() uint64 {
+	if r == nil || r.rng == nil {
+		//nolint:gosec // generated documents are test data, not secrets
+		return rand.Uint64()
+	}
+	return r.rng.Uint64()
+}
+
+// Randomized is implemented by value generators carrying their own Rand. The XML generator and the
+// generated RandomX functions draw occurrences and choice branches from it, so that a seeded generator
+// reproduces whole documents rather than just their values.
+type Randomized interface {
+	Rand() *Rand
+}
+
+// RandOf returns the Rand carried by g, or nil, which draws from the default source, when g does not
+// implement Randomized.
+func RandOf(g any) *Rand {
+	if rg, ok := g.(Randomized); ok {
+		return rg.Rand()
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package helpers_test
+
+import (
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+)
+
+func TestNewRandIsReproducible(t *testing.T) {
+	pattern := &model.XSDRestriction{Pattern: &model.XSDPattern{Value: `[a-z]+\d{2,5}`}}
+	draw := func(r *helpers.Rand) []string {
+		return []string{
+			r.GenerateValue("xs:string", pattern),
+			r.GenerateValue("xs:decimal", nil),
+			r.GenerateValue("xs:boolean", nil),
+			r.GenerateValue("xs:duration", nil),
+			r.RandomIdentifier(),
+		}
+	}
+	a, b := draw(helpers.NewRand(7)), draw(helpers.NewRand(7))
+	for i := range a {
+		if a[i] != b[i] {
+			t.Errorf("value %d differs with the same seed: %q and %q", i, a[i], b[i])
+		}
+	}
+}
+
+func TestNilRandUsesDefaultSource(t *testing.T) {
+	var r *helpers.Rand
+	if n := r.RandomBetween(3, 5); n < 3 || n > 5 {
+		t.Errorf("RandomBetween(3, 5) = %d", n)
+	}
+	if r.Intn(0) != 0 {
+		t.Error("Intn(0) must be 0")
+	}
+}
@@ -0,0 +1,276 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/xsdtypes"
+)
+
+// Ranges of the numbers whose facets set no bound: integers stay within defaultIntegerSpan of zero and
+// decimals, floats and doubles within defaultDecimalSpan, with defaultNumberFraction fraction digits.
+const (
+	defaultIntegerSpan    = 10_000
+	defaultDecimalSpan    = 1_000_000
+	defaultNumberFraction = 6
+)
+
+// defaultSpan is the width of the range of a value with a single bound: numbers stay within defaultSpan
+// of the bound, dates within defaultSpan days.
+const defaultSpan = 1000
+
+// dateLayouts are the lexical forms of the bounds of date and dateTime ranges.
+var dateLayouts = map[string]string{"date": "2006-01-02", "dateTime": time.RFC3339}
+
+// ValueInRange returns a random value of a numeric, date or dateTime type between minVal and maxVal,
+// inclusive. Either bound may be empty, in which case the value stays within defaultSpan of the other
+// one. Decimals keep as many fraction digits as the more precise bound, and at least two.
+func (r *Rand) ValueInRange(xsdType, minVal, maxVal string) (string, error) {
+	local := localTypeName(xsdType)
+	switch {
+	case isIntegerType(local):
+		low, high, err := ratRange(minVal, maxVal, big.NewRat(defaultSpan, 1))
+		if err != nil {
+			return "", err
+		}
+		lo, hi := ceil(low), floor(high)
+		if lo.Cmp(hi) > 0 {
+			return "", fmt.Errorf("no integer between %s and %s", minVal, maxVal)
+		}
+		n := new(big.Int).Sub(hi, lo)
+		return lo.Add(lo, r.bigIntn(n.Add(n, big.NewInt(1)))).String(), nil
+	case isDecimalType(local):
+		low, high, err := ratRange(minVal, maxVal, big.NewRat(defaultSpan, 1))
+		if err != nil {
+			return "", err
+		}
+		if low.Cmp(high) > 0 {
+			return "", fmt.Errorf("empty range %s..%s", minVal, maxVal)
+		}
+		digits := max(fractionDigits(minVal), fractionDigits(maxVal), defaultFractionDigits)
+		span := new(big.Rat).Sub(high, low)
+		v := span.Mul(span, new(big.Rat).SetFloat64(r.Float64()))
+		return v.Add(v, low).FloatString(digits), nil
+	case dateLayouts[local] != "":
+		return r.timeInRange(local, minVal, maxVal)
+	}
+	return "", fmt.Errorf("ranges are not supported for %s", xsdType)
+}
+
+// numberValue returns a random number of the built-in numeric type local within the range, totalDigits
+// and fractionDigits facets of restriction, which may be nil. A bound the facets leave open is set the
+// default span away from the other one, or from zero, and the value space of the type cuts the range.
+func (r *Rand) numberValue(local string, restriction *model.XSDRestriction) string {
+	if restriction == nil {
+		restriction = &model.XSDRestriction{}
+	}
+	span, fraction := big.NewRat(defaultIntegerSpan, 1), 0
+	if isDecimalType(local) {
+		span, fraction = big.NewRat(defaultDecimalSpan, 1), numberFraction(restriction)
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(fraction)), nil)
+	step := new(big.Rat).SetFrac(big.NewInt(1), scale)
+	low, lowOK := bound(restriction.MinIncl, restriction.MinExcl, "", step)
+	high, highOK := bound(restriction.MaxIncl, restriction.MaxExcl, "", new(big.Rat).Neg(step))
+	if total, err := facetInt(restriction.TotalDigits); err == nil && total > fraction {
+		largest := nines(total-fraction, fraction)
+		if !lowOK || low.Cmp(new(big.Rat).Neg(largest)) < 0 {
+			low, lowOK = new(big.Rat).Neg(largest), true
+		}
+		if !highOK || high.Cmp(largest) > 0 {
+			high, highOK = largest, true
+		}
+	}
+	switch {
+	case !lowOK && !highOK:
+		low, high = new(big.Rat).Neg(span), span
+	case !lowOK:
+		low = new(big.Rat).Sub(high, span)
+	case !highOK:
+		high = new(big.Rat).Add(low, span)
+	}
+	if v, ok := bound(nil, nil, integerRanges[local][0], step); ok && v.Cmp(low) > 0 {
+		low = v
+	}
+	if v, ok := bound(nil, nil, integerRanges[local][1], step); ok && v.Cmp(high) < 0 {
+		high = v
+	}
+
+	// draw among the numbers of fraction digits in the range, or take its lower end when there are none
+	scaled := new(big.Rat).SetInt(scale)
+	lo, hi := ceil(new(big.Rat).Mul(low, scaled)), floor(new(big.Rat).Mul(high, scaled))
+	if lo.Cmp(hi) > 0 {
+		hi.Set(lo)
+	}
+	n := new(big.Int).Sub(hi, lo)
+	v := lo.Add(lo, r.bigIntn(n.Add(n, big.NewInt(1))))
+	if fraction == 0 {
+		return v.String()
+	}
+	return new(big.Rat).SetFrac(v, scale).FloatString(fraction)
+}
+
+// numberFraction returns the number of fraction digits of the decimals drawn for restriction: those of
+// its bounds, or defaultNumberFraction, within fractionDigits and leaving at least half of totalDigits
+// to the integer part when the bounds do not need them.
+func numberFraction(restriction *model.XSDRestriction) int {
+	fraction := defaultNumberFraction
+	if total, err := facetInt(restriction.TotalDigits); err == nil {
+		fraction = min(fraction, total/2)
+	}
+	for _, v := range []*model.XSDValue{restriction.MinIncl, restriction.MaxIncl, restriction.MinExcl, restriction.MaxExcl} {
+		if v != nil {
+			fraction = max(fraction, fractionDigits(v.Value))
+		}
+	}
+	if n, err := facetInt(restriction.FractionDigits); err == nil {
+		fraction = min(fraction, n)
+	}
+	return fraction
+}
+
+// calendarInRange returns a random date or dateTime within the range facets of restriction, and false
+// when it sets none or they cannot be parsed. Exclusive bounds move inward by a day for dates and by a
+// second for dateTimes.
+func (r *Rand) calendarInRange(local string, restriction *model.XSDRestriction) (string, bool) {
+	if restriction == nil {
+		return "", false
+	}
+	layout := dateLayouts[local]
+	inclusive := func(incl, excl *model.XSDValue, sign int) (string, bool) {
+		switch {
+		case incl != nil:
+			return incl.Value, true
+		case excl != nil:
+			t, err := time.Parse(layout, excl.Value)
+			if err != nil {
+				return "", false
+			}
+			if local == "date" {
+				return t.AddDate(0, 0, sign).Format(layout), true
+			}
+			return t.Add(time.Duration(sign) * time.Second).Format(layout), true
+		}
+		return "", true
+	}
+	minVal, minOK := inclusive(restriction.MinIncl, restriction.MinExcl, 1)
+	maxVal, maxOK := inclusive(restriction.MaxIncl, restriction.MaxExcl, -1)
+	if !minOK || !maxOK || minVal == "" && maxVal == "" {
+		return "", false
+	}
+	v, err := r.ValueInRange(local, minVal, maxVal)
+	return v, err == nil
+}
+
+// StringFromPattern returns a random string matching an XSD pattern.
+func (r *Rand) StringFromPattern(pattern string) string {
+	return r.generateStringFromPattern(pattern)
+}
+
+// timeInRange returns a random date or dateTime, to the second, between minVal and maxVal.
+func (r *Rand) timeInRange(local, minVal, maxVal string) (string, error) {
+	layout := dateLayouts[local]
+	span := defaultSpan * 24 * time.Hour
+	var low, high time.Time
+	var err error
+	if minVal != "" {
+		if low, err = time.Parse(layout, minVal); err != nil {
+			return "", fmt.Errorf("invalid %s bound %q: %w", local, minVal, err)
+		}
+	}
+	if maxVal != "" {
+		if high, err = time.Parse(layout, maxVal); err != nil {
+			return "", fmt.Errorf("invalid %s bound %q: %w", local, maxVal, err)
+		}
+	}
+	switch {
+	case minVal == "" && maxVal == "":
+		return "", fmt.Errorf("no bound given for %s", local)
+	case minVal == "":
+		low = high.Add(-span)
+	case maxVal == "":
+		high = low.Add(span)
+	}
+	if low.After(high) {
+		return "", fmt.Errorf("empty range %s..%s", minVal, maxVal)
+	}
+	if local == "date" {
+		days := int(high.Sub(low).Hours() / 24)
+		return xsdtypes.Date{Time: low.AddDate(0, 0, r.Intn(days+1))}.String(), nil
+	}
+	seconds := int64(high.Sub(low) / time.Second)
+	offset := new(big.Int).SetInt64(seconds + 1)
+	return low.Add(time.Duration(r.bigIntn(offset).Int64()) * time.Second).Format(time.RFC3339), nil
+}
+
+// ratRange parses the bounds of a numeric range, deriving a missing one from the other and span.
+func ratRange(minVal, maxVal string, span *big.Rat) (*big.Rat, *big.Rat, error) {
+	parse := func(s string) (*big.Rat, error) {
+		v, ok := new(big.Rat).SetString(s)
+		if !ok {
+			return nil, fmt.Errorf("invalid numeric bound %q", s)
+		}
+		return v, nil
+	}
+	var low, high *big.Rat
+	var err error
+	if minVal != "" {
+		if low, err = parse(minVal); err != nil {
+			return nil, nil, err
+		}
+	}
+	if maxVal != "" {
+		if high, err = parse(maxVal); err != nil {
+			return nil, nil, err
+		}
+	}
+	switch {
+	case low == nil && high == nil:
+		low, high = new(big.Rat).Neg(span), span
+	case low == nil:
+		low = new(big.Rat).Sub(high, span)
+	case high == nil:
+		high = new(big.Rat).Add(low, span)
+	}
+	return low, high, nil
+}
+
+// bigIntn returns a uniform random integer in [0, n), n > 0.
+func (r *Rand) bigIntn(n *big.Int) *big.Int {
+	if n.IsInt64() && n.Int64() <= math.MaxInt {
+		return big.NewInt(int64(r.Intn(int(n.Int64()))))
+	}
+	// Wide ranges: draw one random digit group at a time, which is uniform enough for test data.
+	v := new(big.Int)
+	for range (n.BitLen() + 29) / 30 {
+		v.Lsh(v, 30).Add(v, big.NewInt(int64(r.Intn(1<<30))))
+	}
+	return v.Mod(v, n)
+}
+
+func floor(v *big.Rat) *big.Int {
+	q := new(big.Int)
+	m := new(big.Int)
+	q.DivMod(v.Num(), v.Denom(), m)
+	return q
+}
+
+func ceil(v *big.Rat) *big.Int {
+	q := floor(v)
+	if !v.IsInt() {
+		q.Add(q, big.NewInt(1))
+	}
+	return q
+}
+
+// fractionDigits returns the number of digits after the decimal point of a numeric literal.
+func fractionDigits(s string) int {
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(strings.TrimRight(s[i+1:], "0"))
+	}
+	return 0
+}
@@ -0,0 +1,40 @@
+package helpers_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+)
+
+func TestValueInRange(t *testing.T) {
+	r := helpers.NewRand(1)
+	for range 50 {
+		v, err := r.ValueInRange("xs:int", "-3", "3")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, err := strconv.Atoi(v); err != nil || n < -3 || n > 3 {
+			t.Fatalf("int %s outside -3..3", v)
+		}
+		v, err = r.ValueInRange("xs:decimal", "1.5", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f, err := strconv.ParseFloat(v, 64); err != nil || f < 1.5 || f > 1001.5 {
+			t.Fatalf("decimal %s outside 1.5..1001.5", v)
+		}
+		v, err = r.ValueInRange("xs:date", "2024-02-28", "2024-03-01")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != "2024-02-28" && v != "2024-02-29" && v != "2024-03-01" {
+			t.Fatalf("date %s outside 2024-02-28..2024-03-01", v)
+		}
+	}
+	for _, c := range [][3]string{{"xs:string", "1", "2"}, {"xs:int", "5", "1"}, {"xs:int", "a", ""}, {"xs:date", "", ""}} {
+		if _, err := r.ValueInRange(c[0], c[1], c[2]); err == nil {
+			t.Errorf("ValueInRange(%q, %q, %q) should fail", c[0], c[1], c[2])
+		}
+	}
+}
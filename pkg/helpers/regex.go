@@ -1,10 +1,6 @@
 package helpers
 
-import (
-	"crypto/rand"
-	"math/big"
-	"regexp/syntax"
-)
+import "regexp/syntax"
 
 const (
 	limit          = 10
@@ -13,7 +9,8 @@ const (
 )
 
 type Xeger struct {
-	re *syntax.Regexp
+	re   *syntax.Regexp
+	rand *Rand // source of the random choices, nil for the default one
 }
 
 // NewXeger compiles a regex pattern into a syntax tree.
@@ -42,22 +39,22 @@ func (x *Xeger) gen(re *syntax.Regexp) string {
 		return x.genCharClass(re)
 	case syntax.OpAnyCharNotNL:
 		// Generates one random printable character except for newlines
-		return string(printableNotNL[randInt(len(printableNotNL))])
+		return string(printableNotNL[x.rand.Intn(len(printableNotNL))])
 	case syntax.OpAnyChar:
 		// Generates one random printable character (may include newline)
-		return string(printable[randInt(len(printable))])
+		return string(printable[x.rand.Intn(len(printable))])
 	case syntax.OpCapture, syntax.OpConcat:
 		// Handles grouping (parentheses) and concatenation by generating the sequence once
 		return x.genSub(re, 1)
 	case syntax.OpStar:
 		// Handles the '*' repetition: zero or more times (up to a random limit)
-		return x.genSub(re, randInt(limit+1))
+		return x.genSub(re, x.rand.Intn(limit+1))
 	case syntax.OpPlus:
 		// Handles the '+' repetition: one or more times (at least one, up to a random limit)
-		return x.genSub(re, randInt(limit)+1)
+		return x.genSub(re, x.rand.Intn(limit)+1)
 	case syntax.OpQuest:
 		// Handles the '?' repetition: zero or one time (randomly chosen)
-		return x.genSub(re, randInt(2))
+		return x.genSub(re, x.rand.Intn(2))
 	case syntax.OpRepeat:
 		// Handles explicit repetition: {min,max}
 		maxVal := re.Max
@@ -65,10 +62,10 @@ func (x *Xeger) gen(re *syntax.Regexp) string {
 			maxVal = limit
 		}
 		// Generate between Min and Max repetitions randomly
-		return x.genSub(re, randInt(maxVal-re.Min+1)+re.Min)
+		return x.genSub(re, x.rand.Intn(maxVal-re.Min+1)+re.Min)
 	case syntax.OpAlternate:
 		// Handles alternation (a|b): randomly pick one of the subexpressions
-		return x.gen(re.Sub[randInt(len(re.Sub))])
+		return x.gen(re.Sub[x.rand.Intn(len(re.Sub))])
 	}
 	// Fallback for unsupported/empty ops
 	return ""
@@ -82,7 +79,7 @@ func (x *Xeger) genCharClass(re *syntax.Regexp) string {
 	for i := 0; i < len(re.Rune); i += 2 {
 		total += int(re.Rune[i+1]-re.Rune[i]) + 1
 	}
-	idx := randInt(total) // Choose a random position in the total range
+	idx := x.rand.Intn(total) // Choose a random position in the total range
 	for i := 0; i < len(re.Rune); i += 2 {
 		delta := int(re.Rune[i+1] - re.Rune[i])
 		// If idx falls into this range, compute the rune
@@ -106,17 +103,3 @@ func (x *Xeger) genSub(re *syntax.Regexp, count int) string {
 	}
 	return string(out)
 }
-
-// randInt returns a cryptographically secure uniform random integer in [0, n).
-// Returns 0 if n <= 0 to avoid errors.
-func randInt(n int) int {
-	if n <= 0 {
-		return 0
-	}
-	val, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
-	if err != nil {
-		// handle error, here fallback to zero or panic if appropriate
-		return 0
-	}
-	return int(val.Int64())
-}
@@ -1,8 +1,174 @@
 package helpers
 
-import "github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+import (
+	"encoding/xml"
+	"slices"
 
-// ValueGenerator defines an interface for generating values from XSD types.
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+)
+
+// ValueGenerator generates the simple values of XML documents and typed fixtures: the text of elements
+// with simple content and the values of attributes.
 type ValueGenerator interface {
-	Generate(xsdType string, restriction *model.XSDRestriction) string
+	// Generate returns the value of the node described by vc, or false to leave it to another generator,
+	// such as the next one of a Chain. The XML generator falls back to random values then.
+	Generate(vc *ValueContext) (string, bool)
+}
+
+// ValueContext describes the element text or attribute value a ValueGenerator is asked for.
+type ValueContext struct {
+	// Path locates the node from the root element, such as "/order/line[2]/@status". Elements that may
+	// repeat carry their position among their siblings of the same name, from 1.
+	Path string
+	// Name is the qualified name of the element or attribute.
+	Name xml.Name
+	// Attribute reports whether the value is the one of an attribute rather than the text of an element.
+	Attribute bool
+	// Type is the QName of the simple type of the node as written in the schema, "" for anonymous types.
+	Type string
+	// Base is the built-in type the simple type derives from, such as "xs:int".
+	Base string
+	// Restriction holds the facets of the simple type, merged with the ones of the types it restricts.
+	// It is nil for built-in types.
+	Restriction *model.XSDRestriction
+	// Occurrence is the position of the element among its siblings of the same name, from 0. Attributes
+	// get the position of their element.
+	Occurrence int
+	// Parent holds the values generated so far inside the parent element of the node, by local name: the
+	// text of its simple child elements, and its attributes as "@name". The parent of an attribute is its
+	// element. Attributes are generated before the content of their element, so that documents stream,
+	// except for the elements with an attribute whose generator is a ParentReader: they are held until
+	// their end and get their attributes last, which then see the children. Later siblings of the same
+	// name replace earlier ones. The map keeps changing as the document is generated: generators must not
+	// modify it nor keep it.
+	Parent map[string]string
+	// Rand is the source of the document, from which generators without their own Rand should draw to
+	// keep seeded documents reproducible. It may be nil, which draws from the default source.
+	Rand *Rand
+}
+
+// Facets returns the facets of the restriction of the node, nil when it has none.
+func (vc *ValueContext) Facets() *validation.Facets {
+	if vc.Restriction == nil {
+		return nil
+	}
+	return FacetsOf(vc.Restriction)
+}
+
+// ParentReader is implemented by value generators reading the Parent of the attributes they generate.
+// The XML generator generates the attributes such generators produce after the content of their element,
+// which holds the element in memory until its end when the document is streamed.
+type ParentReader interface {
+	ReadsParent() bool
+}
+
+// ReadsParent reports whether g implements ParentReader and reads the Parent of attributes.
+func ReadsParent(g any) bool {
+	if pr, ok := g.(ParentReader); ok {
+		return pr.ReadsParent()
+	}
+	return false
+}
+
+// ReadingParent returns a generator generating the values of g, and reading the Parent of attributes:
+// it marks g, such as a ValueGeneratorFunc, as a ParentReader. It implements Randomized with the Rand of g.
+func ReadingParent(g ValueGenerator) ValueGenerator {
+	return parentReader{g}
+}
+
+type parentReader struct {
+	ValueGenerator
+}
+
+// ReadsParent implements ParentReader.
+func (parentReader) ReadsParent() bool {
+	return true
+}
+
+// Rand implements Randomized.
+func (p parentReader) Rand() *Rand {
+	return RandOf(p.ValueGenerator)
+}
+
+// ValueGeneratorFunc adapts a function to the ValueGenerator interface.
+type ValueGeneratorFunc func(vc *ValueContext) (string, bool)
+
+// Generate calls f.
+func (f ValueGeneratorFunc) Generate(vc *ValueContext) (string, bool) {
+	return f(vc)
+}
+
+// Chain returns a generator asking each of generators in turn, and keeping the first value produced.
+// It declines the nodes that every one of them declines. The chain implements Randomized with the Rand
+// of its first generator that carries one, and ParentReader when one of its generators reads Parent.
+func Chain(generators ...ValueGenerator) ValueGenerator {
+	return chain(generators)
+}
+
+type chain []ValueGenerator
+
+// Generate implements ValueGenerator.
+func (c chain) Generate(vc *ValueContext) (string, bool) {
+	for _, g := range c {
+		if value, ok := g.Generate(vc); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// Rand implements Randomized.
+func (c chain) Rand() *Rand {
+	for _, g := range c {
+		if r := RandOf(g); r != nil {
+			return r
+		}
+	}
+	return nil
+}
+
+// ReadsParent implements ParentReader.
+func (c chain) ReadsParent() bool {
+	return slices.ContainsFunc(c, func(g ValueGenerator) bool { return ReadsParent(g) })
+}
+
+// Fallback returns a generator keeping the values of g that satisfy the facets of their node, and asking
+// fallback for the nodes that g declines or gets wrong. Hand-written generators can thus focus on a few
+// nodes, or on plausible values, and leave the rest to DefaultValueGenerator. Fallback implements
+// Randomized with the Rand of g, or else of fallback, and ParentReader when one of them reads Parent.
+func Fallback(g, fallback ValueGenerator) ValueGenerator {
+	return fallbackGenerator{g: g, fallback: fallback}
+}
+
+type fallbackGenerator struct {
+	g, fallback ValueGenerator
+}
+
+// Generate implements ValueGenerator.
+func (f fallbackGenerator) Generate(vc *ValueContext) (string, bool) {
+	if value, ok := f.g.Generate(vc); ok && Satisfies(value, vc.Facets()) {
+		return value, true
+	}
+	return f.fallback.Generate(vc)
+}
+
+// Rand implements Randomized.
+func (f fallbackGenerator) Rand() *Rand {
+	if r := RandOf(f.g); r != nil {
+		return r
+	}
+	return RandOf(f.fallback)
+}
+
+// ReadsParent implements ParentReader.
+func (f fallbackGenerator) ReadsParent() bool {
+	return ReadsParent(f.g) || ReadsParent(f.fallback)
+}
+
+// Satisfies reports whether value satisfies facets, which may be nil.
+func Satisfies(value string, facets *validation.Facets) bool {
+	var errs validation.Errors
+	validation.CheckValue("", value, facets, false, &errs)
+	return len(errs) == 0
 }
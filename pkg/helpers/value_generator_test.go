@@ -0,0 +1,85 @@
+package helpers_test
+
+import (
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+)
+
+// constant generates value for the nodes named name and declines the others.
+func constant(name, value string) helpers.ValueGenerator {
+	return helpers.ValueGeneratorFunc(func(vc *helpers.ValueContext) (string, bool) {
+		return value, vc.Name.Local == name
+	})
+}
+
+func TestChainKeepsFirstValue(t *testing.T) {
+	g := helpers.Chain(constant("city", "Lyon"), constant("city", "Paris"), constant("country", "France"))
+	for name, want := range map[string]string{"city": "Lyon", "country": "France"} {
+		vc := &helpers.ValueContext{Base: "xs:string"}
+		vc.Name.Local = name
+		if value, ok := g.Generate(vc); !ok || value != want {
+			t.Errorf("Generate(%s) = %q, %v, want %q", name, value, ok, want)
+		}
+	}
+	vc := &helpers.ValueContext{Base: "xs:string"}
+	vc.Name.Local = "street"
+	if _, ok := g.Generate(vc); ok {
+		t.Error("a chain declines the nodes every generator declines")
+	}
+}
+
+func TestChainCarriesRand(t *testing.T) {
+	r := helpers.NewRand(1)
+	g := helpers.Chain(constant("city", "Lyon"), helpers.DefaultValueGenerator{Source: r})
+	if helpers.RandOf(g) != r {
+		t.Error("a chain implements Randomized with the Rand of its generators")
+	}
+}
+
+func TestReadingParentMarksGenerators(t *testing.T) {
+	r := helpers.NewRand(1)
+	reader := helpers.ReadingParent(helpers.DefaultValueGenerator{Source: r})
+	if !helpers.ReadsParent(reader) || helpers.RandOf(reader) != r {
+		t.Error("ReadingParent marks a generator as a ParentReader and keeps its Rand")
+	}
+	if helpers.ReadsParent(constant("city", "Lyon")) {
+		t.Error("generators read Parent only when they say so")
+	}
+	if !helpers.ReadsParent(helpers.Chain(constant("city", "Lyon"), reader)) ||
+		!helpers.ReadsParent(helpers.Fallback(constant("city", "Lyon"), reader)) {
+		t.Error("chains and fallbacks read Parent when one of their generators does")
+	}
+}
+
+func TestFallbackReplacesDeclinedAndInvalidValues(t *testing.T) {
+	g := helpers.Fallback(constant("code", "abc"), helpers.DefaultValueGenerator{Source: helpers.NewRand(1)})
+	code := &helpers.ValueContext{Base: "xs:string", Restriction: &model.XSDRestriction{
+		Pattern: &model.XSDPattern{Value: "[a-z]{3}"},
+	}}
+	code.Name.Local = "code"
+	if value, ok := g.Generate(code); !ok || value != "abc" {
+		t.Errorf("valid values are kept, got %q", value)
+	}
+
+	code.Restriction.Pattern.Value = "[A-Z]{3}-[0-9]"
+	for range 10 {
+		if value, ok := g.Generate(code); !ok || !helpers.Satisfies(value, code.Facets()) {
+			t.Fatalf("values breaking the facets are replaced, got %q", value)
+		}
+	}
+
+	other := &helpers.ValueContext{Base: "xs:int"}
+	other.Name.Local = "quantity"
+	if value, ok := g.Generate(other); !ok || value == "" {
+		t.Error("declined values come from the fallback")
+	}
+
+	other.Restriction = &model.XSDRestriction{MinIncl: &model.XSDValue{Value: "1"}, MaxIncl: &model.XSDValue{Value: "9"}}
+	for range 50 {
+		if value, ok := g.Generate(other); !ok || !helpers.Satisfies(value, other.Facets()) {
+			t.Fatalf("the fallback honours the bounds of the node, got %q", value)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package model
+
+import "strings"
+
+// XSDAnnotation is the xs:annotation of a schema component.
+type XSDAnnotation struct {
+	Documentation []XSDDocumentation `xml:"documentation"`
+}
+
+// XSDDocumentation is one xs:documentation entry of an annotation.
+type XSDDocumentation struct {
+	Lang string `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	Text string `xml:",chardata"`
+}
+
+// Text returns the documentation entries with their whitespace collapsed, separated by blank lines.
+// It is safe to call on a nil annotation.
+func (a *XSDAnnotation) Text() string {
+	if a == nil {
+		return ""
+	}
+	var paragraphs []string
+	for _, doc := range a.Documentation {
+		if text := strings.Join(strings.Fields(doc.Text), " "); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// Namespaces returns the namespace declarations of the schema element by prefix;
+// the default namespace is stored under the empty prefix.
+func (s *XSDSchema) Namespaces() map[string]string {
+	namespaces := map[string]string{}
+	for _, attr := range s.Attrs {
+		switch {
+		case attr.Name.Space == "xmlns":
+			namespaces[attr.Name.Local] = attr.Value
+		case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+			namespaces[""] = attr.Value
+		}
+	}
+	return namespaces
+}
@@ -0,0 +1,150 @@
+package model
+
+import "encoding/xml"
+
+// XSDParticle is one child of a sequence or choice; exactly one field is set.
+type XSDParticle struct {
+	Element  *XSDElement
+	Choice   *XSDChoice
+	Sequence *XSDSequence
+}
+
+type particleKind int
+
+const (
+	elementParticle particleKind = iota
+	choiceParticle
+	sequenceParticle
+)
+
+// particleRef points at a child of a model group by kind and index.
+type particleRef struct {
+	kind  particleKind
+	index int
+}
+
+// modelGroup gives sequences and choices a common view for decoding and ordering their children.
+type modelGroup struct {
+	minOccurs *string
+	maxOccurs *string
+	elements  *[]XSDElement
+	choices   *[]XSDChoice
+	sequences *[]XSDSequence
+	order     *[]particleRef
+}
+
+func (s *XSDSequence) group() modelGroup {
+	return modelGroup{&s.MinOccurs, &s.MaxOccurs, &s.Elements, &s.Choices, &s.Sequences, &s.order}
+}
+
+func (c *XSDChoice) group() modelGroup {
+	return modelGroup{&c.MinOccurs, &c.MaxOccurs, &c.Elements, &c.Choices, &c.Sequences, &c.order}
+}
+
+// Particles returns the children of the sequence in document order.
+func (s *XSDSequence) Particles() []XSDParticle { return s.group().particles() }
+
+// Particles returns the alternatives of the choice in document order.
+func (c *XSDChoice) Particles() []XSDParticle { return c.group().particles() }
+
+// UnmarshalXML decodes the sequence while recording the order of its children.
+func (s *XSDSequence) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return s.group().decode(d, start)
+}
+
+// UnmarshalXML decodes the choice while recording the order of its children.
+func (c *XSDChoice) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return c.group().decode(d, start)
+}
+
+// particles resolves the recorded order. Groups built by hand, without unmarshaling,
+// list elements first, then choices, then sequences.
+func (g modelGroup) particles() []XSDParticle {
+	order := *g.order
+	if len(order) != len(*g.elements)+len(*g.choices)+len(*g.sequences) {
+		order = g.defaultOrder()
+	}
+	particles := make([]XSDParticle, 0, len(order))
+	for _, ref := range order {
+		switch ref.kind {
+		case elementParticle:
+			particles = append(particles, XSDParticle{Element: &(*g.elements)[ref.index]})
+		case choiceParticle:
+			particles = append(particles, XSDParticle{Choice: &(*g.choices)[ref.index]})
+		case sequenceParticle:
+			particles = append(particles, XSDParticle{Sequence: &(*g.sequences)[ref.index]})
+		}
+	}
+	return particles
+}
+
+func (g modelGroup) defaultOrder() []particleRef {
+	var order []particleRef
+	for i := range *g.elements {
+		order = append(order, particleRef{elementParticle, i})
+	}
+	for i := range *g.choices {
+		order = append(order, particleRef{choiceParticle, i})
+	}
+	for i := range *g.sequences {
+		order = append(order, particleRef{sequenceParticle, i})
+	}
+	return order
+}
+
+// decode reads the occurrence attributes and the children of a model group.
+// Children other than elements, choices and sequences (annotations, wildcards) are skipped.
+func (g modelGroup) decode(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "minOccurs":
+			*g.minOccurs = attr.Value
+		case "maxOccurs":
+			*g.maxOccurs = attr.Value
+		}
+	}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if err := g.decodeChild(d, t); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// decodeChild decodes one child particle and records its position.
+func (g modelGroup) decodeChild(d *xml.Decoder, t xml.StartElement) error {
+	switch t.Name.Local {
+	case "element":
+		var el XSDElement
+		if err := d.DecodeElement(&el, &t); err != nil {
+			return err
+		}
+		*g.elements = append(*g.elements, el)
+		*g.order = append(*g.order, particleRef{elementParticle, len(*g.elements) - 1})
+	case "choice":
+		var ch XSDChoice
+		if err := d.DecodeElement(&ch, &t); err != nil {
+			return err
+		}
+		*g.choices = append(*g.choices, ch)
+		*g.order = append(*g.order, particleRef{choiceParticle, len(*g.choices) - 1})
+	case "sequence":
+		var seq XSDSequence
+		if err := d.DecodeElement(&seq, &t); err != nil {
+			return err
+		}
+		*g.sequences = append(*g.sequences, seq)
+		*g.order = append(*g.order, particleRef{sequenceParticle, len(*g.sequences) - 1})
+	default:
+		return d.Skip()
+	}
+	return nil
+}
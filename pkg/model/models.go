@@ -6,11 +6,13 @@ type XSDSchema struct {
 	XMLName         xml.Name         `xml:"schema"`
 	TargetNamespace string           `xml:"targetNamespace,attr"`
 	ElementForm     string           `xml:"elementFormDefault,attr"`
+	AttributeForm   string           `xml:"attributeFormDefault,attr"`
 	Includes        []XSDInclude     `xml:"include"`
 	Imports         []XSDImport      `xml:"import"`
 	Elements        []XSDElement     `xml:"element"`
 	ComplexTypes    []XSDComplexType `xml:"complexType"`
 	SimpleTypes     []XSDSimpleType  `xml:"simpleType"`
+	Attrs           []xml.Attr       `xml:",any,attr"` // namespace declarations and other attributes
 }
 
 type XSDInclude struct {
@@ -23,33 +25,51 @@ type XSDImport struct {
 }
 
 type XSDElement struct {
-	Name        string          `xml:"name,attr"`
-	Type        string          `xml:"type,attr,omitempty"`
-	Ref         string          `xml:"ref,attr,omitempty"`
-	MinOccurs   string          `xml:"minOccurs,attr,omitempty"`
-	MaxOccurs   string          `xml:"maxOccurs,attr,omitempty"`
-	ComplexType *XSDComplexType `xml:"complexType"`
-	SimpleType  *XSDSimpleType  `xml:"simpleType"`
+	Name              string          `xml:"name,attr"`
+	Type              string          `xml:"type,attr,omitempty"`
+	Ref               string          `xml:"ref,attr,omitempty"`
+	MinOccurs         string          `xml:"minOccurs,attr,omitempty"`
+	MaxOccurs         string          `xml:"maxOccurs,attr,omitempty"`
+	Fixed             string          `xml:"fixed,attr,omitempty"`
+	Default           string          `xml:"default,attr,omitempty"`
+	Form              string          `xml:"form,attr,omitempty"`
+	Abstract          bool            `xml:"abstract,attr,omitempty"`
+	SubstitutionGroup string          `xml:"substitutionGroup,attr,omitempty"` // QName of the head element
+	Annotation        *XSDAnnotation  `xml:"annotation"`
+	ComplexType       *XSDComplexType `xml:"complexType"`
+	SimpleType        *XSDSimpleType  `xml:"simpleType"`
+	Origin            *XSDOrigin      `xml:"-"` // schema document of a global element, set by the parser
 }
 
 type XSDComplexType struct {
-	Name     string         `xml:"name,attr,omitempty"`
-	Sequence *XSDSequence   `xml:"sequence"`
-	Choice   *XSDChoice     `xml:"choice"`
-	Attrs    []XSDAttribute `xml:"attribute"`
+	Name       string         `xml:"name,attr,omitempty"`
+	Annotation *XSDAnnotation `xml:"annotation"`
+	Sequence   *XSDSequence   `xml:"sequence"`
+	Choice     *XSDChoice     `xml:"choice"`
+	Attrs      []XSDAttribute `xml:"attribute"`
+	Origin     *XSDOrigin     `xml:"-"` // schema document of a named type, set by the parser
 }
 
 type XSDSimpleType struct {
 	Name        string          `xml:"name,attr,omitempty"`
+	Annotation  *XSDAnnotation  `xml:"annotation"`
 	Restriction *XSDRestriction `xml:"restriction"`
+	Origin      *XSDOrigin      `xml:"-"` // schema document of a named type, set by the parser
 }
 
 type XSDRestriction struct {
-	Base         string      `xml:"base,attr"`
-	MinIncl      *XSDValue   `xml:"minInclusive"`
-	MaxExcl      *XSDValue   `xml:"maxExclusive"`
-	Pattern      *XSDPattern `xml:"pattern"`
-	Enumerations []XSDValue  `xml:"enumeration"`
+	Base           string      `xml:"base,attr"`
+	MinIncl        *XSDValue   `xml:"minInclusive"`
+	MaxIncl        *XSDValue   `xml:"maxInclusive"`
+	MinExcl        *XSDValue   `xml:"minExclusive"`
+	MaxExcl        *XSDValue   `xml:"maxExclusive"`
+	Length         *XSDValue   `xml:"length"`
+	MinLength      *XSDValue   `xml:"minLength"`
+	MaxLength      *XSDValue   `xml:"maxLength"`
+	TotalDigits    *XSDValue   `xml:"totalDigits"`
+	FractionDigits *XSDValue   `xml:"fractionDigits"`
+	Pattern        *XSDPattern `xml:"pattern"`
+	Enumerations   []XSDValue  `xml:"enumeration"`
 }
 
 type XSDPattern struct {
@@ -57,20 +77,38 @@ type XSDPattern struct {
 }
 
 type XSDValue struct {
-	Value string `xml:"value,attr"`
+	Value      string         `xml:"value,attr"`
+	Annotation *XSDAnnotation `xml:"annotation"`
 }
 
+// XSDSequence is an ordered model group. Particles returns its children in document order.
 type XSDSequence struct {
-	Elements []XSDElement `xml:"element"`
+	MinOccurs string
+	MaxOccurs string
+	Elements  []XSDElement
+	Choices   []XSDChoice
+	Sequences []XSDSequence
+
+	order []particleRef // document order of the children, recorded while unmarshaling
 }
 
+// XSDChoice is a model group of alternatives. Particles returns its children in document order.
 type XSDChoice struct {
-	Elements []XSDElement `xml:"element"`
+	MinOccurs string
+	MaxOccurs string
+	Elements  []XSDElement
+	Choices   []XSDChoice
+	Sequences []XSDSequence
+
+	order []particleRef // document order of the children, recorded while unmarshaling
 }
 
 type XSDAttribute struct {
-	Name  string `xml:"name,attr"`
-	Type  string `xml:"type,attr"`
-	Use   string `xml:"use,attr,omitempty"`
-	Fixed string `xml:"fixed,attr,omitempty"`
+	Name       string         `xml:"name,attr"`
+	Type       string         `xml:"type,attr"`
+	Use        string         `xml:"use,attr,omitempty"`
+	Fixed      string         `xml:"fixed,attr,omitempty"`
+	Default    string         `xml:"default,attr,omitempty"`
+	Form       string         `xml:"form,attr,omitempty"`
+	Annotation *XSDAnnotation `xml:"annotation"`
 }
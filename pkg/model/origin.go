@@ -0,0 +1,56 @@
+package model
+
+// XSDOrigin describes the schema document declaring a top-level component. Included and imported
+// schemas are merged into a single XSDSchema, so each component keeps track of the target namespace
+// and the form defaults that apply to it.
+type XSDOrigin struct {
+	TargetNamespace string
+	ElementForm     string
+	AttributeForm   string
+}
+
+// Origin returns the XSDOrigin of the components declared directly in s.
+func (s *XSDSchema) Origin() *XSDOrigin {
+	return &XSDOrigin{TargetNamespace: s.TargetNamespace, ElementForm: s.ElementForm, AttributeForm: s.AttributeForm}
+}
+
+// SetOrigin records origin on every top-level component of s that does not have one yet.
+func (s *XSDSchema) SetOrigin(origin *XSDOrigin) {
+	for i := range s.Elements {
+		if s.Elements[i].Origin == nil {
+			s.Elements[i].Origin = origin
+		}
+	}
+	for i := range s.ComplexTypes {
+		if s.ComplexTypes[i].Origin == nil {
+			s.ComplexTypes[i].Origin = origin
+		}
+	}
+	for i := range s.SimpleTypes {
+		if s.SimpleTypes[i].Origin == nil {
+			s.SimpleTypes[i].Origin = origin
+		}
+	}
+}
+
+// ElementNamespace returns the namespace of a local element declared with form, which is empty
+// unless the element is qualified, explicitly or through elementFormDefault.
+func (o *XSDOrigin) ElementNamespace(form string) string {
+	return o.namespace(form, o.ElementForm)
+}
+
+// AttributeNamespace returns the namespace of a local attribute declared with form, which is empty
+// unless the attribute is qualified, explicitly or through attributeFormDefault.
+func (o *XSDOrigin) AttributeNamespace(form string) string {
+	return o.namespace(form, o.AttributeForm)
+}
+
+func (o *XSDOrigin) namespace(form, formDefault string) string {
+	if form == "" {
+		form = formDefault
+	}
+	if form != "qualified" {
+		return ""
+	}
+	return o.TargetNamespace
+}
@@ -26,6 +26,7 @@ func ParseXSD(filePath string, loadedSchemas map[string]*model.XSDSchema) (*mode
 		return nil, err
 	}
 	loadedSchemas[absPath] = schema
+	schema.SetOrigin(schema.Origin())
 
 	// Handle <xs:include> elements
 	if err := processIncludes(schema, loadedSchemas, filePath); err != nil {
@@ -81,6 +82,10 @@ func processIncludes(schema *model.XSDSchema, loadedSchemas map[string]*model.XS
 		if err != nil {
 			return err
 		}
+		// A chameleon include without targetNamespace takes the namespace of the including schema
+		if incSchema.TargetNamespace == "" {
+			adoptNamespace(incSchema, schema.TargetNamespace)
+		}
 		// Merge elements and types from included schema into current schema
 		schema.Elements = append(schema.Elements, incSchema.Elements...)
 		schema.ComplexTypes = append(schema.ComplexTypes, incSchema.ComplexTypes...)
@@ -105,3 +110,22 @@ func processImports(schema *model.XSDSchema, loadedSchemas map[string]*model.XSD
 	}
 	return nil
 }
+
+// adoptNamespace moves the components of a chameleon schema, one without targetNamespace, into namespace.
+func adoptNamespace(schema *model.XSDSchema, namespace string) {
+	var origins []*model.XSDOrigin
+	for i := range schema.Elements {
+		origins = append(origins, schema.Elements[i].Origin)
+	}
+	for i := range schema.ComplexTypes {
+		origins = append(origins, schema.ComplexTypes[i].Origin)
+	}
+	for i := range schema.SimpleTypes {
+		origins = append(origins, schema.SimpleTypes[i].Origin)
+	}
+	for _, origin := range origins {
+		if origin != nil && origin.TargetNamespace == "" {
+			origin.TargetNamespace = namespace
+		}
+	}
+}
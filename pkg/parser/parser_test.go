@@ -83,3 +83,56 @@ func TestParseXSD_AlreadyLoaded(t *testing.T) {
 		t.Error("Expected cached schema to be reused")
 	}
 }
+
+func TestParseXSD_NestedGroupsKeepDocumentOrder(t *testing.T) {
+	schema, err := ParseXSD(filepath.Join("testdata", "nested_groups.xsd"), nil)
+	if err != nil {
+		t.Fatalf("Failed to parse XSD: %v", err)
+	}
+	particles := schema.ComplexTypes[0].Sequence.Particles()
+	if len(particles) != 3 || particles[0].Element == nil || particles[1].Choice == nil || particles[2].Element == nil {
+		t.Fatalf("Expected element, choice, element particles, got %+v", particles)
+	}
+	choice := particles[1].Choice
+	if choice.MinOccurs != "0" || choice.MaxOccurs != "unbounded" {
+		t.Errorf("Expected choice occurrences 0..unbounded, got %s..%s", choice.MinOccurs, choice.MaxOccurs)
+	}
+	branches := choice.Particles()
+	if len(branches) != 2 || branches[1].Sequence == nil || len(branches[1].Sequence.Elements) != 2 {
+		t.Errorf("Expected an element and a two-element sequence branch, got %+v", branches)
+	}
+}
+
+func TestParseXSD_Annotations(t *testing.T) {
+	schema, err := ParseXSD(filepath.Join("testdata", "nested_groups.xsd"), nil)
+	if err != nil {
+		t.Fatalf("Failed to parse XSD: %v", err)
+	}
+	ct := schema.ComplexTypes[0]
+	if got := ct.Annotation.Text(); got != "How an order is paid.\n\nMode de paiement." {
+		t.Errorf("Unexpected documentation %q", got)
+	}
+	if lang := ct.Annotation.Documentation[1].Lang; lang != "fr" {
+		t.Errorf("Expected xml:lang fr, got %q", lang)
+	}
+	if ns := schema.Namespaces()["xs"]; ns != "http://www.w3.org/2001/XMLSchema" {
+		t.Errorf("Expected the xs prefix to be declared, got %q", ns)
+	}
+}
+
+func TestParseXSD_ComponentsKeepTheirNamespace(t *testing.T) {
+	schema, err := ParseXSD(filepath.Join("..", "codegen", "testdata", "multins", "orders.xsd"), nil)
+	if err != nil {
+		t.Fatalf("Failed to parse XSD: %v", err)
+	}
+	if ns := schema.ComplexTypes[0].Origin.TargetNamespace; ns != "http://example.com/orders" {
+		t.Errorf("Expected customer in the orders namespace, got %q", ns)
+	}
+	imported := schema.ComplexTypes[1].Origin
+	if imported.TargetNamespace != "http://example.com/common" || imported.ElementForm != "qualified" {
+		t.Errorf("Expected address in the qualified common namespace, got %+v", imported)
+	}
+	if ns := imported.AttributeNamespace(""); ns != "" {
+		t.Errorf("Expected unqualified attributes by default, got %q", ns)
+	}
+}
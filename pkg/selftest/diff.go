@@ -0,0 +1,155 @@
+package selftest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+// Divergence locates the first difference between two XML documents.
+type Divergence struct {
+	Path    string // XPath-like location, such as "/order/line[2]/@status"
+	Message string
+}
+
+// String implements fmt.Stringer.
+func (d *Divergence) String() string {
+	return d.Path + ": " + d.Message
+}
+
+// Compare walks want and got in document order and returns their first difference, or nil when they
+// hold the same XML. Elements and attributes are compared by namespace URI and local name, so prefixes
+// and namespace declarations do not matter, attributes are compared regardless of their order, and
+// values that only differ in surrounding whitespace or in the lexical form of a number or boolean
+// ("1.50" and "1.5", "1" and "true") are equal.
+func Compare(want, got *etree.Element) *Divergence {
+	if want.Tag != got.Tag || want.NamespaceURI() != got.NamespaceURI() {
+		return &Divergence{Path: "/" + want.Tag, Message: fmt.Sprintf("root element %s, got %s", name(want), name(got))}
+	}
+	return compareElements("/"+want.Tag, want, got)
+}
+
+func compareElements(path string, want, got *etree.Element) *Divergence {
+	if d := compareAttributes(path, want, got); d != nil {
+		return d
+	}
+	wantChildren, gotChildren := want.ChildElements(), got.ChildElements()
+	if len(wantChildren) == 0 && len(gotChildren) == 0 {
+		if !sameValue(want.Text(), got.Text()) {
+			return &Divergence{Path: path, Message: fmt.Sprintf("value %q, got %q", want.Text(), got.Text())}
+		}
+		return nil
+	}
+	counts := map[string]int{}
+	for _, c := range wantChildren {
+		counts[name(c)]++
+	}
+	seen := map[string]int{}
+	for i, w := range wantChildren {
+		seen[name(w)]++
+		childPath := path + "/" + w.Tag
+		if counts[name(w)] > 1 {
+			childPath += "[" + strconv.Itoa(seen[name(w)]) + "]"
+		}
+		if i >= len(gotChildren) {
+			return &Divergence{Path: childPath, Message: "missing element"}
+		}
+		g := gotChildren[i]
+		if name(w) != name(g) {
+			return &Divergence{Path: childPath, Message: "element " + name(w) + ", got " + name(g)}
+		}
+		if d := compareElements(childPath, w, g); d != nil {
+			return d
+		}
+	}
+	if len(gotChildren) > len(wantChildren) {
+		extra := gotChildren[len(wantChildren)]
+		return &Divergence{Path: path + "/" + extra.Tag, Message: "unexpected element " + name(extra)}
+	}
+	return nil
+}
+
+// compareAttributes compares the attributes of want and got, namespace declarations excluded.
+func compareAttributes(path string, want, got *etree.Element) *Divergence {
+	gotAttrs := attributes(got)
+	for _, a := range want.Attr {
+		if isNamespaceDeclaration(a) {
+			continue
+		}
+		key := attrName(&a)
+		value, ok := gotAttrs[key]
+		if !ok {
+			return &Divergence{Path: path + "/@" + a.Key, Message: "missing attribute"}
+		}
+		if !sameValue(a.Value, value) {
+			return &Divergence{Path: path + "/@" + a.Key, Message: fmt.Sprintf("value %q, got %q", a.Value, value)}
+		}
+		delete(gotAttrs, key)
+	}
+	for _, a := range got.Attr {
+		if _, ok := gotAttrs[attrName(&a)]; ok {
+			return &Divergence{Path: path + "/@" + a.Key, Message: "unexpected attribute " + attrName(&a)}
+		}
+	}
+	return nil
+}
+
+func attributes(e *etree.Element) map[string]string {
+	attrs := map[string]string{}
+	for _, a := range e.Attr {
+		if !isNamespaceDeclaration(a) {
+			attrs[attrName(&a)] = a.Value
+		}
+	}
+	return attrs
+}
+
+func isNamespaceDeclaration(a etree.Attr) bool {
+	return a.Space == "xmlns" || (a.Space == "" && a.Key == "xmlns")
+}
+
+// name returns the expanded name of an element, {namespace}local.
+func name(e *etree.Element) string {
+	if ns := e.NamespaceURI(); ns != "" {
+		return "{" + ns + "}" + e.Tag
+	}
+	return e.Tag
+}
+
+func attrName(a *etree.Attr) string {
+	if ns := a.NamespaceURI(); ns != "" {
+		return "{" + ns + "}" + a.Key
+	}
+	return a.Key
+}
+
+// sameValue reports whether two simple values are equal once whitespace is collapsed, comparing
+// numbers and booleans by value.
+func sameValue(want, got string) bool {
+	want, got = strings.Join(strings.Fields(want), " "), strings.Join(strings.Fields(got), " ")
+	if want == got {
+		return true
+	}
+	w, wantErr := strconv.ParseFloat(want, 64)
+	g, gotErr := strconv.ParseFloat(got, 64)
+	if wantErr == nil && gotErr == nil {
+		return w == g
+	}
+	if w, ok := parseBoolean(want); ok {
+		g, ok := parseBoolean(got)
+		return ok && w == g
+	}
+	return false
+}
+
+func parseBoolean(s string) (value, ok bool) {
+	switch s {
+	case "true", "1":
+		return true, true
+	case "false", "0":
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,44 @@
+package selftest
+
+import (
+	"testing"
+
+	"github.com/beevik/etree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, s string) *etree.Element {
+	t.Helper()
+	doc := etree.NewDocument()
+	require.NoError(t, doc.ReadFromString(s))
+	return doc.Root()
+}
+
+func TestCompareIgnoresPrefixesAndAttributeOrder(t *testing.T) {
+	want := parse(t, `<o:order xmlns:o="urn:o" a="1" b="x"><o:line>1.50</o:line><o:line> true </o:line></o:order>`)
+	got := parse(t, `<order xmlns="urn:o" b="x" a="1"><line>1.5</line><line>1</line></order>`)
+	assert.Nil(t, Compare(want, got))
+}
+
+func TestCompareReportsFirstDivergentPath(t *testing.T) {
+	want := `<order xmlns="urn:o" version="1"><line><code>A</code></line><line><code>B</code></line></order>`
+	cases := map[string]struct {
+		got  string
+		path string
+	}{
+		"value":              {`<order xmlns="urn:o" version="1"><line><code>A</code></line><line><code>C</code></line></order>`, "/order/line[2]/code"},
+		"missing attribute":  {`<order xmlns="urn:o"><line><code>A</code></line><line><code>B</code></line></order>`, "/order/@version"},
+		"extra attribute":    {`<order xmlns="urn:o" version="1"><line n="2"><code>A</code></line><line><code>B</code></line></order>`, "/order/line[1]/@n"},
+		"missing element":    {`<order xmlns="urn:o" version="1"><line><code>A</code></line></order>`, "/order/line[2]"},
+		"unexpected element": {`<order xmlns="urn:o" version="1"><line><code>A</code></line><line><code>B</code><note/></line></order>`, "/order/line[2]/note"},
+		"namespace":          {`<order xmlns="urn:o" version="1"><line><code xmlns="">A</code></line><line><code>B</code></line></order>`, "/order/line[1]/code"},
+		"root":               {`<order version="1"/>`, "/order"},
+	}
+	for name, c := range cases {
+		d := Compare(parse(t, want), parse(t, c.got))
+		if assert.NotNil(t, d, name) {
+			assert.Equal(t, c.path, d.Path, "%s: %s", name, d)
+		}
+	}
+}
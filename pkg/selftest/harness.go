@@ -0,0 +1,180 @@
+package selftest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const (
+	modulePath      = "github.com/Patrick-Ivann/xsd-codegen"
+	harnessModule   = "xsdselftest"
+	harnessPackage  = "schema"
+	instancesDir    = "instances"
+	roundTripSuffix = ".out"
+)
+
+// LocateModule returns the source directory of the xsd-codegen module as seen from the current
+// directory, which works inside the module itself and inside modules depending on it.
+func LocateModule(ctx context.Context) (string, error) {
+	out, err := exec.CommandContext(ctx, "go", "list", "-m", "-f", "{{.Dir}}", modulePath).Output()
+	dir := strings.TrimSpace(string(out))
+	if err != nil || dir == "" {
+		return "", fmt.Errorf("locate the %s module, set the module directory explicitly: %w", modulePath, errOf(err))
+	}
+	return dir, nil
+}
+
+// harnessSource is the program compiled against the generated types. For every file <GoName>/<n>.xml
+// of the directory given on the command line, it unmarshals the file into the Go type, marshals it
+// back to <n>.xml.out and prints a JSON result line.
+var harnessSource = template.Must(template.New("harness").Parse(`// Code generated by xsd-codegen selftest. DO NOT EDIT.
+
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"{{ .Module }}/{{ .Package }}"
+)
+
+var types = map[string]func() any{
+{{- range .Types }}
+	"{{ .GoName }}": func() any { return new({{ $.Package }}.{{ .GoName }}) },
+{{- end }}
+}
+
+type result struct {
+	Type     string ` + "`json:\"type\"`" + `
+	Instance int    ` + "`json:\"instance\"`" + `
+	Error    string ` + "`json:\"error,omitempty\"`" + `
+}
+
+func main() {
+	files, err := filepath.Glob(filepath.Join(os.Args[1], "*", "*.xml"))
+	if err != nil {
+		panic(err)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	for _, file := range files {
+		r := result{Type: filepath.Base(filepath.Dir(file))}
+		r.Instance, _ = strconv.Atoi(strings.TrimSuffix(filepath.Base(file), ".xml"))
+		if err := roundTrip(file, types[r.Type]()); err != nil {
+			r.Error = err.Error()
+		}
+		if err := enc.Encode(r); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func roundTrip(file string, v any) error {
+	in, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(in, v); err != nil {
+		return err
+	}
+	out, err := xml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file+"{{ .Suffix }}", out, 0o600)
+}
+`))
+
+// writeHarness writes the temporary module: go.mod pointing at moduleDir, the generated types and
+// the round-trip program.
+func writeHarness(dir, moduleDir string, src []byte, types []*TypeReport) error {
+	pkgDir := filepath.Join(dir, harnessPackage)
+	if err := os.MkdirAll(pkgDir, 0o750); err != nil {
+		return fmt.Errorf("create harness module: %w", err)
+	}
+	goMod := fmt.Sprintf("module %s\n\ngo 1.23\n\nrequire %s v0.0.0-00010101000000-000000000000\n\nreplace %s => %s\n",
+		harnessModule, modulePath, modulePath, moduleDir)
+	sum, err := os.ReadFile(filepath.Join(moduleDir, "go.sum"))
+	if err != nil {
+		return fmt.Errorf("read go.sum of %s: %w", moduleDir, err)
+	}
+	var program bytes.Buffer
+	err = harnessSource.Execute(&program, map[string]any{
+		"Module": harnessModule, "Package": harnessPackage, "Types": types, "Suffix": roundTripSuffix,
+	})
+	if err != nil {
+		return fmt.Errorf("render harness: %w", err)
+	}
+	for name, content := range map[string][]byte{
+		"go.mod":  []byte(goMod),
+		"go.sum":  sum,
+		"main.go": program.Bytes(),
+		filepath.Join(harnessPackage, harnessPackage+".go"): src,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o600); err != nil {
+			return fmt.Errorf("write harness module: %w", err)
+		}
+	}
+	return nil
+}
+
+// harnessResult is one JSON line printed by the harness.
+type harnessResult struct {
+	Type     string `json:"type"`
+	Instance int    `json:"instance"`
+	Error    string `json:"error"`
+}
+
+// runHarness compiles and runs the harness on every instance and returns the failures by Go type
+// and instance number.
+func runHarness(ctx context.Context, dir string) (map[string]map[int]string, error) {
+	cmd := exec.CommandContext(ctx, "go", "run", ".", instancesDir)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("run generated types: %w\n%s", err, stderr.String())
+	}
+	failures := map[string]map[int]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		var r harnessResult
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("read harness output: %w", err)
+		}
+		if r.Error == "" {
+			continue
+		}
+		if failures[r.Type] == nil {
+			failures[r.Type] = map[int]string{}
+		}
+		failures[r.Type][r.Instance] = r.Error
+	}
+	return failures, scanner.Err()
+}
+
+// errOf keeps the standard error of a failed command in its error message.
+func errOf(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	if err == nil {
+		return errors.New("module not found")
+	}
+	return err
+}
@@ -0,0 +1,202 @@
+// Package selftest checks that the Go types generated from a schema round-trip the XML documents of
+// that schema.
+//
+// Run generates random instances of every global element with the XML generator, compiles a small
+// program against the generated types in a temporary module, unmarshals each instance into its Go
+// type, marshals it back and compares both documents with Compare. Wrong struct tags, lost attributes
+// and namespace mistakes show up as the first divergent path of each type.
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/codegen"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/xmlgen"
+	"github.com/beevik/etree"
+)
+
+// DefaultCount is the number of instances generated per type when Options.Count is zero.
+const DefaultCount = 10
+
+// Options controls a self-test run.
+type Options struct {
+	// Count is the number of random instances per global element; zero means DefaultCount.
+	Count int
+	// Codegen configures the generated Go types. Package is ignored.
+	Codegen codegen.Options
+	// Generator produces the simple values of the instances; nil means helpers.DefaultValueGenerator.
+	Generator helpers.ValueGenerator
+	// ModuleDir is the source directory of the xsd-codegen module the generated code is compiled
+	// against. When empty it is located with "go list".
+	ModuleDir string
+	// WorkDir receives the temporary module and the instances and is kept after the run. When empty
+	// a temporary directory is used and removed.
+	WorkDir string
+}
+
+// Report lists the outcome of a self-test run per generated type.
+type Report struct {
+	Types []*TypeReport
+}
+
+// OK reports whether every instance of every type round-tripped.
+func (r *Report) OK() bool {
+	for _, t := range r.Types {
+		if t.Passed != t.Instances {
+			return false
+		}
+	}
+	return true
+}
+
+// TypeReport is the outcome of the instances of one global element.
+type TypeReport struct {
+	Element    string // name of the global element
+	GoName     string // generated Go type
+	Instances  int
+	Passed     int
+	Instance   int         // 1-based index of the first failing instance, 0 when every instance passed
+	Error      string      // unmarshal or marshal error of the first failing instance
+	Divergence *Divergence // first difference of the first failing instance
+}
+
+// String summarizes the report of a type on one line.
+func (t *TypeReport) String() string {
+	s := fmt.Sprintf("%s (%s): %d/%d instances round-trip", t.Element, t.GoName, t.Passed, t.Instances)
+	switch {
+	case t.Error != "":
+		s += fmt.Sprintf(", instance %d: %s", t.Instance, t.Error)
+	case t.Divergence != nil:
+		s += fmt.Sprintf(", instance %d diverges at %s", t.Instance, t.Divergence)
+	}
+	return s
+}
+
+// Run self-tests the Go types generated from schema. It returns an error when the test itself cannot
+// run, for instance when the generated code does not compile, and a Report otherwise.
+func Run(ctx context.Context, schema *model.XSDSchema, opts Options) (*Report, error) {
+	if opts.Count <= 0 {
+		opts.Count = DefaultCount
+	}
+	if opts.Generator == nil {
+		opts.Generator = helpers.DefaultValueGenerator{}
+	}
+	if opts.ModuleDir == "" {
+		dir, err := LocateModule(ctx)
+		if err != nil {
+			return nil, err
+		}
+		opts.ModuleDir = dir
+	}
+	workDir := opts.WorkDir
+	if workDir == "" {
+		dir, err := os.MkdirTemp("", "xsd-selftest-")
+		if err != nil {
+			return nil, fmt.Errorf("create work directory: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		workDir = dir
+	}
+
+	genOpts := opts.Codegen
+	genOpts.Package = harnessPackage
+	file, err := codegen.Describe(schema, genOpts)
+	if err != nil {
+		return nil, err
+	}
+	src, err := codegen.Generate(schema, genOpts)
+	if err != nil {
+		return nil, fmt.Errorf("generate Go types: %w", err)
+	}
+
+	report := &Report{}
+	for _, t := range file.Types {
+		if t.XMLName == "" {
+			continue
+		}
+		element, err := xmlgen.FindRoot(schema, "{"+t.Namespace+"}"+t.XMLName)
+		if err != nil {
+			continue
+		}
+		report.Types = append(report.Types, &TypeReport{Element: t.XMLName, GoName: t.GoName})
+		if err := writeInstances(schema, element, filepath.Join(workDir, instancesDir, t.GoName), opts); err != nil {
+			return nil, err
+		}
+	}
+	if len(report.Types) == 0 {
+		return report, nil
+	}
+	if err := writeHarness(workDir, opts.ModuleDir, src, report.Types); err != nil {
+		return nil, err
+	}
+	results, err := runHarness(ctx, workDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range report.Types {
+		if err := check(t, filepath.Join(workDir, instancesDir, t.GoName), opts.Count, results[t.GoName]); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// writeInstances writes opts.Count random documents of element into dir, named 1.xml, 2.xml, ...
+func writeInstances(schema *model.XSDSchema, element xmlgen.Root, dir string, opts Options) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("create instance directory: %w", err)
+	}
+	for i := 1; i <= opts.Count; i++ {
+		root := xmlgen.GenerateElement(schema, element.Element, opts.Generator)
+		if root == nil {
+			return fmt.Errorf("cannot generate an instance of %s within the default limits", element.Element.Name)
+		}
+		if element.Namespace != "" {
+			root.CreateAttr("xmlns", element.Namespace)
+		}
+		doc := etree.NewDocument()
+		doc.SetRoot(root)
+		if err := doc.WriteToFile(instanceFile(dir, i)); err != nil {
+			return fmt.Errorf("write instance: %w", err)
+		}
+	}
+	return nil
+}
+
+// check compares every instance of t with its round-tripped copy and fills in the report.
+func check(t *TypeReport, dir string, count int, failures map[int]string) error {
+	t.Instances = count
+	for i := 1; i <= count; i++ {
+		if msg, failed := failures[i]; failed {
+			if t.Instance == 0 {
+				t.Instance, t.Error = i, msg
+			}
+			continue
+		}
+		want, got := etree.NewDocument(), etree.NewDocument()
+		if err := want.ReadFromFile(instanceFile(dir, i)); err != nil {
+			return fmt.Errorf("read instance: %w", err)
+		}
+		if err := got.ReadFromFile(instanceFile(dir, i) + roundTripSuffix); err != nil {
+			return fmt.Errorf("read round-tripped instance: %w", err)
+		}
+		if d := Compare(want.Root(), got.Root()); d != nil {
+			if t.Instance == 0 {
+				t.Instance, t.Divergence = i, d
+			}
+			continue
+		}
+		t.Passed++
+	}
+	return nil
+}
+
+func instanceFile(dir string, i int) string {
+	return filepath.Join(dir, strconv.Itoa(i)+".xml")
+}
@@ -0,0 +1,53 @@
+package selftest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/codegen"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunRoundTripsGeneratedTypes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles a temporary module")
+	}
+	schema, err := parser.ParseXSD(filepath.Join("testdata", "roundtrip.xsd"), nil)
+	require.NoError(t, err)
+
+	for _, style := range []codegen.ChoiceStyle{codegen.ChoiceFields, codegen.ChoiceInterface} {
+		opts := Options{Count: 5, WorkDir: t.TempDir(), Codegen: codegen.Options{ChoiceStyle: style}}
+		report, err := Run(context.Background(), schema, opts)
+		require.NoError(t, err)
+		require.Len(t, report.Types, 1)
+		assert.True(t, report.OK(), "%s: %s", style, report.Types[0])
+		assert.Equal(t, "Shipment", report.Types[0].GoName)
+		assert.Equal(t, 5, report.Types[0].Passed)
+	}
+}
+
+func TestCheckReportsFirstFailingInstance(t *testing.T) {
+	dir := t.TempDir()
+	for i, pair := range [][2]string{
+		{`<order a="1"><line>1</line></order>`, `<order a="1"><line>1.0</line></order>`},
+		{`<order a="1"><line>1</line></order>`, `<order><line>1</line></order>`},
+		{`<order a="1"><line>2</line></order>`, `<order a="1"><line>3</line></order>`},
+		{`<order/>`, ``},
+	} {
+		require.NoError(t, os.WriteFile(instanceFile(dir, i+1), []byte(pair[0]), 0o600))
+		if pair[1] != "" {
+			require.NoError(t, os.WriteFile(instanceFile(dir, i+1)+roundTripSuffix, []byte(pair[1]), 0o600))
+		}
+	}
+
+	report := &TypeReport{Element: "order", GoName: "Order"}
+	require.NoError(t, check(report, dir, 4, map[int]string{4: "expected element type <order>"}))
+	assert.Equal(t, 1, report.Passed)
+	assert.Equal(t, 2, report.Instance)
+	assert.Equal(t, "/order/@a", report.Divergence.Path)
+	assert.Equal(t, "order (Order): 1/4 instances round-trip, instance 2 diverges at /order/@a: missing attribute", report.String())
+}
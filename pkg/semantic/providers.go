@@ -0,0 +1,160 @@
+package semantic
+
+import (
+	"embed"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+)
+
+//go:embed data/*.txt
+var data embed.FS
+
+// list returns the lines of an embedded word list.
+func list(name string) []string {
+	content, err := data.ReadFile("data/" + name + ".txt")
+	if err != nil {
+		panic(err)
+	}
+	return strings.Split(strings.TrimSpace(string(content)), "\n")
+}
+
+var (
+	firstNames = list("first_names")
+	lastNames  = list("last_names")
+	companies  = list("companies")
+	products   = list("products")
+	streets    = list("streets")
+	cities     = list("cities")
+	states     = list("states")
+	countries  = list("countries") // "FR France"
+	currencies = list("currencies")
+	words      = list("words")
+)
+
+// countryCodes and countryNames are the ISO 3166 codes and the names of countries.
+var countryCodes, countryNames = splitCountries()
+
+func splitCountries() (codes, names []string) {
+	for _, c := range countries {
+		code, name, _ := strings.Cut(c, " ")
+		codes, names = append(codes, code), append(names, name)
+	}
+	return codes, names
+}
+
+// streetSuffixes complete the names of streets.
+var streetSuffixes = []string{"Street", "Avenue", "Road", "Lane", "Boulevard", "Drive", "Court"}
+
+// domains are reserved for documentation, so generated addresses never reach anyone.
+var domains = []string{"example.com", "example.org", "example.net"}
+
+// ibanFormats gives the length of the basic bank account number of a few countries, and how many of its
+// first characters are letters.
+var ibanFormats = map[string][2]int{"DE": {18, 0}, "FR": {23, 0}, "ES": {20, 0}, "GB": {18, 4}, "NL": {14, 4}}
+
+func pick(r *helpers.Rand, values []string) string {
+	return values[r.Intn(len(values))]
+}
+
+func builtins() []Provider {
+	return []Provider{
+		{Kind: "fullName", Names: []string{"name", "full name", "fullname", "contact name", "person"},
+			Generate: func(r *helpers.Rand) string { return pick(r, firstNames) + " " + pick(r, lastNames) }},
+		{Kind: "firstName", Names: []string{"first name", "firstname", "given name", "forename"},
+			Values: firstNames},
+		{Kind: "lastName", Names: []string{"last name", "lastname", "surname", "family name"},
+			Values: lastNames},
+		{Kind: "company", Names: []string{"company", "company name", "organization", "organisation", "employer", "vendor", "supplier"},
+			Values: companies},
+		{Kind: "product", Names: []string{"product", "product name", "item name", "article"},
+			Values: products},
+		{Kind: "email", Names: []string{"email", "e mail", "mail", "email address"}, Generate: email},
+		{Kind: "phone", Names: []string{"phone", "telephone", "tel", "mobile", "fax", "phone number"}, Generate: phone},
+		{Kind: "street", Names: []string{"street", "address", "street address", "address line", "addr"},
+			Generate: func(r *helpers.Rand) string {
+				return strconv.Itoa(1+r.Intn(999)) + " " + pick(r, streets) + " " + pick(r, streetSuffixes)
+			}},
+		{Kind: "city", Names: []string{"city", "city name", "town", "locality"},
+			Values: cities},
+		{Kind: "state", Names: []string{"state", "province", "region"},
+			Values: states},
+		{Kind: "postalCode", Names: []string{"zip", "zip code", "zipcode", "postal code", "postcode", "post code"},
+			Generate: func(r *helpers.Rand) string { return fmt.Sprintf("%05d", 10000+r.Intn(90000)) }},
+		{Kind: "country", Names: []string{"country", "country name", "nation"},
+			Values: countryNames},
+		{Kind: "countryCode", Names: []string{"country code", "iso country"},
+			Values: countryCodes},
+		{Kind: "currency", Names: []string{"currency", "currency code"},
+			Values: currencies},
+		{Kind: "iban", Names: []string{"iban"}, Generate: iban},
+		{Kind: "url", Names: []string{"url", "uri", "website", "web site", "homepage", "link", "href"},
+			Generate: func(r *helpers.Rand) string {
+				return "https://www." + pick(r, domains) + "/" + pick(r, words) + "/" + pick(r, words)
+			}},
+		{Kind: "uuid", Names: []string{"uuid", "guid"}, Generate: uuid},
+		{Kind: "description", Names: []string{"description", "comment", "note", "remark", "summary"}, Generate: sentence},
+	}
+}
+
+// email returns first.last@domain, in lower case.
+func email(r *helpers.Rand) string {
+	return strings.ToLower(pick(r, firstNames)+"."+pick(r, lastNames)) + "@" + pick(r, domains)
+}
+
+// phone returns a North American number of the 555-01xx range reserved for fiction.
+func phone(r *helpers.Rand) string {
+	return fmt.Sprintf("+1-%d-555-01%02d", 201+r.Intn(700), r.Intn(100))
+}
+
+// iban returns an IBAN, in electronic form, whose check digits are valid.
+func iban(r *helpers.Rand) string {
+	code := pick(r, []string{"DE", "ES", "FR", "GB", "NL"})
+	format := ibanFormats[code]
+	var bban strings.Builder
+	for i := range format[0] {
+		if i < format[1] {
+			bban.WriteByte(byte('A' + r.Intn(26)))
+		} else {
+			bban.WriteByte(byte('0' + r.Intn(10)))
+		}
+	}
+	// ISO 13616: the check digits make the number formed by the BBAN, the country and the check digits,
+	// letters counting as 10 to 35, equal to 1 modulo 97.
+	var digits strings.Builder
+	for _, c := range bban.String() + code + "00" {
+		if c >= 'A' && c <= 'Z' {
+			digits.WriteString(strconv.Itoa(int(c-'A') + 10))
+		} else {
+			digits.WriteRune(c)
+		}
+	}
+	n, _ := new(big.Int).SetString(digits.String(), 10)
+	check := 98 - new(big.Int).Mod(n, big.NewInt(97)).Int64()
+	return fmt.Sprintf("%s%02d%s", code, check, bban.String())
+}
+
+// uuid returns a random, version 4 UUID.
+func uuid(r *helpers.Rand) string {
+	var b [16]byte
+	for i := range b {
+		b[i] = byte(r.Intn(256))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// sentence returns a short sentence of words of the embedded list.
+func sentence(r *helpers.Rand) string {
+	n := 4 + r.Intn(6)
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = pick(r, words)
+	}
+	s := strings.Join(parts, " ")
+	return strings.ToUpper(s[:1]) + s[1:] + "."
+}
@@ -0,0 +1,180 @@
+// Package semantic generates realistic fake values, such as names, e-mail addresses or IBANs, for the
+// elements and attributes whose names or simple type names tell what they hold. The values come from
+// word lists embedded in the package, so generation stays offline and reproducible.
+package semantic
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+)
+
+// attempts is the number of values drawn from a provider before falling back, when they break the
+// facets of the type.
+const attempts = 10
+
+// Provider generates realistic values of one kind.
+type Provider struct {
+	// Kind names the values, such as "email".
+	Kind string
+	// Names are the words of the element, attribute and simple type names the provider is picked for,
+	// such as "email" or "postal code". They match the words of camelCase, snake_case and kebab-case names.
+	Names []string
+	// Values lists the values of providers picking from a fixed list, such as city names.
+	Values []string
+	// Generate returns a value drawn from r, for providers without Values.
+	Generate func(r *helpers.Rand) string
+}
+
+// Registry picks providers by name.
+type Registry struct {
+	providers []Provider
+}
+
+// NewRegistry returns a registry of the built-in providers: names, companies, products, e-mail addresses,
+// phone numbers, streets, cities, states, postal codes, countries and country codes, currencies, IBANs, URLs,
+// UUIDs and descriptions.
+func NewRegistry() *Registry {
+	r := &Registry{}
+	for _, p := range builtins() {
+		r.Register(p)
+	}
+	return r
+}
+
+// Register adds a provider, which takes precedence over the providers registered before it when their
+// names match equally well.
+func (r *Registry) Register(p Provider) {
+	r.providers = append(r.providers, p)
+}
+
+// Lookup returns the provider of the values named name, an element, attribute or simple type name. The
+// provider whose name matches the last words of name wins, then the one with the longest name: in
+// "billingEmailAddress", "email address" beats "address", and in "countryCode", "country code" beats
+// "country". A trailing "type" word is ignored.
+func (r *Registry) Lookup(name string) (Provider, bool) {
+	words := Words(name)
+	if len(words) > 1 && words[len(words)-1] == "type" {
+		words = words[:len(words)-1]
+	}
+	best, bestEnd, bestLength := -1, -1, 0
+	for i, p := range r.providers {
+		for _, n := range p.Names {
+			key := Words(n)
+			for end := len(words) - 1; end >= len(key)-1; end-- {
+				if !slices.Equal(words[end-len(key)+1:end+1], key) {
+					continue
+				}
+				if end > bestEnd || end == bestEnd && len(key) >= bestLength {
+					best, bestEnd, bestLength = i, end, len(key)
+				}
+				break
+			}
+		}
+	}
+	if best < 0 {
+		return Provider{}, false
+	}
+	return r.providers[best], true
+}
+
+// Value returns a realistic value of the string type xsdType restricted by restriction, from the
+// provider of the first of names that has one and generates a value within the facets. It returns false
+// for other types, for enumerations, whose values are realistic already, and when no provider fits, so
+// that the caller falls back to its own generator.
+func (r *Registry) Value(rand *helpers.Rand, xsdType string, restriction *model.XSDRestriction, names ...string) (string, bool) {
+	if r == nil || !isStringType(xsdType) || restriction != nil && len(restriction.Enumerations) > 0 {
+		return "", false
+	}
+	var facets *validation.Facets
+	if restriction != nil {
+		facets = helpers.FacetsOf(restriction)
+	}
+	for _, name := range names {
+		p, ok := r.Lookup(name)
+		if !ok {
+			continue
+		}
+		if value, ok := p.value(rand, facets); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// Generate implements helpers.ValueGenerator with the provider of the name of the node, or else of its
+// simple type, drawing from the Rand of vc. It declines the nodes Value returns false for.
+func (r *Registry) Generate(vc *helpers.ValueContext) (string, bool) {
+	typeName := vc.Type
+	if i := strings.LastIndexByte(typeName, ':'); i >= 0 {
+		typeName = typeName[i+1:]
+	}
+	return r.Value(vc.Rand, vc.Base, vc.Restriction, vc.Name.Local, typeName)
+}
+
+// value returns a value of p within facets: one of the Values that are, or the first of a few generated
+// values that is.
+func (p Provider) value(rand *helpers.Rand, facets *validation.Facets) (string, bool) {
+	if len(p.Values) > 0 {
+		var valid []string
+		for _, v := range p.Values {
+			if helpers.Satisfies(v, facets) {
+				valid = append(valid, v)
+			}
+		}
+		if len(valid) == 0 {
+			return "", false
+		}
+		return valid[rand.Intn(len(valid))], true
+	}
+	for range attempts {
+		if value := p.Generate(rand); helpers.Satisfies(value, facets) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// Words splits a name into lower-case words at case changes, digits and punctuation:
+// "shipToCity" gives ship, to, city and "USPrice" gives us, price.
+func Words(name string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(name)
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	for i, c := range runes {
+		switch {
+		case !unicode.IsLetter(c) && !unicode.IsDigit(c):
+			flush()
+			continue
+		case len(word) > 0 && unicode.IsDigit(c) != unicode.IsDigit(word[len(word)-1]):
+			flush()
+		case unicode.IsUpper(c) && len(word) > 0 && unicode.IsLower(word[len(word)-1]):
+			flush()
+		case unicode.IsUpper(c) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && len(word) > 0 &&
+			unicode.IsUpper(word[len(word)-1]):
+			flush()
+		}
+		word = append(word, c)
+	}
+	flush()
+	return words
+}
+
+// isStringType reports whether the built-in type xsdType holds free text.
+func isStringType(xsdType string) bool {
+	switch xsdType[strings.LastIndex(xsdType, ":")+1:] {
+	case "string", "normalizedString", "token", "anyURI":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package semantic_test
+
+import (
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/semantic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWords(t *testing.T) {
+	cases := map[string][]string{
+		"shipToCity":    {"ship", "to", "city"},
+		"USPrice":       {"us", "price"},
+		"postal_code":   {"postal", "code"},
+		"e-mail":        {"e", "mail"},
+		"addressLine2":  {"address", "line", "2"},
+		"IBAN":          {"iban"},
+		"EmailAddrType": {"email", "addr", "type"},
+	}
+	for name, want := range cases {
+		assert.Equal(t, want, semantic.Words(name), name)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	registry := semantic.NewRegistry()
+	cases := map[string]string{
+		"name":                "fullName",
+		"firstName":           "firstName",
+		"customer_surname":    "lastName",
+		"billingEmailAddress": "email",
+		"e-mail":              "email",
+		"street":              "street",
+		"cityName":            "city",
+		"zipCode":             "postalCode",
+		"country":             "country",
+		"countryCode":         "countryCode",
+		"CurrencyCodeType":    "currency",
+		"IBAN":                "iban",
+		"homepageURL":         "url",
+		"orderGUID":           "uuid",
+		"productName":         "product",
+		"phoneNumber":         "phone",
+		"comment":             "description",
+	}
+	for name, kind := range cases {
+		p, ok := registry.Lookup(name)
+		if assert.True(t, ok, name) {
+			assert.Equal(t, kind, p.Kind, name)
+		}
+	}
+	for _, name := range []string{"quantity", "orderDate", "partNum"} {
+		_, ok := registry.Lookup(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestProvidersGenerateWellFormedValues(t *testing.T) {
+	registry := semantic.NewRegistry()
+	r := helpers.NewRand(1)
+	formats := map[string]*regexp.Regexp{
+		"email": regexp.MustCompile(`^[a-z]+\.[a-z]+@example\.(com|org|net)$`),
+		"uuid":  regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`),
+		"phone": regexp.MustCompile(`^\+1-\d{3}-555-01\d{2}$`),
+		"zip":   regexp.MustCompile(`^\d{5}$`),
+		"iban":  regexp.MustCompile(`^[A-Z]{2}\d{2}[A-Z0-9]{10,30}$`),
+	}
+	for range 50 {
+		for name, format := range formats {
+			p, ok := registry.Lookup(name)
+			require.True(t, ok, name)
+			assert.Regexp(t, format, p.Generate(r), name)
+		}
+		p, _ := registry.Lookup("iban")
+		assert.True(t, validIBAN(p.Generate(r)))
+	}
+}
+
+// validIBAN checks the check digits of an IBAN, as banks do.
+func validIBAN(iban string) bool {
+	var digits strings.Builder
+	for _, c := range iban[4:] + iban[:4] {
+		if c >= 'A' && c <= 'Z' {
+			digits.WriteString(strconv.Itoa(int(c-'A') + 10))
+		} else {
+			digits.WriteRune(c)
+		}
+	}
+	n, _ := new(big.Int).SetString(digits.String(), 10)
+	return new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+func TestValueStaysWithinFacets(t *testing.T) {
+	registry := semantic.NewRegistry()
+	r := helpers.NewRand(1)
+
+	value, ok := registry.Value(r, "xs:string", &model.XSDRestriction{MaxLength: &model.XSDValue{Value: "4"}}, "city")
+	require.True(t, ok)
+	assert.LessOrEqual(t, len(value), 4)
+
+	_, ok = registry.Value(r, "xs:string", &model.XSDRestriction{MaxLength: &model.XSDValue{Value: "2"}}, "city")
+	assert.False(t, ok, "no city fits, so the caller falls back")
+
+	value, ok = registry.Value(r, "xs:string", &model.XSDRestriction{Pattern: &model.XSDPattern{Value: "[A-Z]{3}"}},
+		"amount", "CurrencyType")
+	require.True(t, ok, "the type name is tried after the node name")
+	assert.Regexp(t, `^[A-Z]{3}$`, value)
+
+	_, ok = registry.Value(r, "xs:decimal", nil, "zip")
+	assert.False(t, ok, "only string types get semantic values")
+	_, ok = registry.Value(r, "xs:string", &model.XSDRestriction{Enumerations: []model.XSDValue{{Value: "x"}}}, "city")
+	assert.False(t, ok, "enumerations keep their values")
+	_, ok = (*semantic.Registry)(nil).Value(r, "xs:string", nil, "city")
+	assert.False(t, ok)
+}
+
+func TestRegisterTakesPrecedence(t *testing.T) {
+	registry := semantic.NewRegistry()
+	registry.Register(semantic.Provider{Kind: "sku", Names: []string{"name"}, Generate: func(*helpers.Rand) string { return "SKU-1" }})
+	value, ok := registry.Value(helpers.NewRand(1), "xs:string", nil, "name")
+	require.True(t, ok)
+	assert.Equal(t, "SKU-1", value)
+}
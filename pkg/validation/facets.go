@@ -0,0 +1,237 @@
+package validation
+
+import (
+	"encoding"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/xsdtypes"
+)
+
+// Facets holds the constraining facets of a simple type as written in the schema.
+// Empty strings mean the facet is not set. The zero value accepts everything.
+type Facets struct {
+	MinInclusive   string
+	MaxInclusive   string
+	MinExclusive   string
+	MaxExclusive   string
+	Length         string
+	MinLength      string
+	MaxLength      string
+	TotalDigits    string
+	FractionDigits string
+	Pattern        string
+	Enumerations   []string
+	Fixed          string
+
+	once    sync.Once
+	pattern *regexp.Regexp
+	err     error
+}
+
+// validator is implemented by generated enum types.
+type validator interface {
+	IsValid() bool
+}
+
+// CheckValue validates a simple value against facets, which may be nil.
+// Pointers are dereferenced; absent values (nil or zero) are skipped when optional is true.
+// Values implementing IsValid, such as generated enums, must also report themselves valid.
+func CheckValue(path string, value any, facets *Facets, optional bool, errs *Errors) {
+	if optional && !Present(value) {
+		return
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			errs.Add(path, "missing required value")
+			return
+		}
+		value = rv.Elem().Interface()
+	}
+	if v, ok := value.(validator); ok && !v.IsValid() {
+		errs.Add(path, "value %q is not one of the enumerated values", Lexical(value))
+	}
+	if facets != nil {
+		facets.check(path, value, errs)
+	}
+}
+
+// check runs every facet that is set against the lexical form of value.
+func (f *Facets) check(path string, value any, errs *Errors) {
+	lexical := Lexical(value)
+	if f.Fixed != "" && lexical != f.Fixed {
+		errs.Add(path, "value %q differs from the fixed value %q", lexical, f.Fixed)
+	}
+	if len(f.Enumerations) > 0 && !contains(f.Enumerations, lexical) {
+		errs.Add(path, "value %q is not one of %s", lexical, strings.Join(f.Enumerations, ", "))
+	}
+	f.checkPattern(path, lexical, errs)
+	f.checkLength(path, value, lexical, errs)
+	f.checkBounds(path, lexical, errs)
+	f.checkDigits(path, lexical, errs)
+}
+
+// checkPattern matches lexical against the pattern facet, compiled once with XSD semantics.
+func (f *Facets) checkPattern(path, lexical string, errs *Errors) {
+	if f.Pattern == "" {
+		return
+	}
+	f.once.Do(func() { f.pattern, f.err = CompilePattern(f.Pattern) })
+	if f.err != nil {
+		errs.Add(path, "invalid pattern %q: %v", f.Pattern, f.err)
+		return
+	}
+	if !f.pattern.MatchString(lexical) {
+		errs.Add(path, "value %q does not match pattern %q", lexical, f.Pattern)
+	}
+}
+
+// checkLength applies length, minLength and maxLength, counted in octets for binary values
+// and in characters otherwise.
+func (f *Facets) checkLength(path string, value any, lexical string, errs *Errors) {
+	if f.Length == "" && f.MinLength == "" && f.MaxLength == "" {
+		return
+	}
+	n := utf8.RuneCountInString(lexical)
+	if b, ok := asBytes(value); ok {
+		n = len(b)
+	}
+	if l, err := strconv.Atoi(f.Length); err == nil && n != l {
+		errs.Add(path, "length %d differs from the required length %d", n, l)
+	}
+	if l, err := strconv.Atoi(f.MinLength); err == nil && n < l {
+		errs.Add(path, "length %d is below minLength %d", n, l)
+	}
+	if l, err := strconv.Atoi(f.MaxLength); err == nil && n > l {
+		errs.Add(path, "length %d exceeds maxLength %d", n, l)
+	}
+}
+
+// checkBounds applies the inclusive and exclusive bounds to numeric and date/time values.
+func (f *Facets) checkBounds(path, lexical string, errs *Errors) {
+	bounds := []struct {
+		limit, name string
+		ok          func(cmp int) bool
+	}{
+		{f.MinInclusive, "minInclusive", func(c int) bool { return c >= 0 }},
+		{f.MaxInclusive, "maxInclusive", func(c int) bool { return c <= 0 }},
+		{f.MinExclusive, "minExclusive", func(c int) bool { return c > 0 }},
+		{f.MaxExclusive, "maxExclusive", func(c int) bool { return c < 0 }},
+	}
+	for _, b := range bounds {
+		if b.limit == "" {
+			continue
+		}
+		cmp, comparable := Compare(lexical, b.limit)
+		if comparable && !b.ok(cmp) {
+			errs.Add(path, "value %s violates %s %s", lexical, b.name, b.limit)
+		}
+	}
+}
+
+// checkDigits applies totalDigits and fractionDigits to decimal values.
+func (f *Facets) checkDigits(path, lexical string, errs *Errors) {
+	if f.TotalDigits == "" && f.FractionDigits == "" {
+		return
+	}
+	total, fraction, ok := Digits(lexical)
+	if !ok {
+		return
+	}
+	if l, err := strconv.Atoi(f.TotalDigits); err == nil && total > l {
+		errs.Add(path, "value %s has %d digits, more than totalDigits %d", lexical, total, l)
+	}
+	if l, err := strconv.Atoi(f.FractionDigits); err == nil && fraction > l {
+		errs.Add(path, "value %s has %d fraction digits, more than fractionDigits %d", lexical, fraction, l)
+	}
+}
+
+// Lexical returns the XSD lexical form of a generated field value.
+func Lexical(value any) string {
+	switch v := value.(type) {
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(text)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// Compare orders two lexical values numerically or, failing that, as dates and times.
+// The boolean is false when the values are not comparable.
+func Compare(a, b string) (int, bool) {
+	if ra, ok := new(big.Rat).SetString(a); ok {
+		if rb, ok := new(big.Rat).SetString(b); ok {
+			return ra.Cmp(rb), true
+		}
+	}
+	ta, okA := parseInstant(a)
+	tb, okB := parseInstant(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	return ta.Compare(tb), true
+}
+
+// parseInstant parses the calendar types that support ordering facets.
+func parseInstant(s string) (time.Time, bool) {
+	if d, err := xsdtypes.ParseDateTime(s); err == nil {
+		return d.Time, true
+	}
+	if d, err := xsdtypes.ParseDate(s); err == nil {
+		return d.Time, true
+	}
+	if t, err := xsdtypes.ParseTime(s); err == nil {
+		return t.Time, true
+	}
+	return time.Time{}, false
+}
+
+// Digits counts the significant total and fraction digits of a decimal lexical value.
+func Digits(lexical string) (total, fraction int, ok bool) {
+	if _, err := xsdtypes.ParseDecimal(lexical); err != nil {
+		return 0, 0, false
+	}
+	unsigned := strings.TrimLeft(lexical, "+-")
+	intPart, fracPart, _ := strings.Cut(unsigned, ".")
+	intPart = strings.TrimLeft(intPart, "0")
+	fracPart = strings.TrimRight(fracPart, "0")
+	total = len(intPart) + len(fracPart)
+	if total == 0 {
+		total = 1
+	}
+	return total, len(fracPart), true
+}
+
+// asBytes returns the content of byte-slice values such as xsdtypes.HexBinary.
+func asBytes(value any) ([]byte, bool) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+		return rv.Bytes(), true
+	}
+	return nil, false
+}
+
+func contains(values []string, v string) bool {
+	for _, candidate := range values {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package validation
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Character classes of XML Schema regular expressions expressed in RE2 syntax, without brackets.
+const (
+	nameStartChars = `_:A-Za-z\x{C0}-\x{D6}\x{D8}-\x{F6}\x{F8}-\x{2FF}\x{370}-\x{37D}\x{37F}-\x{1FFF}` +
+		`\x{200C}-\x{200D}\x{2070}-\x{218F}\x{2C00}-\x{2FEF}\x{3001}-\x{D7FF}\x{F900}-\x{FDCF}\x{FDF0}-\x{FFFD}`
+	nameChars = nameStartChars + `\-.0-9\x{B7}\x{300}-\x{36F}\x{203F}-\x{2040}`
+	wordChars = `\p{L}\p{M}\p{N}\p{S}`
+	spaceChar = `\x20\t\n\r`
+)
+
+// classEscapes translates the multi-character escapes whose meaning differs between XSD and RE2.
+// The first form is used outside brackets and the second inside a character class; an empty
+// second form means the escape cannot be used inside a class.
+var classEscapes = map[byte][2]string{
+	'i': {"[" + nameStartChars + "]", nameStartChars},
+	'I': {"[^" + nameStartChars + "]", ""},
+	'c': {"[" + nameChars + "]", nameChars},
+	'C': {"[^" + nameChars + "]", ""},
+	'd': {`\p{Nd}`, `\p{Nd}`},
+	'D': {`\P{Nd}`, `\P{Nd}`},
+	'w': {"[" + wordChars + "]", wordChars},
+	'W': {`[\p{P}\p{Z}\p{C}]`, `\p{P}\p{Z}\p{C}`},
+	's': {"[" + spaceChar + "]", spaceChar},
+	'S': {"[^" + spaceChar + "]", ""},
+}
+
+// generatorEscapes are ASCII subsets of the classes of classEscapes, in the same forms, from which
+// generators draw values that are readable and stay within the lexical space of numbers.
+var generatorEscapes = map[byte][2]string{
+	'i': {"[_:A-Za-z]", "_:A-Za-z"},
+	'I': {`[\-.0-9]`, ""},
+	'c': {`[\-._:0-9A-Za-z]`, `\-._:0-9A-Za-z`},
+	'C': {"[ !#%&*+,/;=?@]", ""},
+	'd': {"[0-9]", "0-9"},
+	'D': {"[A-Za-z]", "A-Za-z"},
+	'w': {"[0-9A-Za-z]", "0-9A-Za-z"},
+	'W': {`[ !#%&*,\-./:;?@_]`, ` !#%&*,\-./:;?@_`},
+	's': {"[" + spaceChar + "]", spaceChar},
+	'S': {"[!-~]", ""},
+}
+
+// CompilePattern compiles an XSD pattern facet into a Go regular expression.
+// XSD patterns are implicitly anchored, treat ^ and $ as ordinary characters, use Unicode \d and \w,
+// and know the XML name classes \i and \c. Character class subtraction is not supported by RE2.
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	translated, err := translatePattern(pattern, nil)
+	if err != nil {
+		return nil, err
+	}
+	return regexp.Compile("^(?:" + translated + ")$")
+}
+
+// GeneratorPattern rewrites an XSD pattern facet into an RE2 expression, without anchors, that only
+// matches values of the pattern, for generating them: the escapes \d, \w, \i and the like stand for the
+// ASCII characters of their XSD classes, where RE2 would give them another meaning, such as \w matching
+// "_". Escapes inside negated character classes keep their whole class.
+func GeneratorPattern(pattern string) (string, error) {
+	return translatePattern(pattern, generatorEscapes)
+}
+
+// translatePattern rewrites an XSD regular expression into RE2 syntax. The escapes outside of negated
+// character classes take their form from subsets, when it is not nil, rather than classEscapes.
+func translatePattern(pattern string, subsets map[byte][2]string) (string, error) {
+	var sb strings.Builder
+	inClass, negated := false, false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			escapes := classEscapes
+			if subsets != nil && !negated {
+				escapes = subsets
+			}
+			if err := writeEscape(&sb, escapes, pattern[i], inClass); err != nil {
+				return "", err
+			}
+		case c == '[' && inClass:
+			return "", errors.New("character class subtraction is not supported")
+		case c == '[':
+			inClass = true
+			sb.WriteByte(c)
+			if i+1 < len(pattern) && pattern[i+1] == '^' {
+				i++
+				negated = true
+				sb.WriteByte('^')
+			}
+		case c == ']' && inClass:
+			inClass, negated = false, false
+			sb.WriteByte(c)
+		case (c == '^' || c == '$') && !inClass:
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String(), nil
+}
+
+// writeEscape writes the RE2 form of the escape \c found in escapes.
+func writeEscape(sb *strings.Builder, escapes map[byte][2]string, c byte, inClass bool) error {
+	forms, ok := escapes[c]
+	if !ok {
+		sb.WriteByte('\\')
+		sb.WriteByte(c)
+		return nil
+	}
+	if !inClass {
+		sb.WriteString(forms[0])
+		return nil
+	}
+	if forms[1] == "" {
+		return errors.New(`negated escape \` + string(c) + " cannot be used inside a character class")
+	}
+	sb.WriteString(forms[1])
+	return nil
+}
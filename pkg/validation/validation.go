@@ -0,0 +1,117 @@
+// Package validation is the runtime used by the Validate methods of code generated by xsd-codegen.
+//
+// Violations are collected in an Errors value rather than returned one by one, so a single Validate call
+// reports every problem of a message. Each violation carries an XPath-like location such as
+// "/purchaseOrder/items/item[3]/quantity" or "/purchaseOrder/@confirmDate".
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Unbounded is the maximum number of occurrences of a maxOccurs="unbounded" particle.
+const Unbounded = -1
+
+// Error is a single constraint violation.
+type Error struct {
+	Path    string
+	Message string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Errors aggregates the violations found while validating a value.
+type Errors []*Error
+
+// Add records a violation at path.
+func (e *Errors) Add(path, format string, args ...any) {
+	*e = append(*e, &Error{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Error implements the error interface, listing one violation per line.
+func (e Errors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Err returns nil when no violation was recorded and e otherwise.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Index returns the location of the i-th (zero-based) occurrence of a repeated element.
+func Index(path string, i int) string {
+	return path + "[" + strconv.Itoa(i+1) + "]"
+}
+
+// CheckOccurs verifies that count lies within [minOccurs, maxOccurs]; maxOccurs may be Unbounded.
+func CheckOccurs(path string, count, minOccurs, maxOccurs int, errs *Errors) {
+	if count < minOccurs {
+		errs.Add(path, "expected at least %d occurrence(s), got %d", minOccurs, count)
+	}
+	if maxOccurs != Unbounded && count > maxOccurs {
+		errs.Add(path, "expected at most %d occurrence(s), got %d", maxOccurs, count)
+	}
+}
+
+// Branch is one alternative of an xs:choice, identified by its element name.
+type Branch struct {
+	Name  string
+	Value any
+}
+
+// CheckChoice verifies that at most one branch of a choice is present, and exactly one when required.
+// A branch is present when its value is a non-nil pointer, a non-empty slice or any other non-zero value.
+func CheckChoice(path string, required bool, errs *Errors, branches ...Branch) {
+	var present []string
+	for _, b := range branches {
+		if Present(b.Value) {
+			present = append(present, b.Name)
+		}
+	}
+	switch {
+	case len(present) > 1:
+		errs.Add(path, "choice allows a single branch, got %s", strings.Join(present, ", "))
+	case len(present) == 0 && required:
+		errs.Add(path, "choice requires one of %s", branchNames(branches))
+	}
+}
+
+func branchNames(branches []Branch) string {
+	names := make([]string, len(branches))
+	for i, b := range branches {
+		names[i] = b.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// Present reports whether v holds a value: nil pointers, empty slices and zero values are absent.
+func Present(v any) bool {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return false
+	}
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return !rv.IsNil()
+	case reflect.Slice, reflect.Map:
+		return rv.Len() > 0
+	default:
+		return !rv.IsZero()
+	}
+}
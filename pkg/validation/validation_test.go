@@ -0,0 +1,105 @@
+package validation_test
+
+import (
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/xsdtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompilePatternSemantics(t *testing.T) {
+	cases := []struct {
+		pattern string
+		input   string
+		match   bool
+	}{
+		{`\d{3}`, "123", true},
+		{`\d{3}`, "1234", false}, // implicitly anchored
+		{`\d`, "٣", true},        // Unicode digits
+		{`a^b$`, "a^b$", true},   // ^ and $ are ordinary characters
+		{`\i\c*`, "xs:element-1", true},
+		{`\i\c*`, "1abc", false},
+		{`[\i-]+`, "a-b", true},
+		{`\s+`, " \t", true},
+		{`\w+`, "héllo", true},
+		{`\w`, "-", false},
+	}
+	for _, tc := range cases {
+		re, err := validation.CompilePattern(tc.pattern)
+		require.NoError(t, err, tc.pattern)
+		assert.Equal(t, tc.match, re.MatchString(tc.input), "%s against %q", tc.pattern, tc.input)
+	}
+}
+
+func TestCompilePatternUnsupported(t *testing.T) {
+	_, err := validation.CompilePattern(`[a-z-[aeiou]]`)
+	assert.Error(t, err)
+	_, err = validation.CompilePattern(`[\I]`)
+	assert.Error(t, err)
+}
+
+func TestGeneratorPattern(t *testing.T) {
+	for pattern, want := range map[string]string{
+		`\d{30}\w{30}`: `[0-9]{30}[0-9A-Za-z]{30}`,
+		`[\i-]\c*`:     `[_:A-Za-z-][\-._:0-9A-Za-z]*`,
+		`[^\w]`:        `[^\p{L}\p{M}\p{N}\p{S}]`, // negated classes keep the whole class
+		`a^b`:          `a\^b`,
+	} {
+		got, err := validation.GeneratorPattern(pattern)
+		require.NoError(t, err, pattern)
+		assert.Equal(t, want, got, pattern)
+	}
+}
+
+func TestCheckValueFacets(t *testing.T) {
+	facets := &validation.Facets{MinInclusive: "1", MaxExclusive: "5", Pattern: `\d`}
+	var errs validation.Errors
+	validation.CheckValue("/q", 3, facets, false, &errs)
+	assert.Empty(t, errs)
+
+	validation.CheckValue("/q", 5, facets, false, &errs)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "/q: value 5 violates maxExclusive 5", errs[0].Error())
+}
+
+func TestCheckValueOptionalAndPointers(t *testing.T) {
+	facets := &validation.Facets{MinLength: "2"}
+	var errs validation.Errors
+	validation.CheckValue("/a", "", facets, true, &errs)
+	validation.CheckValue("/b", (*xsdtypes.Date)(nil), facets, true, &errs)
+	assert.Empty(t, errs)
+
+	d, err := xsdtypes.ParseDate("2024-01-01")
+	require.NoError(t, err)
+	validation.CheckValue("/c", &d, &validation.Facets{MaxInclusive: "2023-12-31"}, true, &errs)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "/c", errs[0].Path)
+}
+
+func TestCheckValueLengthOfBinary(t *testing.T) {
+	var errs validation.Errors
+	validation.CheckValue("/h", xsdtypes.HexBinary{0xCA, 0xFE}, &validation.Facets{Length: "2"}, false, &errs)
+	assert.Empty(t, errs)
+}
+
+func TestDigits(t *testing.T) {
+	total, fraction, ok := validation.Digits("-0012.3400")
+	require.True(t, ok)
+	assert.Equal(t, 4, total)
+	assert.Equal(t, 2, fraction)
+}
+
+func TestCheckOccursAndChoice(t *testing.T) {
+	var errs validation.Errors
+	validation.CheckOccurs("/item", 0, 1, validation.Unbounded, &errs)
+	validation.CheckOccurs("/item", 4, 0, 3, &errs)
+	validation.CheckChoice("/contact", true, &errs,
+		validation.Branch{Name: "email", Value: "a@b.c"},
+		validation.Branch{Name: "phone", Value: "555"},
+	)
+	validation.CheckChoice("/contact", true, &errs, validation.Branch{Name: "email", Value: ""})
+	assert.Len(t, errs, 4)
+	assert.Nil(t, validation.Errors{}.Err())
+}
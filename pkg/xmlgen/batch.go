@@ -0,0 +1,228 @@
+package xmlgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+)
+
+// DefaultBatchName is the template of the file names of batch documents: the local name of their root
+// followed by their number, such as "order-42.xml".
+const DefaultBatchName = "{{.Root}}-{{.N}}.xml"
+
+// Batch configures the generation of many documents with Generator.Batch.
+type Batch struct {
+	// Count is the number of documents.
+	Count int
+	// Workers is the number of documents generated at once, runtime.GOMAXPROCS(0) when zero. It does not
+	// change the documents.
+	Workers int
+	// Name is a text/template of the file names of the documents, DefaultBatchName when empty. It gets
+	// the fields N, the number of the document from 1, Root, the local name of its root, and Seed. Names
+	// are relative slash-separated paths, which must be distinct.
+	Name string
+	// Roots are the global elements of the documents, in turn, the root of the Generator when empty.
+	Roots []Root
+}
+
+// BatchDocument describes a document of a batch.
+type BatchDocument struct {
+	Name string `json:"file"`
+	// Seed generates the document alone, with WithSeed, WithRoot(Root) and the other options of the batch.
+	Seed uint64 `json:"seed"`
+	// Root is the QName of the root element, as accepted by WithRoot.
+	Root string `json:"root"`
+	Size int    `json:"size"`
+
+	root Root
+}
+
+// Manifest lists the documents of a batch, in order.
+type Manifest struct {
+	Seed      uint64          `json:"seed"`
+	Documents []BatchDocument `json:"documents"`
+}
+
+// WriteTo writes the manifest as indented JSON.
+func (m *Manifest) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(data, '\n'))
+	return int64(n), err
+}
+
+// DocumentSeed derives the seed of document i, from 0, of a batch generated from seed. Seeds of
+// consecutive documents are unrelated, so that documents do not repeat each other.
+func DocumentSeed(seed uint64, i int) uint64 {
+	// splitmix64 of the i-th step from seed
+	z := seed + uint64(i+1)*0x9e3779b97f4a7c15 //nolint:gosec // i is a non-negative index
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+// Batch generates b.Count documents with the options of g, each from its own seed derived from the seed
+// of g with DocumentSeed, in parallel. The documents are handed to emit in order from a single
+// goroutine, written as WriteTo writes them, so that the batch does not depend on the number of workers;
+// at most twice as many documents as workers are held in memory at once, which rules out very large
+// documents such as those of WithTargetSize. Batch stops at the first error, of a document or of emit,
+// and returns the manifest of the documents.
+//
+// Generators shared by the documents, such as plugins, are called concurrently, and a value generator
+// carrying its own Rand is rejected, since documents draw from their own seeds.
+func (g *Generator) Batch(ctx context.Context, b *Batch, emit func(d *BatchDocument, data []byte) error) (*Manifest, error) {
+	if helpers.RandOf(g.opts.values) != nil {
+		return nil, errors.New("batch documents draw from their own seeds, which a value generator carrying its Rand prevents")
+	}
+	docs, err := g.batchDocuments(b)
+	if err != nil {
+		return nil, err
+	}
+	workers := b.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = max(min(workers, len(docs)), 1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		i    int
+		data []byte
+		err  error
+	}
+	jobs := make(chan int)
+	results := make(chan result, workers)
+	window := make(chan struct{}, 2*workers) // documents generated but not emitted yet
+	go func() {
+		defer close(jobs)
+		for i := range docs {
+			select {
+			case window <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				var buf bytes.Buffer
+				_, err := g.reseeded(docs[i].Seed, docs[i].root).write(ctx, &buf)
+				results <- result{i: i, data: buf.Bytes(), err: err}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var first error
+	pending := map[int]result{}
+	next := 0
+	for r := range results {
+		if first != nil {
+			continue
+		}
+		pending[r.i] = r
+		for done, ok := pending[next]; ok && first == nil; done, ok = pending[next] {
+			delete(pending, next)
+			d := &docs[next]
+			if done.err != nil {
+				first = fmt.Errorf("%s: %w", d.Name, done.err)
+			} else {
+				d.Size = len(done.data)
+				first = emit(d, done.data)
+			}
+			next++
+			<-window
+		}
+		if first != nil {
+			cancel()
+		}
+	}
+	if first == nil {
+		first = ctx.Err()
+	}
+	if first != nil {
+		return nil, first
+	}
+	return &Manifest{Seed: g.opts.seed, Documents: docs}, nil
+}
+
+// batchDocuments names the documents of b and derives their seeds.
+func (g *Generator) batchDocuments(b *Batch) ([]BatchDocument, error) {
+	if b.Count <= 0 {
+		return nil, fmt.Errorf("invalid batch count %d", b.Count)
+	}
+	name := b.Name
+	if name == "" {
+		name = DefaultBatchName
+	}
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(name)
+	if err != nil {
+		return nil, fmt.Errorf("parse name template: %w", err)
+	}
+	roots := b.Roots
+	if len(roots) == 0 {
+		root, err := FindRoot(g.schema, g.opts.root)
+		if err != nil {
+			return nil, err
+		}
+		roots = []Root{root}
+	}
+	docs := make([]BatchDocument, b.Count)
+	names := map[string]int{}
+	for i := range docs {
+		root := roots[i%len(roots)]
+		seed := DocumentSeed(g.opts.seed, i)
+		var buf strings.Builder
+		fields := struct {
+			N    int
+			Root string
+			Seed uint64
+		}{i + 1, root.Element.Name, seed}
+		if err := tmpl.Execute(&buf, fields); err != nil {
+			return nil, fmt.Errorf("name template: %w", err)
+		}
+		file := buf.String()
+		if !filepath.IsLocal(filepath.FromSlash(file)) {
+			return nil, fmt.Errorf("name template: %q is not a relative path inside the output", file)
+		}
+		if j, ok := names[file]; ok {
+			return nil, fmt.Errorf("name template: documents %d and %d are both named %s", j+1, i+1, file)
+		}
+		names[file] = i
+		docs[i] = BatchDocument{Name: file, Seed: seed, Root: root.QName(g.schema), root: root}
+	}
+	return docs, nil
+}
+
+// reseeded returns a generator of the documents of root from seed, sharing the schema, its sizes and the
+// other options of g.
+func (g *Generator) reseeded(seed uint64, root Root) *Generator {
+	gen := *g
+	gen.opts.seed, gen.opts.root = seed, "{"+root.Namespace+"}"+root.Element.Name
+	return &gen
+}
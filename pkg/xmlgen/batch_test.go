@@ -0,0 +1,114 @@
+package xmlgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/semantic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batch generates a batch and returns its manifest and documents, in the order they were emitted.
+func batch(t *testing.T, g *Generator, b *Batch) (*Manifest, []string) {
+	t.Helper()
+	var docs []string
+	manifest, err := g.Batch(context.Background(), b, func(d *BatchDocument, data []byte) error {
+		docs = append(docs, d.Name+"\n"+string(data))
+		return nil
+	})
+	require.NoError(t, err)
+	return manifest, docs
+}
+
+func TestBatchDoesNotDependOnWorkers(t *testing.T) {
+	schema := testSchema(t, "order.xsd")
+	names := WithSemanticValues(semantic.NewRegistry()) // shared by the workers
+	manifest, want := batch(t, New(schema, WithSeed(5), names), &Batch{Count: 40, Workers: 1})
+	require.Len(t, manifest.Documents, 40)
+	assert.Equal(t, uint64(5), manifest.Seed)
+	for _, workers := range []int{0, 3, 16, 100} {
+		_, docs := batch(t, New(schema, WithSeed(5), names), &Batch{Count: 40, Workers: workers})
+		assert.Equal(t, want, docs, "%d workers", workers)
+	}
+
+	seeds := map[uint64]bool{}
+	for i, d := range manifest.Documents {
+		assert.Equal(t, fmt.Sprintf("order-%d.xml", i+1), d.Name)
+		assert.Equal(t, "order", d.Root)
+		assert.Equal(t, DocumentSeed(5, i), d.Seed)
+		seeds[d.Seed] = true
+		alone := write(t, New(schema, WithSeed(d.Seed), WithRoot(d.Root), names))
+		assert.Equal(t, d.Name+"\n"+alone, want[i], "the seed of a document generates it alone")
+		assert.Equal(t, len(alone), d.Size)
+	}
+	assert.Len(t, seeds, 40)
+}
+
+func TestBatchRootsAndNames(t *testing.T) {
+	schema := testSchema(t, "shapes.xsd")
+	roots := Roots(schema)
+	require.Greater(t, len(roots), 1)
+	manifest, _ := batch(t, New(schema, WithSeed(1)), &Batch{
+		Count: 2 * len(roots),
+		Roots: roots,
+		Name:  `{{.Root}}/{{printf "%03d" .N}}-{{.Seed}}.xml`,
+	})
+	for i, d := range manifest.Documents {
+		root := roots[i%len(roots)]
+		assert.Equal(t, root.QName(schema), d.Root)
+		assert.Equal(t, fmt.Sprintf("%s/%03d-%d.xml", root.Element.Name, i+1, d.Seed), d.Name)
+	}
+
+	var buf bytes.Buffer
+	_, err := manifest.WriteTo(&buf)
+	require.NoError(t, err)
+	var read Manifest
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &read))
+	assert.Equal(t, manifest.Documents[3].Name, read.Documents[3].Name)
+	assert.Equal(t, manifest.Documents[3].Seed, read.Documents[3].Seed)
+	assert.Contains(t, buf.String(), `"file": "`)
+}
+
+func TestBatchFailures(t *testing.T) {
+	g := New(testSchema(t, "order.xsd"))
+	emit := func(*BatchDocument, []byte) error { return nil }
+	for _, tc := range []struct {
+		batch Batch
+		err   string
+	}{
+		{Batch{Count: 0}, "invalid batch count 0"},
+		{Batch{Count: 2, Name: "order.xml"}, "documents 1 and 2 are both named order.xml"},
+		{Batch{Count: 1, Name: "../{{.Root}}.xml"}, `"../order.xml" is not a relative path`},
+		{Batch{Count: 1, Name: "{{.Missing}}"}, "name template"},
+		{Batch{Count: 1, Name: "{{"}, "parse name template"},
+	} {
+		_, err := g.Batch(context.Background(), &tc.batch, emit)
+		assert.ErrorContains(t, err, tc.err)
+	}
+
+	full := errors.New("disk full")
+	emitted := 0
+	_, err := g.Batch(context.Background(), &Batch{Count: 1000, Workers: 4}, func(*BatchDocument, []byte) error {
+		if emitted++; emitted == 10 {
+			return full
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, full)
+	assert.Equal(t, 10, emitted, "the batch stops at the first error")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = g.Batch(ctx, &Batch{Count: 10}, emit)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	seeded := New(testSchema(t, "order.xsd"), WithValueGenerator(helpers.DefaultValueGenerator{Source: helpers.NewRand(1)}))
+	_, err = seeded.Batch(context.Background(), &Batch{Count: 1}, emit)
+	assert.ErrorContains(t, err, "value generator carrying its Rand")
+}
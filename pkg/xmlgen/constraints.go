@@ -0,0 +1,567 @@
+package xmlgen
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/beevik/etree"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConstraintAttempts is the number of documents Generate tries before giving up on constraints.
+const DefaultConstraintAttempts = 100
+
+// Constraints are business rules the schema cannot express, applied to the generated documents. They are
+// usually loaded from a YAML or JSON file with LoadConstraints:
+//
+//	# shipped after ordered, billTo equals shipTo 30% of the time, and consistent totals
+//	- compare: /purchaseOrder/shipDate >= /purchaseOrder/@orderDate
+//	- copy: /purchaseOrder/billTo
+//	  to: /purchaseOrder/shipTo
+//	  probability: 0.3
+//	- each: /purchaseOrder/items/item
+//	  derive: lineTotal
+//	  from: quantity * USPrice
+//	- derive: /purchaseOrder/total
+//	  from: sum(/purchaseOrder/items/item/quantity * /purchaseOrder/items/item/USPrice)
+//
+// Paths are written as in Rules. Relative paths start from the element given by Each, or else from the
+// document. Expressions combine paths, numbers and quoted strings with +, -, * and /, which must be
+// separated from paths by spaces since paths may contain them, parentheses and the functions sum,
+// count, min and max. Operators apply to the values of the nodes of a path pairwise, or to each of them
+// for single values, so that quantity * USPrice multiplies the quantity and price of each item.
+//
+// Once a document is generated, copies and derivations are applied in order, then every comparison is
+// checked. A document breaking a comparison is generated again, up to DefaultConstraintAttempts times.
+type Constraints []Constraint
+
+// Constraint is a copy, a derivation or a comparison.
+type Constraint struct {
+	// Each applies the constraint once per element matching it, from which relative paths start.
+	Each string `json:"each" yaml:"each"`
+	// Copy copies the value, or the whole content, of the first node matching it to the nodes matching To.
+	Copy string `json:"copy" yaml:"copy"`
+	To   string `json:"to" yaml:"to"`
+	// Derive sets the nodes matching it to the values of the expression From: every node to a single
+	// value, or each node to the value of the same rank.
+	Derive string `json:"derive" yaml:"derive"`
+	From   string `json:"from" yaml:"from"`
+	// Compare is a comparison of two expressions with ==, !=, <, <=, > or >=. Numbers, dates and
+	// dateTimes compare by value, other values as strings. A comparison holds when every pair of values
+	// does, and so when a path matches no node.
+	Compare string `json:"compare" yaml:"compare"`
+	// Probability applies a copy or a derivation only that often, between 0 and 1; 0 means always.
+	Probability float64 `json:"probability" yaml:"probability"`
+}
+
+// ConstraintError reports a comparison that no generated document satisfied.
+type ConstraintError struct {
+	Compare  string
+	Attempts int
+}
+
+// Error implements the error interface.
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("constraint %q fails on each of the %d documents generated", e.Compare, e.Attempts)
+}
+
+// LoadConstraints reads constraints from a YAML file. JSON being a subset of YAML, JSON files are
+// accepted too.
+func LoadConstraints(path string) (Constraints, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is given by the user on the command line
+	if err != nil {
+		return nil, fmt.Errorf("read constraints: %w", err)
+	}
+	var constraints Constraints
+	if err := yaml.Unmarshal(data, &constraints); err != nil {
+		return nil, fmt.Errorf("parse constraints %s: %w", path, err)
+	}
+	if _, err := compileConstraints(constraints); err != nil {
+		return nil, fmt.Errorf("constraints %s: %w", path, err)
+	}
+	return constraints, nil
+}
+
+// constrained generates documents of root with w until one satisfies the constraints.
+func (g *Generator) constrained(w *walker, root Root) (*etree.Document, error) {
+	constraints, err := compileConstraints(g.opts.constraints)
+	if err != nil {
+		return nil, err
+	}
+	var failed *constraint
+	for range g.opts.attempts {
+		attempt := *w
+		doc, err := g.document(&attempt, root)
+		if err != nil {
+			return nil, err
+		}
+		if failed, err = constraints.apply(doc, w.rand); err != nil {
+			return nil, err
+		}
+		if failed == nil {
+			return doc, nil
+		}
+	}
+	return nil, &ConstraintError{Compare: failed.Compare, Attempts: g.opts.attempts}
+}
+
+// constraint is a compiled Constraint.
+type constraint struct {
+	Constraint
+	each, source, target *pathExpr
+	expr                 expr // From, or Compare
+}
+
+type constraintSet []*constraint
+
+func compileConstraints(constraints Constraints) (constraintSet, error) {
+	var set constraintSet
+	for i, c := range constraints {
+		compiled, err := compileConstraint(c)
+		if err != nil {
+			return nil, fmt.Errorf("constraint %d: %w", i+1, err)
+		}
+		set = append(set, compiled)
+	}
+	return set, nil
+}
+
+func compileConstraint(c Constraint) (*constraint, error) {
+	compiled := &constraint{Constraint: c}
+	var err error
+	if c.Each != "" {
+		if compiled.each, err = parsePathExpr(c.Each); err != nil {
+			return nil, err
+		}
+	}
+	if c.Probability < 0 || c.Probability > 1 {
+		return nil, fmt.Errorf("probability %v is not between 0 and 1", c.Probability)
+	}
+	switch {
+	case c.Copy != "" && c.Derive == "" && c.Compare == "":
+		if c.To == "" || c.From != "" {
+			return nil, errors.New("copy takes a to path")
+		}
+		if compiled.source, err = parsePathExpr(c.Copy); err != nil {
+			return nil, err
+		}
+		compiled.target, err = parsePathExpr(c.To)
+	case c.Derive != "" && c.Copy == "" && c.Compare == "":
+		if c.From == "" || c.To != "" {
+			return nil, errors.New("derive takes a from expression")
+		}
+		if compiled.target, err = parsePathExpr(c.Derive); err != nil {
+			return nil, err
+		}
+		compiled.expr, err = parseExpr(c.From, false)
+	case c.Compare != "" && c.Copy == "" && c.Derive == "":
+		if c.To != "" || c.From != "" || c.Probability != 0 {
+			return nil, errors.New("compare takes neither to, from nor probability")
+		}
+		compiled.expr, err = parseExpr(c.Compare, true)
+	default:
+		return nil, errors.New("set one of copy, derive and compare")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return compiled, nil
+}
+
+// apply applies the copies and derivations to doc, then returns the first comparison doc breaks.
+func (s constraintSet) apply(doc *etree.Document, rand *helpers.Rand) (*constraint, error) {
+	for _, c := range s {
+		if c.Compare != "" {
+			continue
+		}
+		for _, context := range c.contexts(doc) {
+			if c.Probability > 0 && rand.Float64() >= c.Probability {
+				continue
+			}
+			if err := c.change(doc, context); err != nil {
+				return nil, fmt.Errorf("constraint %s: %w", c.name(), err)
+			}
+		}
+	}
+	for _, c := range s {
+		if c.Compare == "" {
+			continue
+		}
+		for _, context := range c.contexts(doc) {
+			values, err := c.expr.eval(doc, context)
+			if err != nil {
+				return nil, fmt.Errorf("constraint %s: %w", c.name(), err)
+			}
+			if len(values) == 0 {
+				return c, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// name identifies the constraint in errors.
+func (c *constraint) name() string {
+	switch {
+	case c.Copy != "":
+		return "copy " + c.Copy + " to " + c.To
+	case c.Derive != "":
+		return "derive " + c.Derive + " from " + c.From
+	}
+	return c.Compare
+}
+
+// contexts returns the elements matching Each, or the document, nil, without Each.
+func (c *constraint) contexts(doc *etree.Document) []*etree.Element {
+	if c.each == nil {
+		return []*etree.Element{nil}
+	}
+	var elements []*etree.Element
+	for _, n := range c.each.nodes(doc, nil) {
+		if n.attr == "" {
+			elements = append(elements, n.elem)
+		}
+	}
+	return elements
+}
+
+// change applies a copy or a derivation from context.
+func (c *constraint) change(doc *etree.Document, context *etree.Element) error {
+	targets := c.target.nodes(doc, context)
+	if c.Copy != "" {
+		sources := c.source.nodes(doc, context)
+		if len(sources) == 0 {
+			return nil
+		}
+		for _, t := range targets {
+			copyNode(sources[0], t)
+		}
+		return nil
+	}
+	values, err := c.expr.eval(doc, context)
+	if err != nil {
+		return err
+	}
+	switch {
+	case len(values) == 1:
+		for _, t := range targets {
+			t.set(values[0])
+		}
+	case len(values) == len(targets):
+		for i, t := range targets {
+			t.set(values[i])
+		}
+	case len(targets) > 0:
+		return fmt.Errorf("%d values for %d nodes", len(values), len(targets))
+	}
+	return nil
+}
+
+// node is an element or, when attr is set, one of its attributes.
+type node struct {
+	elem *etree.Element
+	attr string // full key of the attribute
+}
+
+func (n node) text() string {
+	if n.attr != "" {
+		return n.elem.SelectAttrValue(n.attr, "")
+	}
+	return n.elem.Text()
+}
+
+func (n node) set(value string) {
+	if n.attr != "" {
+		n.elem.CreateAttr(n.attr, value)
+		return
+	}
+	n.elem.SetText(value)
+}
+
+// copyNode copies the value of src to dst, or its attributes and children when both are elements. The
+// namespace declarations of dst are kept.
+func copyNode(src, dst node) {
+	if src.attr != "" || dst.attr != "" {
+		dst.set(src.text())
+		return
+	}
+	if src.elem == dst.elem {
+		return
+	}
+	cp := src.elem.Copy()
+	for _, a := range slices.Clone(dst.elem.Attr) {
+		if !isNamespaceDeclaration(a) {
+			dst.elem.RemoveAttr(a.FullKey())
+		}
+	}
+	for _, a := range cp.Attr {
+		if !isNamespaceDeclaration(a) {
+			dst.elem.CreateAttr(a.FullKey(), a.Value)
+		}
+	}
+	for _, t := range slices.Clone(dst.elem.Child) {
+		dst.elem.RemoveChild(t)
+	}
+	for _, t := range slices.Clone(cp.Child) {
+		dst.elem.AddChild(t)
+	}
+}
+
+func isNamespaceDeclaration(a etree.Attr) bool {
+	return a.Space == "xmlns" || a.Space == "" && a.Key == "xmlns"
+}
+
+// pathExpr is a path of a constraint.
+type pathExpr struct {
+	steps    pathPattern
+	absolute bool
+}
+
+func parsePathExpr(p string) (*pathExpr, error) {
+	steps, err := parsePath(p)
+	if err != nil {
+		return nil, err
+	}
+	return &pathExpr{steps: steps, absolute: strings.HasPrefix(p, "/")}, nil
+}
+
+// nodes returns the nodes of doc matching the path from context, in document order. Relative paths
+// start from the document when context is nil.
+func (p *pathExpr) nodes(doc *etree.Document, context *etree.Element) []node {
+	var found []node
+	attributes := func(e *etree.Element, states []bool) {
+		for _, a := range e.Attr {
+			if !isNamespaceDeclaration(a) && p.steps.accepts(p.steps.next(states, "@"+a.Key)) {
+				found = append(found, node{elem: e, attr: a.FullKey()})
+			}
+		}
+	}
+	var visit func(e *etree.Element, states []bool)
+	visit = func(e *etree.Element, states []bool) {
+		states = p.steps.next(states, e.Tag)
+		if !slices.Contains(states, true) {
+			return
+		}
+		if p.steps.accepts(states) {
+			found = append(found, node{elem: e})
+		}
+		attributes(e, states)
+		for _, c := range e.ChildElements() {
+			visit(c, states)
+		}
+	}
+	if context == nil || p.absolute {
+		if root := doc.Root(); root != nil {
+			visit(root, p.steps.start())
+		}
+		return found
+	}
+	start := p.steps.start()
+	if p.steps.accepts(start) {
+		found = append(found, node{elem: context})
+	}
+	attributes(context, start)
+	for _, c := range context.ChildElements() {
+		visit(c, start)
+	}
+	return found
+}
+
+// expr is an expression of a constraint. It evaluates to a sequence of values; a comparison evaluates
+// to a single "true" when it holds and to no value otherwise.
+type expr interface {
+	eval(doc *etree.Document, context *etree.Element) ([]string, error)
+}
+
+type literal string
+
+func (l literal) eval(*etree.Document, *etree.Element) ([]string, error) {
+	return []string{string(l)}, nil
+}
+
+func (p *pathExpr) eval(doc *etree.Document, context *etree.Element) ([]string, error) {
+	nodes := p.nodes(doc, context)
+	values := make([]string, len(nodes))
+	for i, n := range nodes {
+		values[i] = strings.TrimSpace(n.text())
+	}
+	return values, nil
+}
+
+type binary struct {
+	op          string
+	left, right expr
+}
+
+func (b *binary) eval(doc *etree.Document, context *etree.Element) ([]string, error) {
+	left, err := b.left.eval(doc, context)
+	if err != nil {
+		return nil, err
+	}
+	right, err := b.right.eval(doc, context)
+	if err != nil {
+		return nil, err
+	}
+	n := max(len(left), len(right))
+	switch {
+	case len(left) == 0 || len(right) == 0:
+		n = 0
+	case len(left) != len(right) && len(left) != 1 && len(right) != 1:
+		return nil, fmt.Errorf("operands of %s have %d and %d values", b.op, len(left), len(right))
+	}
+	var values []string
+	holds := true
+	for i := range n {
+		l, r := left[min(i, len(left)-1)], right[min(i, len(right)-1)]
+		if comparisons[b.op] != nil {
+			holds = holds && comparisons[b.op](compareValues(l, r))
+			continue
+		}
+		v, err := arithmetic(b.op, l, r)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	if comparisons[b.op] == nil {
+		return values, nil
+	}
+	if !holds {
+		return nil, nil
+	}
+	return []string{"true"}, nil
+}
+
+var comparisons = map[string]func(int) bool{
+	"==": func(c int) bool { return c == 0 },
+	"!=": func(c int) bool { return c != 0 },
+	"<":  func(c int) bool { return c < 0 },
+	"<=": func(c int) bool { return c <= 0 },
+	">":  func(c int) bool { return c > 0 },
+	">=": func(c int) bool { return c >= 0 },
+}
+
+// arithmetic applies op to two numbers. Sums keep the fraction digits of the more precise operand,
+// products the fraction digits of both, and quotients at least two.
+func arithmetic(op, left, right string) (string, error) {
+	x, ok := new(big.Rat).SetString(left)
+	if !ok {
+		return "", fmt.Errorf("%q is not a number", left)
+	}
+	y, ok := new(big.Rat).SetString(right)
+	if !ok {
+		return "", fmt.Errorf("%q is not a number", right)
+	}
+	scale := max(fractionLength(left), fractionLength(right))
+	v := new(big.Rat)
+	switch op {
+	case "+":
+		v.Add(x, y)
+	case "-":
+		v.Sub(x, y)
+	case "*":
+		v.Mul(x, y)
+		scale = fractionLength(left) + fractionLength(right)
+	case "/":
+		if y.Sign() == 0 {
+			return "", fmt.Errorf("%s / %s divides by zero", left, right)
+		}
+		v.Quo(x, y)
+		scale = max(scale, 2)
+	}
+	if scale == 0 {
+		return v.Num().String(), nil
+	}
+	return v.FloatString(scale), nil
+}
+
+// fractionLength returns the number of digits written after the decimal point of a number.
+func fractionLength(number string) int {
+	if i := strings.IndexByte(number, '.'); i >= 0 {
+		return len(number) - i - 1
+	}
+	return 0
+}
+
+// timeLayouts are the lexical forms of the dates and times compared by value.
+var timeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02Z07:00", "2006-01-02", "15:04:05"}
+
+// compareValues compares numbers and dates by value, and other values as strings.
+func compareValues(a, b string) int {
+	if x, ok := new(big.Rat).SetString(a); ok {
+		if y, ok := new(big.Rat).SetString(b); ok {
+			return x.Cmp(y)
+		}
+	}
+	for _, layout := range timeLayouts {
+		x, errA := time.Parse(layout, a)
+		y, errB := time.Parse(layout, b)
+		if errA == nil && errB == nil {
+			return x.Compare(y)
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+type negation struct {
+	operand expr
+}
+
+func (n *negation) eval(doc *etree.Document, context *etree.Element) ([]string, error) {
+	values, err := n.operand.eval(doc, context)
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range values {
+		if values[i], err = arithmetic("-", "0", v); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+type call struct {
+	function string
+	args     []expr
+}
+
+func (c *call) eval(doc *etree.Document, context *etree.Element) ([]string, error) {
+	var values []string
+	for _, a := range c.args {
+		v, err := a.eval(doc, context)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v...)
+	}
+	switch c.function {
+	case "count":
+		return []string{strconv.Itoa(len(values))}, nil
+	case "sum":
+		total := "0"
+		for _, v := range values {
+			var err error
+			if total, err = arithmetic("+", total, v); err != nil {
+				return nil, err
+			}
+		}
+		return []string{total}, nil
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	best := values[0]
+	for _, v := range values[1:] {
+		if order := compareValues(v, best); order < 0 && c.function == "min" || order > 0 && c.function == "max" {
+			best = v
+		}
+	}
+	return []string{best}, nil
+}
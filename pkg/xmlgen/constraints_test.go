@@ -0,0 +1,103 @@
+package xmlgen
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/beevik/etree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstraintsHoldOnGeneratedDocuments(t *testing.T) {
+	schema := testSchema(t, "invoice.xsd")
+	constraints, err := LoadConstraints(filepath.Join("testdata", "constraints.yaml"))
+	require.NoError(t, err)
+
+	copies := 0
+	const documents = 100
+	for seed := range uint64(documents) {
+		doc, err := New(schema, WithSeed(seed), WithConstraints(constraints)).Generate(context.Background())
+		require.NoError(t, err)
+		invoice := doc.Root()
+		assert.GreaterOrEqual(t, invoice.SelectAttrValue("shipDate", ""), invoice.SelectAttrValue("orderDate", ""))
+
+		total := new(big.Rat)
+		for _, item := range invoice.SelectElements("item") {
+			quantity := rat(t, item.SelectElement("quantity").Text())
+			price := rat(t, item.SelectElement("price").Text())
+			lineTotal := rat(t, item.SelectElement("lineTotal").Text())
+			assert.Zero(t, new(big.Rat).Mul(quantity, price).Cmp(lineTotal), "lineTotal of seed %d", seed)
+			total.Add(total, lineTotal)
+		}
+		assert.Zero(t, total.Cmp(rat(t, invoice.SelectElement("total").Text())), "total of seed %d", seed)
+
+		billTo, shipTo := etree.NewDocumentWithRoot(invoice.SelectElement("billTo").Copy()),
+			etree.NewDocumentWithRoot(invoice.SelectElement("shipTo").Copy())
+		billTo.Root().Tag, shipTo.Root().Tag = "address", "address"
+		a, _ := billTo.WriteToString()
+		b, _ := shipTo.WriteToString()
+		if a == b {
+			copies++
+		}
+	}
+	assert.InDelta(t, 0.3, float64(copies)/documents, 0.15, "shipTo copies billTo 30%% of the time")
+}
+
+func rat(t *testing.T, s string) *big.Rat {
+	t.Helper()
+	v, ok := new(big.Rat).SetString(s)
+	require.True(t, ok, "%q is not a number", s)
+	return v
+}
+
+func TestConstraintsFailAfterBoundedAttempts(t *testing.T) {
+	gen := New(testSchema(t, "invoice.xsd"), WithSeed(1), WithConstraintAttempts(5), WithConstraints(Constraints{
+		{Compare: "count(/invoice/item) > 3"},
+	}))
+	_, err := gen.Generate(context.Background())
+	var constraintErr *ConstraintError
+	require.ErrorAs(t, err, &constraintErr)
+	assert.Equal(t, 5, constraintErr.Attempts)
+}
+
+func TestConstraintExpressions(t *testing.T) {
+	doc := etree.NewDocument()
+	require.NoError(t, doc.ReadFromString(`<order date="2024-03-01"><line><q>2</q><p>1.50</p></line>`+
+		`<line><q>3</q><p>0.25</p></line><shipped>2024-02-29</shipped></order>`))
+	cases := map[string][]string{
+		"sum(/order/line/q * /order/line/p)": {"3.75"},
+		"/order/line/q * 2":                  {"4", "6"},
+		"-(/order/line/q + 1) / 2":           {"-1.50", "-2.00"},
+		"count(//q)":                         {"2"},
+		"max(//p, 1)":                        {"1.50"},
+		"min(/order/@date, //shipped)":       {"2024-02-29"},
+		"'text'":                             {"text"},
+		"/order/shipped < /order/@date":      {"true"},
+		"/order/line/q == 2":                 nil,
+		"/order/missing == 2":                {"true"},
+	}
+	for src, want := range cases {
+		e, err := parseExpr(src, false)
+		if err != nil {
+			e, err = parseExpr(src, true)
+		}
+		require.NoError(t, err, src)
+		got, err := e.eval(doc, nil)
+		require.NoError(t, err, src)
+		assert.Equal(t, want, got, src)
+	}
+
+	for _, src := range []string{"", "1 +", "avg(//q)", "(1", "'open", "1 2"} {
+		_, err := parseExpr(src, false)
+		assert.Error(t, err, src)
+	}
+	_, err := parseExpr("/order/line/q", true)
+	assert.Error(t, err, "not a comparison")
+	e, err := parseExpr("/order/line/q * /order/@date", false)
+	require.NoError(t, err)
+	_, err = e.eval(doc, nil)
+	assert.ErrorContains(t, err, `"2024-03-01" is not a number`)
+}
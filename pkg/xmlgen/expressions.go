@@ -0,0 +1,188 @@
+package xmlgen
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// functions are the functions of constraint expressions.
+var functions = map[string]bool{"sum": true, "count": true, "min": true, "max": true}
+
+// exprParser reads a constraint expression:
+//
+//	comparison := sum [("==" | "!=" | "<" | "<=" | ">" | ">=") sum]
+//	sum        := product {("+" | "-") product}
+//	product    := unary {("*" | "/") unary}
+//	unary      := "-" unary | number | string | path | function "(" [sum {"," sum}] ")" | "(" sum ")"
+type exprParser struct {
+	src string
+	pos int
+}
+
+// parseExpr parses an expression, which is a comparison when comparison is set.
+func parseExpr(src string, comparison bool) (expr, error) {
+	p := &exprParser{src: src}
+	e, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	if op := p.comparison(); op != "" {
+		if !comparison {
+			return nil, fmt.Errorf("%s: unexpected comparison %s", src, op)
+		}
+		right, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		e = &binary{op: op, left: e, right: right}
+	} else if comparison {
+		return nil, fmt.Errorf("%s: not a comparison", src)
+	}
+	if p.skipSpaces(); p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos:])
+	}
+	return e, nil
+}
+
+func (p *exprParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%s: at %d: %s", p.src, p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *exprParser) skipSpaces() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// operator consumes and returns the first of ops found at the current position, or "".
+func (p *exprParser) operator(ops ...string) string {
+	p.skipSpaces()
+	for _, op := range ops {
+		if strings.HasPrefix(p.src[p.pos:], op) {
+			p.pos += len(op)
+			return op
+		}
+	}
+	return ""
+}
+
+func (p *exprParser) comparison() string {
+	op := p.operator("==", "!=", "<=", ">=", "<", ">", "=")
+	if op == "=" {
+		return "=="
+	}
+	return op
+}
+
+func (p *exprParser) sum() (expr, error) {
+	e, err := p.product()
+	for err == nil {
+		op := p.operator("+", "-")
+		if op == "" {
+			return e, nil
+		}
+		var right expr
+		if right, err = p.product(); err == nil {
+			e = &binary{op: op, left: e, right: right}
+		}
+	}
+	return nil, err
+}
+
+func (p *exprParser) product() (expr, error) {
+	e, err := p.unary()
+	for err == nil {
+		op := p.operator("*", "/")
+		if op == "" {
+			return e, nil
+		}
+		var right expr
+		if right, err = p.unary(); err == nil {
+			e = &binary{op: op, left: e, right: right}
+		}
+	}
+	return nil, err
+}
+
+func (p *exprParser) unary() (expr, error) {
+	p.skipSpaces()
+	if p.pos == len(p.src) {
+		return nil, p.errorf("missing operand")
+	}
+	switch c := p.src[p.pos]; {
+	case c == '-':
+		p.pos++
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &negation{operand: operand}, nil
+	case c == '(':
+		p.pos++
+		e, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		if p.operator(")") == "" {
+			return nil, p.errorf("missing )")
+		}
+		return e, nil
+	case c == '\'' || c == '"':
+		end := strings.IndexByte(p.src[p.pos+1:], c)
+		if end < 0 {
+			return nil, p.errorf("unterminated string")
+		}
+		s := p.src[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return literal(s), nil
+	case c >= '0' && c <= '9':
+		start := p.pos
+		for p.pos < len(p.src) && (p.src[p.pos] >= '0' && p.src[p.pos] <= '9' || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		return literal(p.src[start:p.pos]), nil
+	}
+	return p.pathOrCall()
+}
+
+// pathOrCall reads a path, which extends to the next space, parenthesis, comma, comparison or plus
+// sign, or a function call.
+func (p *exprParser) pathOrCall() (expr, error) {
+	start := p.pos
+	for p.pos < len(p.src) && !unicode.IsSpace(rune(p.src[p.pos])) && !strings.ContainsRune("(),=!<>+", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	word := p.src[start:p.pos]
+	if word == "" {
+		return nil, p.errorf("unexpected %q", p.src[p.pos:])
+	}
+	if p.pos < len(p.src) && p.src[p.pos] == '(' {
+		if !functions[word] {
+			return nil, p.errorf("unknown function %s", word)
+		}
+		p.pos++
+		c := &call{function: word}
+		if p.operator(")") != "" {
+			return c, nil
+		}
+		for {
+			arg, err := p.sum()
+			if err != nil {
+				return nil, err
+			}
+			c.args = append(c.args, arg)
+			switch p.operator(",", ")") {
+			case ")":
+				return c, nil
+			case "":
+				return nil, p.errorf("missing )")
+			}
+		}
+	}
+	path, err := parsePathExpr(word)
+	if err != nil {
+		return nil, p.errorf("%v", err)
+	}
+	return path, nil
+}
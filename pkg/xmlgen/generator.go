@@ -0,0 +1,425 @@
+package xmlgen
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/semantic"
+	"github.com/beevik/etree"
+)
+
+// Unbounded is the maximum number of occurrences handed to an OccurrencePolicy for maxOccurs="unbounded".
+const Unbounded = -1
+
+// defaultUnboundedLimit is the largest number of occurrences of unbounded particles generated by default.
+const defaultUnboundedLimit = 3
+
+// xsiNamespace is the namespace of the xsi:schemaLocation attributes.
+const xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
+// OccurrencePolicy returns how many times a particle is generated, given its minOccurs and maxOccurs,
+// the latter being Unbounded for unbounded particles. Randomness must come from r.
+type OccurrencePolicy func(r *helpers.Rand, minOccurs, maxOccurs int) int
+
+// RandomOccurrences picks a number of occurrences at random between minOccurs and maxOccurs. Unbounded
+// particles get at most limit occurrences, or minOccurs when it is larger.
+func RandomOccurrences(limit int) OccurrencePolicy {
+	return func(r *helpers.Rand, minOccurs, maxOccurs int) int {
+		if maxOccurs == Unbounded {
+			maxOccurs = max(minOccurs, limit)
+		}
+		return r.RandomBetween(minOccurs, maxOccurs)
+	}
+}
+
+// MinOccurrences always picks minOccurs.
+func MinOccurrences(_ *helpers.Rand, minOccurs, _ int) int {
+	return minOccurs
+}
+
+// MaxOccurrences always picks maxOccurs. Unbounded particles get limit occurrences, or minOccurs when it
+// is larger.
+func MaxOccurrences(limit int) OccurrencePolicy {
+	return func(_ *helpers.Rand, minOccurs, maxOccurs int) int {
+		if maxOccurs == Unbounded {
+			return max(minOccurs, limit)
+		}
+		return maxOccurs
+	}
+}
+
+// Mode selects the shape of the generated documents.
+type Mode string
+
+const (
+	// ModeRandom picks random numbers of occurrences and random choice branches. Optional attributes
+	// are always generated.
+	ModeRandom Mode = "random"
+	// ModeMin generates the smallest valid document: minOccurs occurrences, the smallest branch of each
+	// choice and only the required attributes.
+	ModeMin Mode = "min"
+	// ModeMax generates every optional item: maxOccurs occurrences, capped for unbounded particles,
+	// random choice branches and every attribute.
+	ModeMax Mode = "max"
+)
+
+// ParseMode parses the name of a Mode.
+func ParseMode(name string) (Mode, error) {
+	switch m := Mode(name); m {
+	case ModeRandom, ModeMin, ModeMax:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown mode %q, expected min, max or random", name)
+}
+
+// Generator builds XML documents for a global element of a schema. The same schema, options and seed
+// always give the same document.
+type Generator struct {
+	schema *model.XSDSchema
+	sizes  *sizes // computed once, then only read, so that documents can be generated concurrently
+	opts   options
+}
+
+// options holds the settings of a Generator, set with Option functions.
+type options struct {
+	root           string
+	seed           uint64
+	seeded         bool
+	values         helpers.ValueGenerator
+	boundaries     bool
+	rules          Rules
+	generators     *Registry
+	semantic       *semantic.Registry
+	constraints    Constraints
+	attempts       int
+	limits         limits
+	mode           Mode
+	occurrences    OccurrencePolicy
+	prefixes       map[string]string
+	schemaLocation string
+	indent         int
+	now            time.Time
+	target         int
+}
+
+// Option configures a Generator.
+type Option func(*options)
+
+// WithRoot selects the root element by local name, prefix:name or {namespace}name, as FindRoot does.
+// By default the root is the first global element with complex content.
+func WithRoot(name string) Option {
+	return func(o *options) { o.root = name }
+}
+
+// WithSeed sets the seed of the random choices. Without it a random seed is picked, which Seed reports.
+func WithSeed(seed uint64) Option {
+	return func(o *options) { o.seed, o.seeded = seed, true }
+}
+
+// WithValueGenerator generates the simple values with g instead of helpers.DefaultValueGenerator. g
+// gets a helpers.ValueContext per element text and attribute value, and the values it declines are
+// random; helpers.Chain and helpers.Fallback combine generators. Occurrences and choices are drawn from
+// the Rand of g when it implements helpers.Randomized, and from the seed of the Generator otherwise.
+func WithValueGenerator(g helpers.ValueGenerator) Option {
+	return func(o *options) { o.values = g }
+}
+
+// WithBoundaryValues generates the simple values with helpers.BoundaryValueGenerator, drawing from the
+// seed of the Generator: numbers on their bounds, strings of their minimum and maximum length, leap days
+// and extreme timezones. Suites then also cover every boundary value of every restricted simple type.
+// WithValueGenerator takes precedence.
+func WithBoundaryValues() Option {
+	return func(o *options) { o.boundaries = true }
+}
+
+// WithRules pins or constrains the values and occurrences of the nodes matching rules, before the value
+// generator is consulted. Generate fails when a rule does not fit the type of a node it matches, such as
+// a range on a string.
+func WithRules(rules Rules) Option {
+	return func(o *options) { o.rules = rules }
+}
+
+// WithNow sets the reference time of the relative bounds of rules, such as "today+30d". By default it is
+// the time New was called, so that the documents of a Generator agree, but WithNow is needed for the
+// same seed to give the same documents on another day.
+func WithNow(now time.Time) Option {
+	return func(o *options) { o.now = now }
+}
+
+// WithGenerators generates the values registry produces before asking the semantic providers and the
+// value generator, so that the generators registered for domain types or paths take precedence; rules
+// still come first. Generate fails when a generator of registry reports an error, such as a plugin that
+// stopped. The caller closes registry once done.
+func WithGenerators(registry *Registry) Option {
+	return func(o *options) { o.generators = registry }
+}
+
+// WithSemanticValues generates realistic values, such as names, e-mail addresses or IBANs, for the
+// string elements and attributes whose names or simple type names registry has a provider for, as long
+// as they fit the facets. Other values come from the value generator; rules take precedence.
+func WithSemanticValues(registry *semantic.Registry) Option {
+	return func(o *options) { o.semantic = registry }
+}
+
+// WithConstraints applies constraints to the documents built by Generate, generating a document again
+// when it breaks one of their comparisons. Suites ignore constraints.
+func WithConstraints(constraints Constraints) Option {
+	return func(o *options) { o.constraints = constraints }
+}
+
+// WithConstraintAttempts sets the number of documents Generate tries before failing with a
+// *ConstraintError. The default is DefaultConstraintAttempts.
+func WithConstraintAttempts(n int) Option {
+	return func(o *options) { o.attempts = max(n, 1) }
+}
+
+// WithMaxDepth limits the depth of the documents: the content of elements at depth n and below, the
+// root being at depth 1, is the shortest valid one, made of the minimum number of occurrences of each
+// particle and of the smallest alternative of each choice. Zero means no limit. The default is
+// DefaultMaxDepth.
+//
+// Generate fails with a *LimitError when a limit is reached inside an element whose required content is
+// recursive, since no document within the limits exists then.
+func WithMaxDepth(n int) Option {
+	return func(o *options) { o.limits.maxDepth = n }
+}
+
+// WithMaxElements limits the number of elements of the documents: once n elements are generated, the
+// rest of the document is the shortest valid completion, as for WithMaxDepth. The completion may add a
+// few elements beyond n. Zero means no limit. The default is DefaultMaxElements.
+func WithMaxElements(n int) Option {
+	return func(o *options) { o.limits.maxElements = n }
+}
+
+// WithMaxBytes limits the size of the documents, estimated without indentation nor namespace
+// declarations on the root: once n bytes are generated, the rest of the document is the shortest valid
+// completion, as for WithMaxDepth. Zero, the default, means no limit.
+func WithMaxBytes(n int) Option {
+	return func(o *options) { o.limits.maxBytes = n }
+}
+
+// WithTargetSize makes the documents about n bytes large, indentation included, by repeating the
+// occurrences of their outermost unbounded particles until the document reaches n: the first unbounded
+// particle met repeats until then, the ones inside it get their usual occurrences, and the ones after it,
+// if the document is still smaller, fill it in turn. Documents without unbounded particles stay smaller.
+// It also lifts the element limit, which a later WithMaxElements sets again; the other limits still
+// apply. Stream and WriteTo write such documents in constant memory, unless value generators reading the
+// parent values of attributes hold their elements.
+func WithTargetSize(n int) Option {
+	return func(o *options) { o.target, o.limits.maxElements = n, 0 }
+}
+
+// WithMode generates minimal, maximal or random documents, the default. It also sets the occurrence
+// policy to MinOccurrences, MaxOccurrences(3) or RandomOccurrences(3), which a later WithOccurrences
+// overrides. Limits still apply to maximal documents.
+func WithMode(mode Mode) Option {
+	return func(o *options) {
+		o.mode = mode
+		switch mode {
+		case ModeMin:
+			o.occurrences = MinOccurrences
+		case ModeMax:
+			o.occurrences = MaxOccurrences(defaultUnboundedLimit)
+		default:
+			o.occurrences = RandomOccurrences(defaultUnboundedLimit)
+		}
+	}
+}
+
+// WithOccurrences sets the policy deciding how many occurrences of each particle are generated. The
+// default is RandomOccurrences(3).
+func WithOccurrences(policy OccurrencePolicy) Option {
+	return func(o *options) { o.occurrences = policy }
+}
+
+// WithNamespacePrefixes writes the elements and attributes of the given namespaces with a prefix,
+// declared on the root element. prefixes maps namespace URIs to prefixes. Namespaces without a prefix
+// are declared as the default namespace of the elements using them.
+func WithNamespacePrefixes(prefixes map[string]string) Option {
+	return func(o *options) { o.prefixes = prefixes }
+}
+
+// WithSchemaLocation adds an xsi:schemaLocation attribute pointing at location to the root element, or
+// xsi:noNamespaceSchemaLocation when the root has no namespace.
+func WithSchemaLocation(location string) Option {
+	return func(o *options) { o.schemaLocation = location }
+}
+
+// WithIndent sets the number of spaces per indentation level used by WriteTo; zero writes the document
+// on a single line. The default is 2.
+func WithIndent(spaces int) Option {
+	return func(o *options) { o.indent = spaces }
+}
+
+// New returns a Generator of documents of schema.
+func New(schema *model.XSDSchema, opts ...Option) *Generator {
+	o := options{
+		mode:        ModeRandom,
+		occurrences: RandomOccurrences(defaultUnboundedLimit),
+		limits:      limits{maxDepth: DefaultMaxDepth, maxElements: DefaultMaxElements},
+		indent:      2,
+		attempts:    DefaultConstraintAttempts,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !o.seeded {
+		o.seed, o.seeded = helpers.NewSeed(), true
+	}
+	if o.now.IsZero() {
+		o.now = time.Now()
+	}
+	return &Generator{schema: schema, sizes: newSizes(schema), opts: o}
+}
+
+// Seed returns the seed of the generated documents, to generate them again with WithSeed.
+func (g *Generator) Seed() uint64 {
+	return g.opts.seed
+}
+
+// Generate builds a document. It stops with the error of ctx when ctx is canceled, with a *LimitError
+// when the document cannot be completed within the limits, and with a *ConstraintError when no document
+// satisfies the constraints.
+func (g *Generator) Generate(ctx context.Context) (*etree.Document, error) {
+	root, err := FindRoot(g.schema, g.opts.root)
+	if err != nil {
+		return nil, err
+	}
+	if len(g.opts.constraints) > 0 {
+		return g.constrained(g.newWalker(ctx), root)
+	}
+	return g.document(g.newWalker(ctx), root)
+}
+
+// newWalker returns a walker drawing from the seed of g, or from the Rand of its value generator.
+func (g *Generator) newWalker(ctx context.Context) *walker {
+	rand := helpers.NewRand(g.opts.seed)
+	values := g.opts.values
+	switch {
+	case values == nil && g.opts.boundaries:
+		values = helpers.BoundaryValueGenerator{Source: rand}
+	case values == nil:
+		values = helpers.DefaultValueGenerator{Source: rand}
+	default:
+		if r := helpers.RandOf(values); r != nil {
+			rand = r
+		}
+	}
+	rules, err := compileRules(g.opts.rules, g.opts.now)
+	return &walker{
+		ctx:         ctx,
+		schema:      g.schema,
+		rand:        rand,
+		values:      values,
+		rules:       rules,
+		generators:  g.opts.generators,
+		semantic:    g.opts.semantic,
+		err:         err,
+		occurrences: g.opts.occurrences,
+		minimal:     g.opts.mode == ModeMin,
+		limits:      g.opts.limits,
+		sizes:       g.sizes,
+		prefixes:    g.opts.prefixes,
+		indent:      g.opts.indent,
+		target:      g.opts.target,
+	}
+}
+
+// document generates a document of root with w.
+func (g *Generator) document(w *walker, root Root) (*etree.Document, error) {
+	tree := &tree{}
+	w.out = tree
+	w.element(root.Element, w.originOf(root.Element), "", 1, false)
+	if w.err == nil {
+		w.err = w.generators.Err()
+	}
+	if w.err != nil {
+		return nil, w.err
+	}
+	if tree.root == nil {
+		return nil, fmt.Errorf("cannot generate element %s", root.QName(g.schema))
+	}
+	for _, a := range g.rootAttrs(root, w.declared) {
+		tree.root.CreateAttr(a.Name.Local, a.Value)
+	}
+	doc := etree.NewDocument()
+	doc.SetRoot(tree.root)
+	return doc, nil
+}
+
+// rootAttrs returns the attributes added to the root element of a document of root: the declarations of
+// the prefixes of the namespaces, with the ones generated for namespaces without one in declared, in a
+// stable order, then the schema location.
+func (g *Generator) rootAttrs(root Root, declared map[string]string) []xml.Attr {
+	prefixes := map[string]string{}
+	for ns, prefix := range g.opts.prefixes {
+		prefixes[prefix] = ns
+	}
+	for ns, prefix := range declared {
+		prefixes[prefix] = ns
+	}
+	names := make([]string, 0, len(prefixes))
+	for prefix := range prefixes {
+		names = append(names, prefix)
+	}
+	sort.Strings(names)
+	var attrs []xml.Attr
+	attr := func(name, value string) {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+	}
+	for _, prefix := range names {
+		attr("xmlns:"+prefix, prefixes[prefix])
+	}
+	if g.opts.schemaLocation != "" {
+		if root.Namespace == "" {
+			attr("xsi:noNamespaceSchemaLocation", g.opts.schemaLocation)
+		} else {
+			attr("xsi:schemaLocation", root.Namespace+" "+g.opts.schemaLocation)
+		}
+		attr("xmlns:xsi", xsiNamespace)
+	}
+	return attrs
+}
+
+// WriteTo generates a document and writes it to w, indented as set with WithIndent. Documents are
+// streamed as Stream does, unless the generator has constraints, which need the whole document.
+func (g *Generator) WriteTo(w io.Writer) (int64, error) {
+	return g.write(context.Background(), w)
+}
+
+// write implements WriteTo.
+func (g *Generator) write(ctx context.Context, w io.Writer) (int64, error) {
+	if len(g.opts.constraints) == 0 {
+		return g.stream(ctx, w)
+	}
+	doc, err := g.Generate(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if g.opts.indent > 0 {
+		doc.Indent(g.opts.indent)
+	}
+	return doc.WriteTo(w)
+}
+
+// parseOccurs parses a minOccurs or maxOccurs value, which defaults to 1, into a count or Unbounded.
+func parseOccurs(value string) int {
+	switch value {
+	case "":
+		return 1
+	case "unbounded":
+		return Unbounded
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 1
+	}
+	return n
+}
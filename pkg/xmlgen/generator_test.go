@@ -0,0 +1,162 @@
+package xmlgen
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/parser"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/semantic"
+	"github.com/beevik/etree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const catalogNS = "http://example.com/catalog"
+
+// shallow keeps the recursive categories of the catalog small.
+var shallow = WithMaxDepth(4)
+
+// testSchema parses the schema testdata/name.
+func testSchema(t *testing.T, name string) *model.XSDSchema {
+	t.Helper()
+	schema, err := parser.ParseXSD(filepath.Join("testdata", name), nil)
+	require.NoError(t, err)
+	return schema
+}
+
+func write(t *testing.T, g *Generator) string {
+	t.Helper()
+	var buf bytes.Buffer
+	_, err := g.WriteTo(&buf)
+	require.NoError(t, err)
+	return buf.String()
+}
+
+// maxDepth returns the depth of the deepest element below e, e being at depth 1.
+func maxDepth(e *etree.Element) int {
+	depth := 0
+	for _, c := range e.ChildElements() {
+		depth = max(depth, maxDepth(c))
+	}
+	return depth + 1
+}
+
+func TestGeneratorIsReproducible(t *testing.T) {
+	schema := testSchema(t, "catalog.xsd")
+	first := write(t, New(schema, shallow, WithSeed(7)))
+	assert.Equal(t, first, write(t, New(schema, shallow, WithSeed(7))))
+
+	g := New(schema, shallow)
+	assert.Equal(t, write(t, g), write(t, New(schema, shallow, WithSeed(g.Seed()))), "Seed reports the random seed")
+}
+
+func TestGeneratorWritesNamespacesAndSchemaLocation(t *testing.T) {
+	doc, err := New(testSchema(t, "catalog.xsd"), shallow, WithSchemaLocation("catalog.xsd")).Generate(context.Background())
+	require.NoError(t, err)
+
+	root := doc.Root()
+	assert.Equal(t, "catalog", root.Tag)
+	assert.Equal(t, catalogNS, root.NamespaceURI())
+	assert.Equal(t, catalogNS+" catalog.xsd", root.SelectAttrValue("xsi:schemaLocation", ""))
+	category := root.SelectElement("category")
+	require.NotNil(t, category)
+	assert.Equal(t, catalogNS, category.NamespaceURI(), "qualified local elements inherit the default namespace")
+	assert.Regexp(t, `^[A-Z]{2}\d{4}$`, category.SelectAttrValue("ns1:code", ""), "qualified attributes get a prefix")
+	assert.Equal(t, catalogNS, root.SelectAttrValue("xmlns:ns1", ""))
+	assert.NotEmpty(t, category.SelectAttrValue("rank", ""))
+}
+
+func TestGeneratorUsesNamespacePrefixes(t *testing.T) {
+	g := New(testSchema(t, "catalog.xsd"), shallow, WithNamespacePrefixes(map[string]string{catalogNS: "c"}), WithIndent(0))
+	out := write(t, g)
+	assert.Regexp(t, `^<c:catalog xmlns:c="`+regexp.QuoteMeta(catalogNS)+`"><c:category c:code="`, out)
+	assert.NotContains(t, out, `xmlns="`)
+	assert.NotContains(t, out, "\n")
+}
+
+func TestGeneratorSelectsRoot(t *testing.T) {
+	g := New(testSchema(t, "catalog.xsd"), WithRoot("cat:note"), WithSchemaLocation("catalog.xsd"))
+	doc, err := g.Generate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "note", doc.Root().Tag)
+	assert.NotEmpty(t, doc.Root().Text())
+
+	_, err = New(testSchema(t, "catalog.xsd"), WithRoot("missing")).Generate(context.Background())
+	assert.ErrorContains(t, err, "no global element missing")
+}
+
+func TestGeneratorOccurrencesAndDepth(t *testing.T) {
+	schema := testSchema(t, "catalog.xsd")
+	most := func(_ *helpers.Rand, minOccurs, maxOccurs int) int {
+		if maxOccurs == Unbounded {
+			return max(minOccurs, 2)
+		}
+		return maxOccurs
+	}
+	for _, depth := range []int{2, 3, 5} {
+		doc, err := New(schema, WithOccurrences(most), WithMaxDepth(depth)).Generate(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, depth+1, maxDepth(doc.Root()), "required children of elements at the maximum depth are kept")
+		assert.Len(t, doc.Root().SelectElements("category"), 2)
+		assert.NotNil(t, doc.Root().SelectElement("comment"))
+	}
+
+	least := func(_ *helpers.Rand, minOccurs, _ int) int { return minOccurs }
+	doc, err := New(schema, WithOccurrences(least)).Generate(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, doc.Root().SelectElements("category"), 1)
+	assert.Nil(t, doc.Root().SelectElement("comment"))
+}
+
+func TestGeneratorModes(t *testing.T) {
+	schema := testSchema(t, "catalog.xsd")
+	doc, err := New(schema, WithMode(ModeMin)).Generate(context.Background())
+	require.NoError(t, err)
+	categories := doc.Root().SelectElements("category")
+	require.Len(t, categories, 1)
+	assert.Nil(t, doc.Root().SelectElement("comment"))
+	assert.Empty(t, categories[0].SelectElements("category"))
+	assert.NotEmpty(t, categories[0].SelectAttrValue("rank", ""), "required attributes are kept")
+	assert.Nil(t, categories[0].SelectAttr("ns1:code"), "optional attributes are left out")
+
+	doc, err = New(schema, WithMode(ModeMax), WithMaxDepth(3)).Generate(context.Background())
+	require.NoError(t, err)
+	categories = doc.Root().SelectElements("category")
+	require.Len(t, categories, defaultUnboundedLimit)
+	assert.NotNil(t, doc.Root().SelectElement("comment"))
+	assert.Len(t, categories[0].SelectElements("category"), defaultUnboundedLimit)
+	assert.NotNil(t, categories[0].SelectAttr("ns1:code"))
+	assert.Equal(t, 4, maxDepth(doc.Root()))
+
+	mode, err := ParseMode("max")
+	require.NoError(t, err)
+	assert.Equal(t, ModeMax, mode)
+	_, err = ParseMode("huge")
+	assert.ErrorContains(t, err, `unknown mode "huge"`)
+}
+
+func TestGeneratorStopsWhenContextIsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := New(testSchema(t, "catalog.xsd")).Generate(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestGeneratorSemanticValues(t *testing.T) {
+	schema := testSchema(t, "invoice.xsd")
+	doc, err := New(schema, WithSeed(1), WithSemanticValues(semantic.NewRegistry())).Generate(context.Background())
+	require.NoError(t, err)
+
+	billTo := doc.Root().SelectElement("billTo")
+	assert.Regexp(t, `^[A-Z][a-z]+ [A-Z][a-z]+$`, billTo.SelectElement("name").Text())
+	assert.Regexp(t, `^[A-Z][a-z]+$`, billTo.SelectElement("city").Text())
+	assert.Regexp(t, `^[A-Z][a-z]+( [A-Z][a-z]+)?$`, billTo.SelectAttrValue("country", ""))
+	_, err = strconv.ParseFloat(doc.Root().SelectElement("total").Text(), 64)
+	assert.NoError(t, err, "other values come from the value generator")
+}
@@ -0,0 +1,266 @@
+package xmlgen
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+)
+
+// DefaultMaxDepth and DefaultMaxElements are the limits from which a Generator, unless configured
+// otherwise, and GenerateElement only generate the shortest valid completion of the document.
+const (
+	DefaultMaxDepth    = 12
+	DefaultMaxElements = 10000
+)
+
+// infinite is the size of content that cannot be completed, because it requires itself.
+const infinite = math.MaxInt
+
+// limits bounds the size of a document. Zero fields mean no limit.
+type limits struct {
+	maxDepth    int
+	maxElements int
+	maxBytes    int
+}
+
+// LimitError reports that a limit was reached inside an element whose required content is recursive,
+// so that no document of bounded size is valid.
+type LimitError struct {
+	Path  string // location of the element, such as "/node/node/node"
+	Limit string // "depth", "elements" or "bytes"
+}
+
+// Error implements the error interface.
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: %s limit reached and the required content of the element is recursive, "+
+		"so the document cannot be completed", e.Path, e.Limit)
+}
+
+// reached returns the name of the first limit reached by a document under construction whose deepest
+// element is at depth, or "" when there is room left.
+func (w *walker) reached(depth int) string {
+	switch {
+	case w.limits.maxDepth > 0 && depth >= w.limits.maxDepth:
+		return "depth"
+	case w.limits.maxElements > 0 && w.elements >= w.limits.maxElements:
+		return "elements"
+	case w.limits.maxBytes > 0 && w.bytes >= w.limits.maxBytes:
+		return "bytes"
+	}
+	return ""
+}
+
+// sizes computes the smallest number of elements of valid content, used to complete documents once a
+// limit is reached. Content requiring itself, such as a node with a required node child, is infinite.
+type sizes struct {
+	schema  *model.XSDSchema
+	content map[*model.XSDComplexType]int // smallest number of elements inside each complex type
+}
+
+// newSizes computes the size of every complex type of the schema, named or inline, by iterating to a
+// fixed point: sizes start infinite and only decrease, so recursive types settle to the size of their
+// shortest non-recursive completion.
+func newSizes(schema *model.XSDSchema) *sizes {
+	s := &sizes{schema: schema, content: map[*model.XSDComplexType]int{}}
+	var types []*model.XSDComplexType
+	for i := range schema.ComplexTypes {
+		types = collectComplexTypes(&schema.ComplexTypes[i], types)
+	}
+	for i := range schema.Elements {
+		if ct := schema.Elements[i].ComplexType; ct != nil {
+			types = collectComplexTypes(ct, types)
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, ct := range types {
+			if n := s.complexType(ct); n < s.typeSize(ct) {
+				s.content[ct] = n
+				changed = true
+			}
+		}
+	}
+	return s
+}
+
+// collectComplexTypes appends ct and the inline complex types of its elements to types.
+func collectComplexTypes(ct *model.XSDComplexType, types []*model.XSDComplexType) []*model.XSDComplexType {
+	types = append(types, ct)
+	var visit func(particles []model.XSDParticle)
+	visit = func(particles []model.XSDParticle) {
+		for _, p := range particles {
+			switch {
+			case p.Element != nil && p.Element.ComplexType != nil:
+				types = collectComplexTypes(p.Element.ComplexType, types)
+			case p.Sequence != nil:
+				visit(p.Sequence.Particles())
+			case p.Choice != nil:
+				visit(p.Choice.Particles())
+			}
+		}
+	}
+	if ct.Sequence != nil {
+		visit(ct.Sequence.Particles())
+	}
+	if ct.Choice != nil {
+		visit(ct.Choice.Particles())
+	}
+	return types
+}
+
+func (s *sizes) typeSize(ct *model.XSDComplexType) int {
+	if n, ok := s.content[ct]; ok {
+		return n
+	}
+	return infinite
+}
+
+// complexType returns the size of the content of ct from the current estimates.
+func (s *sizes) complexType(ct *model.XSDComplexType) int {
+	n := 0
+	if ct.Sequence != nil {
+		n = add(n, s.sequence(ct.Sequence))
+	}
+	if ct.Choice != nil {
+		n = add(n, s.choice(ct.Choice))
+	}
+	return n
+}
+
+func (s *sizes) sequence(seq *model.XSDSequence) int {
+	n := 0
+	for _, p := range seq.Particles() {
+		n = add(n, s.particle(p))
+	}
+	return times(minOccurs(seq.MinOccurs), n)
+}
+
+func (s *sizes) choice(choice *model.XSDChoice) int {
+	return times(minOccurs(choice.MinOccurs), s.smallestBranch(choice.Particles()))
+}
+
+// smallestBranch returns the size of the smallest of the alternatives of a choice.
+func (s *sizes) smallestBranch(particles []model.XSDParticle) int {
+	smallest := infinite
+	if len(particles) == 0 {
+		smallest = 0
+	}
+	for _, p := range particles {
+		smallest = min(smallest, s.group(p))
+	}
+	return smallest
+}
+
+// particle returns the size of the required occurrences of p.
+func (s *sizes) particle(p model.XSDParticle) int {
+	if p.Element != nil {
+		return times(minOccurs(p.Element.MinOccurs), s.element(p.Element))
+	}
+	return s.group(p)
+}
+
+// group returns the size of one occurrence of an element particle, or the size of a model group.
+func (s *sizes) group(p model.XSDParticle) int {
+	switch {
+	case p.Element != nil:
+		return s.element(p.Element)
+	case p.Sequence != nil:
+		return s.sequence(p.Sequence)
+	case p.Choice != nil:
+		return s.choice(p.Choice)
+	}
+	return 0
+}
+
+// element returns the size of one occurrence of el, el included.
+func (s *sizes) element(el *model.XSDElement) int {
+	if el.Ref != "" {
+		ref := globalElement(s.schema, el.Ref)
+		if ref == nil || ref.Ref != "" {
+			return 0
+		}
+		smallest := infinite
+		for _, c := range substitutes(s.schema, ref) {
+			smallest = min(smallest, s.element(c))
+		}
+		return smallest
+	}
+	switch {
+	case el.Fixed != "" || el.SimpleType != nil:
+		return 1
+	case el.ComplexType != nil:
+		return add(1, s.typeSize(el.ComplexType))
+	case el.Type != "" && !isBuiltin(el.Type):
+		for i := range s.schema.ComplexTypes {
+			if ct := &s.schema.ComplexTypes[i]; ct.Name == localName(el.Type) {
+				return add(1, s.typeSize(ct))
+			}
+		}
+	}
+	return 1
+}
+
+// minOccurs parses a minOccurs value, which defaults to 1.
+func minOccurs(value string) int {
+	return max(parseOccurs(value), 0)
+}
+
+// add and times are saturating at infinite.
+func add(a, b int) int {
+	if a == infinite || b == infinite {
+		return infinite
+	}
+	return a + b
+}
+
+func times(n, size int) int {
+	switch {
+	case n == 0 || size == 0:
+		return 0
+	case size == infinite || size > infinite/n:
+		return infinite
+	}
+	return n * size
+}
+
+// elementBytes estimates the size of an element written without indentation, <tag></tag>, as its
+// attributes and text are added to it.
+func elementBytes(tag string) int {
+	return 2*len(tag) + len("<></>")
+}
+
+func attributeBytes(name, value string) int {
+	return len(name) + len(value) + len(` =""`)
+}
+
+// location returns the location of the element being generated, such as "/order/line".
+func (w *walker) location() string {
+	return "/" + strings.Join(w.path, "/")
+}
+
+// sizeUnits are the multipliers of the units of ParseSize, by lower case name.
+var sizeUnits = map[string]float64{
+	"": 1, "b": 1,
+	"k": 1e3, "kb": 1e3, "m": 1e6, "mb": 1e6, "g": 1e9, "gb": 1e9,
+	"kib": 1 << 10, "mib": 1 << 20, "gib": 1 << 30,
+}
+
+// ParseSize parses a number of bytes, such as "500MB", "1.5 GiB" or "20000": B, KB, MB and GB count in
+// powers of 1000, KiB, MiB and GiB in powers of 1024, and units are case insensitive.
+func ParseSize(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool { return r != '.' && !unicode.IsDigit(r) })
+	if i < 0 {
+		i = len(s)
+	}
+	n, err := strconv.ParseFloat(s[:i], 64)
+	unit, ok := sizeUnits[strings.ToLower(strings.TrimSpace(s[i:]))]
+	if err != nil || !ok || n*unit > math.MaxInt {
+		return 0, fmt.Errorf("invalid size %q: want a number of bytes such as 500MB or 2GiB", s)
+	}
+	return int(n * unit), nil
+}
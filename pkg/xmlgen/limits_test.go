@@ -0,0 +1,81 @@
+package xmlgen
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/beevik/etree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// most generates as many occurrences as possible, to reach the limits quickly.
+var most = MaxOccurrences(3)
+
+func countElements(e *etree.Element) int {
+	n := 1
+	for _, c := range e.ChildElements() {
+		n += countElements(c)
+	}
+	return n
+}
+
+func TestSizes(t *testing.T) {
+	schema := testSchema(t, "recursive.xsd")
+	s := newSizes(schema)
+	for name, want := range map[string]int{"node": 2, "expr": 2, "loop": infinite} {
+		root, err := FindRoot(schema, name)
+		require.NoError(t, err)
+		assert.Equal(t, want, s.element(root.Element), name)
+	}
+}
+
+func TestMaxDepthPicksNonRecursiveBranch(t *testing.T) {
+	doc, err := New(testSchema(t, "recursive.xsd"), WithRoot("expr"), WithOccurrences(most), WithMaxDepth(3)).Generate(context.Background())
+	require.NoError(t, err)
+	assert.LessOrEqual(t, maxDepth(doc.Root()), 4)
+	for _, expr := range doc.FindElements("//expr") {
+		if len(expr.ChildElements()) == 1 && expr.SelectElement("sum") == nil {
+			assert.NotNil(t, expr.SelectElement("literal"))
+		}
+	}
+	for _, sum := range doc.FindElements("//sum") {
+		assert.GreaterOrEqual(t, len(sum.SelectElements("expr")), 2, "minOccurs is kept at the limit")
+	}
+}
+
+func TestMaxElementsAndMaxBytes(t *testing.T) {
+	schema := testSchema(t, "recursive.xsd")
+	doc, err := New(schema, WithRoot("node"), WithOccurrences(most), WithMaxDepth(0), WithMaxElements(50)).
+		Generate(context.Background())
+	require.NoError(t, err)
+	n := countElements(doc.Root())
+	assert.GreaterOrEqual(t, n, 50)
+	assert.Less(t, n, 60, "the completion only adds the required labels of the open nodes")
+
+	out := write(t, New(schema, WithRoot("node"), WithOccurrences(most), WithMaxDepth(0), WithMaxBytes(2000), WithIndent(0)))
+	assert.Greater(t, len(out), 1000)
+	assert.Less(t, len(out), 3000)
+}
+
+func TestDefaultLimitsStopUnboundedRecursion(t *testing.T) {
+	doc, err := New(testSchema(t, "recursive.xsd"), WithRoot("node"), WithOccurrences(most)).Generate(context.Background())
+	require.NoError(t, err)
+	assert.LessOrEqual(t, countElements(doc.Root()), DefaultMaxElements+DefaultMaxDepth+1)
+	assert.LessOrEqual(t, maxDepth(doc.Root()), DefaultMaxDepth+1)
+}
+
+func TestLimitWithoutCompletionFails(t *testing.T) {
+	schema := testSchema(t, "recursive.xsd")
+	_, err := New(schema, WithRoot("loop"), WithMaxDepth(3)).Generate(context.Background())
+	var limit *LimitError
+	require.ErrorAs(t, err, &limit)
+	assert.Equal(t, "/"+strings.Repeat("loop/", 2)+"loop", limit.Path)
+	assert.Equal(t, "depth", limit.Limit)
+
+	root, err := FindRoot(schema, "loop")
+	require.NoError(t, err)
+	assert.Nil(t, GenerateElement(schema, root.Element, helpers.DefaultValueGenerator{}))
+}
@@ -1,7 +1,7 @@
 package mocks
 
 import (
-	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -10,7 +10,12 @@ type MockValueGenerator struct {
 	mock.Mock
 }
 
-func (m *MockValueGenerator) Generate(xsdType string, restriction *model.XSDRestriction) string {
-	args := m.Called(xsdType, restriction)
-	return args.String(0)
+func (m *MockValueGenerator) Generate(vc *helpers.ValueContext) (string, bool) {
+	args := m.Called(vc)
+	return args.String(0), args.Bool(1)
+}
+
+// Base matches the value contexts of nodes of the built-in type base, for On("Generate", ...).
+func Base(base string) any {
+	return mock.MatchedBy(func(vc *helpers.ValueContext) bool { return vc.Base == base })
 }
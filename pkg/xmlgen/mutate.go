@@ -0,0 +1,693 @@
+package xmlgen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/xsdtypes"
+	"github.com/beevik/etree"
+)
+
+// Constraints violated by the documents of a MutationSet. Facets use their XML Schema names, such as
+// "minInclusive" or "pattern".
+const (
+	ViolateMinOccurs  = "minOccurs"  // a required element is missing
+	ViolateMaxOccurs  = "maxOccurs"  // an element occurs too many times
+	ViolateUnexpected = "unexpected" // an element the content model does not declare
+	ViolateUse        = "use"        // a required attribute is missing
+	ViolateType       = "type"       // a value that is not of the built-in type, such as "x" for an xs:int
+	ViolateFixed      = "fixed"      // a value that differs from the fixed one
+)
+
+// unexpectedElement is the name of the element added by ViolateUnexpected mutations.
+const unexpectedElement = "unexpectedElement"
+
+// Mutation is a document violating exactly one constraint of the schema.
+type Mutation struct {
+	Name       string          `json:"document"`   // file name, <n>-<constraint>.xml
+	Constraint string          `json:"constraint"` // ViolateMinOccurs, ..., or the name of a facet
+	Path       string          `json:"path"`       // location of the faulty element or attribute
+	Message    string          `json:"message"`    // expected violation, in the words of package validation
+	Document   *etree.Document `json:"-"`
+}
+
+// MutationSet is a valid document and its mutations.
+type MutationSet struct {
+	Valid     *etree.Document
+	Mutations []Mutation
+}
+
+// WriteManifest writes the mutations, without their documents, as indented JSON.
+func (m *MutationSet) WriteManifest(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m.Mutations)
+}
+
+// Mutate generates a valid document, as Generate does, then one document per constraint that can be
+// violated in it: per declaration of an element or attribute, a missing required element or attribute,
+// an extra occurrence beyond maxOccurs, an unexpected child element, and values breaking the facets,
+// the fixed value or the built-in type. Bounds are only violated for numeric types. Each mutated value
+// breaks its constraint and no other facet of its type; constraints that cannot be broken alone, such as a
+// maxLength that the pattern already enforces, get no mutation.
+//
+// Occurrences are only mutated for the element particles of the top-level sequence of a type, where
+// removing or repeating an element cannot select another branch or occurrence of a group. Mutate fails
+// when a value of the generated document breaks its type, facets or fixed value, as the values of rules or
+// of a value generator may, since its mutations would then violate more than one constraint.
+func (g *Generator) Mutate(ctx context.Context) (*MutationSet, error) {
+	valid, err := g.Generate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	root, err := FindRoot(g.schema, g.opts.root)
+	if err != nil {
+		return nil, err
+	}
+	m := &mutator{schema: g.schema, valid: valid, seen: map[string]bool{}}
+	m.element(valid.Root(), root.Element, nil)
+	if err := m.invalid.Err(); err != nil {
+		return nil, fmt.Errorf("the document to mutate is not valid: %w", err)
+	}
+	return &MutationSet{Valid: valid, Mutations: m.mutations}, nil
+}
+
+// mutator walks a valid document along the declarations of its elements and collects the mutations.
+type mutator struct {
+	schema    *model.XSDSchema
+	valid     *etree.Document
+	mutations []Mutation
+	seen      map[string]bool   // constraints already violated, per declaration
+	invalid   validation.Errors // values of the valid document breaking their constraints
+
+	facets map[restrictionFacet]*validation.Facets // facets of the restrictions, their pattern compiled once
+}
+
+// restrictionFacet is a facet of a restriction, "" for all of them.
+type restrictionFacet struct {
+	r     *model.XSDRestriction
+	facet string
+}
+
+// facetsOf returns the facets of r, nil when r is nil, or only the one named facet when it is not empty.
+func (m *mutator) facetsOf(r *model.XSDRestriction, facet string) *validation.Facets {
+	if r == nil {
+		return nil
+	}
+	if m.facets == nil {
+		m.facets = map[restrictionFacet]*validation.Facets{}
+	}
+	key := restrictionFacet{r, facet}
+	if f, ok := m.facets[key]; ok {
+		return f
+	}
+	f := helpers.FacetsOf(r)
+	if facet != "" {
+		f = facetOnly(f, facet)
+	}
+	m.facets[key] = f
+	return f
+}
+
+// add records a mutation of the element at index, the positions of the element and its ancestors
+// among their parent's child elements, unless the constraint of decl was already violated.
+func (m *mutator) add(decl any, constraint string, index []int, path, message string, apply func(*etree.Element)) {
+	key := fmt.Sprintf("%p %s", decl, constraint)
+	if m.seen[key] {
+		return
+	}
+	m.seen[key] = true
+	doc := m.valid.Copy()
+	elem := doc.Root()
+	for _, i := range index {
+		elem = elem.ChildElements()[i]
+	}
+	apply(elem)
+	name := fmt.Sprintf("%03d-%s.xml", len(m.mutations)+1, constraint)
+	m.mutations = append(m.mutations, Mutation{Name: name, Constraint: constraint, Path: path, Message: message, Document: doc})
+}
+
+// element collects the mutations of elem, declared by el, and of its descendants.
+func (m *mutator) element(elem *etree.Element, el *model.XSDElement, index []int) {
+	if el.Ref != "" {
+		if el = globalElement(m.schema, el.Ref); el == nil {
+			return
+		}
+	}
+	path := elementPath(elem)
+	ct := complexTypeOf(m.schema, el)
+	if ct == nil {
+		base, r := m.simpleContent(el)
+		m.check(path, elem.Text(), base, r, el.Fixed)
+		m.value(el, index, path, elem.Text(), base, r, el.Fixed, func(e *etree.Element, v string) { e.SetText(v) })
+		return
+	}
+
+	for i := range ct.Attrs {
+		m.attribute(elem, &ct.Attrs[i], index, path)
+	}
+	m.add(ct, ViolateUnexpected, index, path+"/"+unexpectedElement,
+		fmt.Sprintf("element %s is not declared in %s", unexpectedElement, elem.Tag), func(e *etree.Element) {
+			e.CreateElement(unexpectedElement)
+		})
+
+	decls := childDeclarations(m.schema, ct)
+	if ct.Sequence != nil && parseOccurs(ct.Sequence.MinOccurs) == 1 && parseOccurs(ct.Sequence.MaxOccurs) == 1 {
+		for _, p := range ct.Sequence.Particles() {
+			if p.Element != nil {
+				m.occurrences(elem, p.Element, decls, index, path)
+			}
+		}
+	}
+	for i, child := range elem.ChildElements() {
+		if decl := decls[child.Tag]; decl != nil {
+			m.element(child, decl, append(slices.Clip(index), i))
+		}
+	}
+}
+
+// occurrences removes an occurrence of a required particle, and adds one beyond its maxOccurs.
+func (m *mutator) occurrences(elem *etree.Element, particle *model.XSDElement, decls map[string]*model.XSDElement,
+	index []int, path string) {
+	name := particle.Name
+	if particle.Ref != "" {
+		name = localName(particle.Ref)
+		if head := globalElement(m.schema, particle.Ref); head == nil || len(substitutes(m.schema, head)) != 1 {
+			return
+		}
+	}
+	if decls[name] == nil {
+		return
+	}
+	var positions []int
+	for i, child := range elem.ChildElements() {
+		if child.Tag == name {
+			positions = append(positions, i)
+		}
+	}
+	minOccurs, maxOccurs := parseOccurs(particle.MinOccurs), parseOccurs(particle.MaxOccurs)
+	if minOccurs > 0 && len(positions) == minOccurs {
+		m.add(particle, ViolateMinOccurs, index, path+"/"+name,
+			fmt.Sprintf("element %s occurs %d times, less than minOccurs %d", name, minOccurs-1, minOccurs),
+			func(e *etree.Element) { e.RemoveChild(e.ChildElements()[positions[len(positions)-1]]) })
+	}
+	if maxOccurs != Unbounded && len(positions) > 0 && len(positions) <= maxOccurs {
+		m.add(particle, ViolateMaxOccurs, index, path+"/"+name,
+			fmt.Sprintf("element %s occurs %d times, more than maxOccurs %d", name, maxOccurs+1, maxOccurs),
+			func(e *etree.Element) {
+				last := e.ChildElements()[positions[len(positions)-1]]
+				for n := len(positions); n <= maxOccurs; n++ {
+					e.InsertChildAt(last.Index()+1, last.Copy())
+				}
+			})
+	}
+}
+
+// attribute collects the mutations of the attribute attr of elem.
+func (m *mutator) attribute(elem *etree.Element, attr *model.XSDAttribute, index []int, path string) {
+	var present *etree.Attr
+	for i := range elem.Attr {
+		if elem.Attr[i].Key == attr.Name {
+			present = &elem.Attr[i]
+		}
+	}
+	if present == nil {
+		return
+	}
+	key, at := present.FullKey(), path+"/@"+attr.Name
+	if attr.Use == "required" {
+		m.add(attr, ViolateUse, index, at, fmt.Sprintf("required attribute %s is missing", attr.Name),
+			func(e *etree.Element) { e.RemoveAttr(key) })
+	}
+	base, r := attr.Type, (*model.XSDRestriction)(nil)
+	if !isBuiltin(attr.Type) {
+		if st := findSimpleType(m.schema, localName(attr.Type)); st != nil {
+			base, r = simpleBase(m.schema, st)
+		}
+	}
+	m.check(at, present.Value, base, r, attr.Fixed)
+	m.value(attr, index, at, present.Value, base, r, attr.Fixed, func(e *etree.Element, v string) { e.CreateAttr(key, v) })
+}
+
+// check records how value, found at path in the valid document, breaks its built-in type base, the facets
+// of r or its fixed value.
+func (m *mutator) check(path, value, base string, r *model.XSDRestriction, fixed string) {
+	checkValue(path, value, base, m.facetsOf(r, ""), fixed, &m.invalid)
+}
+
+// checkValue adds to errs how value breaks the built-in type base, facets, which may be nil, or fixed,
+// when it is not empty.
+func checkValue(path, value, base string, facets *validation.Facets, fixed string, errs *validation.Errors) {
+	if fixed != "" && value != fixed {
+		errs.Add(path, "value %q differs from the fixed value %q", value, fixed)
+	}
+	newValue, ok := lexicalTypes[localName(base)]
+	if isInteger(base) {
+		newValue, ok = func() any { return new(xsdtypes.Integer) }, true
+	}
+	if ok && xsdtypes.Decode(value, newValue()) != nil {
+		errs.Add(path, "value %q is not a valid %s", value, base)
+	}
+	if facets != nil {
+		validation.CheckValue(path, value, facets, false, errs)
+	}
+}
+
+// lexicalTypes return the Go values decoding the built-in types, other than integers, whose values
+// invalidLexical breaks, to check the values of the valid document.
+var lexicalTypes = map[string]func() any{
+	"boolean":    func() any { return new(bool) },
+	"decimal":    func() any { return new(xsdtypes.Decimal) },
+	"float":      func() any { return new(float32) },
+	"double":     func() any { return new(float64) },
+	"date":       func() any { return new(xsdtypes.Date) },
+	"dateTime":   func() any { return new(xsdtypes.DateTime) },
+	"time":       func() any { return new(xsdtypes.Time) },
+	"gYear":      func() any { return new(xsdtypes.GYear) },
+	"gYearMonth": func() any { return new(xsdtypes.GYearMonth) },
+	"duration":   func() any { return new(xsdtypes.Duration) },
+}
+
+// value collects the mutations of valid, a simple value of the built-in type base restricted by r, set
+// with set. Each mutation takes the first candidate value breaking its constraint and nothing else; the
+// constraints without such a value are left out.
+func (m *mutator) value(decl any, index []int, path, valid, base string, r *model.XSDRestriction, fixed string,
+	set func(*etree.Element, string)) {
+	for _, c := range candidates(valid, base, r, fixed) {
+		for _, value := range c.values {
+			if message, ok := m.breaksOnly(path, value, base, r, fixed, c.constraint); ok {
+				m.add(decl, c.constraint, index, path, message, func(e *etree.Element) { set(e, value) })
+				break
+			}
+		}
+	}
+}
+
+// breaksOnly returns the message of the only constraint value breaks, when that constraint is constraint.
+func (m *mutator) breaksOnly(path, value, base string, r *model.XSDRestriction, fixed, constraint string) (string, bool) {
+	var errs, own validation.Errors
+	checkValue(path, value, base, m.facetsOf(r, ""), fixed, &errs)
+	if len(errs) != 1 {
+		return "", false
+	}
+	switch constraint {
+	case ViolateFixed:
+		checkValue(path, value, "", nil, fixed, &own)
+	case ViolateType:
+		checkValue(path, value, base, nil, "", &own)
+	default:
+		validation.CheckValue(path, value, m.facetsOf(r, constraint), false, &own)
+	}
+	return errs[0].Message, len(own) == 1
+}
+
+// facetOnly returns the facet of f named facet, alone.
+func facetOnly(f *validation.Facets, facet string) *validation.Facets {
+	only := &validation.Facets{}
+	switch facet {
+	case "enumeration":
+		only.Enumerations = f.Enumerations
+	case "pattern":
+		only.Pattern = f.Pattern
+	case "length":
+		only.Length = f.Length
+	case "minLength":
+		only.MinLength = f.MinLength
+	case "maxLength":
+		only.MaxLength = f.MaxLength
+	case "minInclusive":
+		only.MinInclusive = f.MinInclusive
+	case "maxInclusive":
+		only.MaxInclusive = f.MaxInclusive
+	case "minExclusive":
+		only.MinExclusive = f.MinExclusive
+	case "maxExclusive":
+		only.MaxExclusive = f.MaxExclusive
+	case "totalDigits":
+		only.TotalDigits = f.TotalDigits
+	case "fractionDigits":
+		only.FractionDigits = f.FractionDigits
+	}
+	return only
+}
+
+// candidate is a constraint and values that may break it, in order of preference.
+type candidate struct {
+	constraint string
+	values     []string
+}
+
+// candidates returns the values that may break the fixed value, the built-in type base or each facet of
+// r, which may be nil, of a simple value whose valid value is valid. Values derived from valid keep the
+// other facets more often than constant ones.
+func candidates(valid, base string, r *model.XSDRestriction, fixed string) []candidate {
+	if fixed != "" {
+		values := []string{fixed + "x", "x" + fixed}
+		if n, ok := new(big.Rat).SetString(fixed); ok && isNumeric(base) {
+			values = append(values, ratString(n.Add(n, big.NewRat(1, 1))))
+		}
+		if r != nil {
+			for _, e := range r.Enumerations {
+				values = append(values, e.Value)
+			}
+		}
+		return []candidate{{ViolateFixed, values}}
+	}
+	var all []candidate
+	if invalid, ok := invalidLexical(base); ok && (r == nil || len(r.Enumerations) == 0) {
+		all = append(all, candidate{ViolateType, []string{invalid}})
+	}
+	if r == nil {
+		return all
+	}
+	if len(r.Enumerations) > 0 {
+		values := []string{"invalid"}
+		for _, e := range r.Enumerations {
+			values = append(values, e.Value+"x")
+			if n, ok := new(big.Rat).SetString(e.Value); ok && isNumeric(base) {
+				values = append(values, ratString(new(big.Rat).Add(n, big.NewRat(1, 1))),
+					ratString(new(big.Rat).Sub(n, big.NewRat(1, 1))))
+			}
+		}
+		all = append(all, candidate{"enumeration", values})
+	}
+	if r.Pattern != nil {
+		all = append(all, candidate{"pattern", []string{"", "!", "0", "a", "~invalid~", valid + "!", resize(valid, len(valid)+1)}})
+	}
+	if n, ok := facetInt(r.MaxLength); ok {
+		all = append(all, candidate{"maxLength", []string{resize(valid, n+1), strings.Repeat("x", n+1), strings.Repeat("0", n+1)}})
+	}
+	if n, ok := facetInt(r.MinLength); ok && n > 0 {
+		all = append(all, candidate{"minLength", []string{resize(valid, n-1), strings.Repeat("x", n-1), strings.Repeat("0", n-1)}})
+	}
+	if n, ok := facetInt(r.Length); ok {
+		all = append(all, candidate{"length", []string{resize(valid, n+1), resize(valid, n-1), strings.Repeat("x", n+1)}})
+	}
+	if isNumeric(base) {
+		all = append(all, boundCandidates(base, r)...)
+		all = append(all, digitCandidates(valid, base, r)...)
+	}
+	return all
+}
+
+// boundCandidates returns the values that may break the bounds of r, of a numeric type base.
+func boundCandidates(base string, r *model.XSDRestriction) []candidate {
+	steps := []*big.Rat{big.NewRat(1, 1)}
+	if !isInteger(base) {
+		steps = append(steps, big.NewRat(1, 10))
+	}
+	bounds := []struct {
+		facet string
+		limit *model.XSDValue
+		sign  int64 // direction of the values breaking the bound, 0 for the bound itself
+	}{
+		{"minInclusive", r.MinIncl, -1},
+		{"maxInclusive", r.MaxIncl, 1},
+		{"minExclusive", r.MinExcl, 0},
+		{"maxExclusive", r.MaxExcl, 0},
+	}
+	var all []candidate
+	for _, b := range bounds {
+		if b.limit == nil {
+			continue
+		}
+		limit, ok := new(big.Rat).SetString(b.limit.Value)
+		if !ok {
+			continue
+		}
+		var values []string
+		for _, step := range steps {
+			delta := new(big.Rat).Mul(step, big.NewRat(b.sign, 1))
+			values = append(values, ratString(delta.Add(delta, limit)))
+		}
+		all = append(all, candidate{b.facet, values})
+	}
+	return all
+}
+
+// digitCandidates returns the values that may break the digits facets of r, of a numeric type base.
+func digitCandidates(valid, base string, r *model.XSDRestriction) []candidate {
+	total, fraction, ok := validation.Digits(valid)
+	var all []candidate
+	if n, isSet := facetInt(r.TotalDigits); isSet {
+		values := []string{"1" + strings.Repeat("0", n), strings.Repeat("9", n+1), "-1" + strings.Repeat("0", n)}
+		if ok && !isInteger(base) && total <= n {
+			values = append(values, moreFraction(valid, n+1-total))
+		}
+		all = append(all, candidate{"totalDigits", values})
+	}
+	if n, isSet := facetInt(r.FractionDigits); isSet && !isInteger(base) {
+		values := []string{"0." + strings.Repeat("1", n+1)}
+		if ok && fraction <= n {
+			values = append([]string{moreFraction(valid, n+1-fraction)}, values...)
+		}
+		all = append(all, candidate{"fractionDigits", values})
+	}
+	return all
+}
+
+// moreFraction appends n digits 1 to the fraction of the decimal value.
+func moreFraction(value string, n int) string {
+	if !strings.Contains(value, ".") {
+		value += "."
+	}
+	return value + strings.Repeat("1", n)
+}
+
+// resize cuts value to n characters, or repeats its last one up to n. Empty values stay empty.
+func resize(value string, n int) string {
+	runes := []rune(value)
+	if len(runes) == 0 || n < 0 {
+		return ""
+	}
+	for len(runes) < n {
+		runes = append(runes, runes[len(runes)-1])
+	}
+	return string(runes[:n])
+}
+
+// ratString formats n as an integer, or a decimal without trailing zeros.
+func ratString(n *big.Rat) string {
+	if n.IsInt() {
+		return n.Num().String()
+	}
+	return strings.TrimRight(n.FloatString(6), "0")
+}
+
+func facetInt(v *model.XSDValue) (int, bool) {
+	if v == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v.Value)
+	return n, err == nil
+}
+
+// invalidLexical returns a value that is not of the built-in type base, when base is not a string type.
+func invalidLexical(base string) (string, bool) {
+	switch localName(base) {
+	case "boolean":
+		return "maybe", true
+	case "date", "dateTime", "time", "gYear", "gYearMonth", "duration":
+		return "not-a-" + localName(base), true
+	}
+	if isNumeric(base) {
+		return "not-a-number", true
+	}
+	return "", false
+}
+
+func isNumeric(base string) bool {
+	switch localName(base) {
+	case "decimal", "float", "double":
+		return true
+	}
+	return isInteger(base)
+}
+
+func isInteger(base string) bool {
+	switch localName(base) {
+	case "integer", "int", "long", "short", "byte", "nonNegativeInteger", "positiveInteger",
+		"nonPositiveInteger", "negativeInteger", "unsignedLong", "unsignedInt", "unsignedShort", "unsignedByte":
+		return true
+	}
+	return false
+}
+
+// complexTypeOf returns the complex type of el, or nil when its content is simple.
+func complexTypeOf(schema *model.XSDSchema, el *model.XSDElement) *model.XSDComplexType {
+	if el.ComplexType != nil {
+		return el.ComplexType
+	}
+	if el.Type == "" || isBuiltin(el.Type) {
+		return nil
+	}
+	for i := range schema.ComplexTypes {
+		if ct := &schema.ComplexTypes[i]; ct.Name == localName(el.Type) {
+			return ct
+		}
+	}
+	return nil
+}
+
+// simpleContent returns the built-in base type and the restriction of an element with simple content,
+// as the walker generates them.
+func (m *mutator) simpleContent(el *model.XSDElement) (string, *model.XSDRestriction) {
+	switch {
+	case el.SimpleType != nil:
+		return simpleBase(m.schema, el.SimpleType)
+	case el.Type == "":
+		return "xs:string", nil
+	case !isBuiltin(el.Type):
+		if st := findSimpleType(m.schema, localName(el.Type)); st != nil {
+			return simpleBase(m.schema, st)
+		}
+	}
+	return el.Type, nil
+}
+
+// simpleBase returns the built-in base of st and its own restriction, as walker.simpleValue uses them.
+func simpleBase(schema *model.XSDSchema, st *model.XSDSimpleType) (string, *model.XSDRestriction) {
+	r := st.Restriction
+	if r == nil {
+		return "xs:string", nil
+	}
+	base := r.Base
+	for seen := map[string]bool{}; !isBuiltin(base) && !seen[base]; {
+		seen[base] = true
+		next := findSimpleType(schema, localName(base))
+		if next == nil || next.Restriction == nil {
+			break
+		}
+		base = next.Restriction.Base
+	}
+	return base, r
+}
+
+// effectiveRestriction returns the restriction of st merged with the ones of the named simple types it
+// restricts, down to its built-in base: the facets st leaves out are inherited. Of several patterns,
+// which cannot be combined into one, the most derived is kept. Restrictions of built-in types are
+// returned as is.
+func effectiveRestriction(schema *model.XSDSchema, st *model.XSDSimpleType) *model.XSDRestriction {
+	if st.Restriction == nil || isBuiltin(st.Restriction.Base) {
+		return st.Restriction
+	}
+	merged := *st.Restriction
+	for seen := map[string]bool{}; !isBuiltin(merged.Base) && !seen[merged.Base]; {
+		seen[merged.Base] = true
+		next := findSimpleType(schema, localName(merged.Base))
+		if next == nil || next.Restriction == nil {
+			break
+		}
+		inheritFacets(&merged, next.Restriction)
+		merged.Base = next.Restriction.Base
+	}
+	return &merged
+}
+
+// inheritFacets copies into r the facets of base that r does not set.
+func inheritFacets(r, base *model.XSDRestriction) {
+	for _, f := range []struct{ facet, inherited **model.XSDValue }{
+		{&r.MinIncl, &base.MinIncl}, {&r.MaxIncl, &base.MaxIncl},
+		{&r.MinExcl, &base.MinExcl}, {&r.MaxExcl, &base.MaxExcl},
+		{&r.Length, &base.Length}, {&r.MinLength, &base.MinLength}, {&r.MaxLength, &base.MaxLength},
+		{&r.TotalDigits, &base.TotalDigits}, {&r.FractionDigits, &base.FractionDigits},
+	} {
+		if *f.facet == nil {
+			*f.facet = *f.inherited
+		}
+	}
+	if r.Pattern == nil {
+		r.Pattern = base.Pattern
+	}
+	if len(r.Enumerations) == 0 {
+		r.Enumerations = base.Enumerations
+	}
+}
+
+func findSimpleType(schema *model.XSDSchema, name string) *model.XSDSimpleType {
+	for i := range schema.SimpleTypes {
+		if schema.SimpleTypes[i].Name == name {
+			return &schema.SimpleTypes[i]
+		}
+	}
+	return nil
+}
+
+// childDeclarations maps the names of the child elements of ct, in any group, to their declarations.
+// Names declared twice, and references to substitution group heads, are left out.
+func childDeclarations(schema *model.XSDSchema, ct *model.XSDComplexType) map[string]*model.XSDElement {
+	decls := map[string]*model.XSDElement{}
+	ambiguous := map[string]bool{}
+	declare := func(name string, el *model.XSDElement) {
+		if _, ok := decls[name]; ok {
+			ambiguous[name] = true
+		}
+		decls[name] = el
+	}
+	var visit func(particles []model.XSDParticle)
+	visit = func(particles []model.XSDParticle) {
+		for _, p := range particles {
+			switch {
+			case p.Element != nil && p.Element.Ref != "":
+				head := globalElement(schema, p.Element.Ref)
+				if head == nil {
+					continue
+				}
+				for _, c := range substitutes(schema, head) {
+					declare(c.Name, c)
+				}
+			case p.Element != nil:
+				declare(p.Element.Name, p.Element)
+			case p.Sequence != nil:
+				visit(p.Sequence.Particles())
+			case p.Choice != nil:
+				visit(p.Choice.Particles())
+			}
+		}
+	}
+	if ct.Sequence != nil {
+		visit(ct.Sequence.Particles())
+	}
+	if ct.Choice != nil {
+		visit(ct.Choice.Particles())
+	}
+	for name := range ambiguous {
+		delete(decls, name)
+	}
+	return decls
+}
+
+// elementPath returns the location of elem, with the position of elements sharing their name with a
+// sibling, as in "/order/line[2]/price".
+func elementPath(elem *etree.Element) string {
+	var parts []string
+	for e := elem; e != nil && e.Tag != ""; e = e.Parent() {
+		part := e.Tag
+		if parent := e.Parent(); parent != nil {
+			position, count := 0, 0
+			for _, sibling := range parent.ChildElements() {
+				if sibling.Tag == e.Tag && sibling.Space == e.Space {
+					count++
+					if sibling == e {
+						position = count
+					}
+				}
+			}
+			if count > 1 {
+				part += "[" + strconv.Itoa(position) + "]"
+			}
+		}
+		parts = append(parts, part)
+	}
+	slices.Reverse(parts)
+	return "/" + strings.Join(parts, "/")
+}
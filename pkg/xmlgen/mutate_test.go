@@ -0,0 +1,153 @@
+package xmlgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/parser"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+	"github.com/beevik/etree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMutateViolatesOneConstraintPerDocument(t *testing.T) {
+	schema := testSchema(t, "order.xsd")
+	set, err := New(schema, WithSeed(5), WithOccurrences(MinOccurrences), WithSchemaLocation("")).Mutate(context.Background())
+	require.NoError(t, err)
+
+	valid, err := set.Valid.WriteToString()
+	require.NoError(t, err)
+	paths := map[string]string{}
+	for _, m := range set.Mutations {
+		doc, err := m.Document.WriteToString()
+		require.NoError(t, err)
+		assert.NotEqual(t, valid, doc, m.Name)
+		paths[m.Constraint+" "+m.Path] = m.Message
+	}
+	for _, want := range []string{
+		"fixed /order/@version",
+		"unexpected /order/unexpectedElement",
+		"pattern /order/id",
+		"minOccurs /order/id",
+		"maxOccurs /order/id",
+		"maxOccurs /order/line",
+		"use /order/line/@sku",
+		"type /order/line/quantity",
+		"minInclusive /order/line/quantity",
+		"maxInclusive /order/line/quantity",
+		"enumeration /order/line/status",
+		"type /order/placed",
+	} {
+		assert.Contains(t, paths, want)
+	}
+	assert.Equal(t, "value 0 violates minInclusive 1", paths["minInclusive /order/line/quantity"])
+	assert.NotContains(t, paths, "type /order/line/status", "enumerations replace the type violation")
+	assertOneViolation(t, schema, set)
+
+	var buf bytes.Buffer
+	require.NoError(t, set.WriteManifest(&buf))
+	var manifest []Mutation
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &manifest))
+	require.Len(t, manifest, len(set.Mutations))
+	assert.Equal(t, "001-fixed.xml", manifest[0].Name)
+}
+
+// structural are the constraints whose violations are not in the values of the document.
+var structural = []string{ViolateMinOccurs, ViolateMaxOccurs, ViolateUnexpected, ViolateUse}
+
+// assertOneViolation checks the values of each mutation of set again: the mutations of a value break
+// exactly one constraint, at their path, and the other mutations keep every value valid.
+func assertOneViolation(t *testing.T, schema *model.XSDSchema, set *MutationSet) {
+	t.Helper()
+	facets := map[restrictionFacet]*validation.Facets{}
+	for _, m := range set.Mutations {
+		errs := violations(t, schema, m.Document, facets)
+		if slices.Contains(structural, m.Constraint) {
+			assert.Empty(t, errs, m.Name)
+			continue
+		}
+		if assert.Len(t, errs, 1, "%s breaks one constraint: %v", m.Name, errs) {
+			assert.Equal(t, m.Path, errs[0].Path, m.Name)
+			assert.Equal(t, m.Message, errs[0].Message, m.Name)
+		}
+	}
+}
+
+// violations returns the values of doc breaking their type, facets or fixed value, as Mutate finds them,
+// with the facets compiled so far.
+func violations(t *testing.T, schema *model.XSDSchema, doc *etree.Document,
+	facets map[restrictionFacet]*validation.Facets) validation.Errors {
+	t.Helper()
+	root, err := FindRoot(schema, "")
+	require.NoError(t, err)
+	m := &mutator{schema: schema, valid: doc, seen: map[string]bool{}, facets: facets}
+	m.element(doc.Root(), root.Element, nil)
+	return m.invalid
+}
+
+func TestMutateBreaksFacetsAlone(t *testing.T) {
+	schema := testSchema(t, "facets.xsd")
+	for seed := range uint64(10) {
+		set, err := New(schema, WithSeed(seed)).Mutate(context.Background())
+		require.NoError(t, err)
+		assertOneViolation(t, schema, set)
+
+		constraints := map[string]string{}
+		for _, m := range set.Mutations {
+			constraints[m.Path+" "+m.Constraint] = m.Message
+		}
+		for _, want := range []string{
+			"/task/code pattern", "/task/label minLength", "/task/label maxLength", "/task/percent totalDigits",
+			"/task/percent maxInclusive", "/task/@priority enumeration", "/task/rate fractionDigits",
+		} {
+			assert.Contains(t, constraints, want, "seed %d", seed)
+		}
+		assert.NotContains(t, constraints, "/task/code maxLength", "the pattern already bounds the length")
+		assert.NotContains(t, constraints, "/task/rate maxInclusive", "values above 999 have more than 3 digits")
+		assert.Contains(t, constraints["/task/@priority enumeration"], "is not one of 1, 2, 3")
+	}
+}
+
+func TestMutateCompleteSchema(t *testing.T) {
+	schema, err := parser.ParseXSD(filepath.Join("..", "..", "complete.xsd"), nil)
+	require.NoError(t, err)
+	for seed := range uint64(6) {
+		set, err := New(schema, WithSeed(seed)).Mutate(context.Background())
+		require.NoError(t, err, "seed %d", seed)
+		assert.NotEmpty(t, set.Mutations)
+		assertOneViolation(t, schema, set)
+	}
+}
+
+func TestMutateStartsFromAValidDocument(t *testing.T) {
+	schema := testSchema(t, "order.xsd")
+	for seed := range uint64(20) {
+		set, err := New(schema, WithSeed(seed)).Mutate(context.Background())
+		require.NoError(t, err, "seed %d", seed)
+		for _, quantity := range set.Valid.FindElements("//quantity") {
+			n, err := strconv.Atoi(quantity.Text())
+			require.NoError(t, err)
+			assert.True(t, n >= 1 && n <= 10, "seed %d: quantity %d breaks its bounds", seed, n)
+		}
+	}
+
+	rules := WithRules(Rules{{Path: "/order/line/quantity", Value: "4096"}})
+	_, err := New(schema, WithSeed(5), rules).Mutate(context.Background())
+	assert.ErrorContains(t, err, "the document to mutate is not valid")
+	assert.ErrorContains(t, err, "value 4096 violates maxInclusive")
+}
+
+func TestElementPath(t *testing.T) {
+	set, err := New(testSchema(t, "catalog.xsd"), WithSeed(1), WithOccurrences(MaxOccurrences(2)), shallow).Mutate(context.Background())
+	require.NoError(t, err)
+	root := set.Valid.Root()
+	assert.Equal(t, "/catalog", elementPath(root))
+	assert.Equal(t, "/catalog/category[2]/title", elementPath(root.SelectElements("category")[1].SelectElement("title")))
+}
@@ -0,0 +1,322 @@
+package xmlgen
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"gopkg.in/yaml.v3"
+)
+
+// Plugin is a value generator running as a subprocess, so that generators can be written in any
+// language. It speaks JSON over the standard input and output of the process, one object per line.
+// Each value is asked with a request such as
+//
+//	{"path":"/manifest/container[2]/number","name":"number","type":"tns:ContainerNumber",
+//	 "base":"xs:string","facets":{"pattern":"[A-Z]{4}\\d{7}"},"occurrence":1,
+//	 "parent":{"owner":"MSC"},"seed":8815542383744567121}
+//
+// with "namespace" and "attribute" too when they are set, and answered with {"value":"MSCU3054383"},
+// with {"skip":true} to leave the value to other generators, or with {"error":"..."}, which fails the
+// generation. seed is drawn from the Rand of the document, so that plugins drawing from it keep seeded
+// documents reproducible. Whatever the process writes to its standard error is passed on.
+//
+// The parent values of attributes hold the children of their element only for plugins reading them,
+// set with the parent option of Plugins.
+//
+// A Plugin serves one request at a time. Once it fails, because the process stopped, answered with
+// invalid JSON or with an error, it declines every value and Err reports why.
+type Plugin struct {
+	command []string
+	parent  bool // whether the plugin reads the parent values of attributes
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *json.Decoder
+	err    error
+}
+
+// pluginRequest is the JSON request of a value sent to plugins.
+type pluginRequest struct {
+	Path       string            `json:"path"`
+	Name       string            `json:"name"`
+	Namespace  string            `json:"namespace,omitempty"`
+	Attribute  bool              `json:"attribute,omitempty"`
+	Type       string            `json:"type,omitempty"`
+	Base       string            `json:"base"`
+	Facets     *pluginFacets     `json:"facets,omitempty"`
+	Occurrence int               `json:"occurrence"`
+	Parent     map[string]string `json:"parent,omitempty"`
+	Seed       uint64            `json:"seed"`
+}
+
+// pluginFacets are the facets of the restriction of a value, in lexical form.
+type pluginFacets struct {
+	MinInclusive   string   `json:"minInclusive,omitempty"`
+	MaxInclusive   string   `json:"maxInclusive,omitempty"`
+	MinExclusive   string   `json:"minExclusive,omitempty"`
+	MaxExclusive   string   `json:"maxExclusive,omitempty"`
+	Length         string   `json:"length,omitempty"`
+	MinLength      string   `json:"minLength,omitempty"`
+	MaxLength      string   `json:"maxLength,omitempty"`
+	TotalDigits    string   `json:"totalDigits,omitempty"`
+	FractionDigits string   `json:"fractionDigits,omitempty"`
+	Pattern        string   `json:"pattern,omitempty"`
+	Enumerations   []string `json:"enumerations,omitempty"`
+}
+
+// pluginResponse is the JSON answer of plugins.
+type pluginResponse struct {
+	Value *string `json:"value"`
+	Skip  bool    `json:"skip"`
+	Error string  `json:"error"`
+}
+
+// StartPlugin starts the plugin run by command, the path of an executable followed by its arguments.
+// Close stops it.
+func StartPlugin(command ...string) (*Plugin, error) {
+	if len(command) == 0 {
+		return nil, errors.New("empty plugin command")
+	}
+	cmd := exec.Command(command[0], command[1:]...) //nolint:gosec // plugins are configured by the user
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start plugin %s: %w", strings.Join(command, " "), err)
+	}
+	return &Plugin{command: command, cmd: cmd, stdin: stdin, stdout: json.NewDecoder(bufio.NewReader(stdout))}, nil
+}
+
+// Generate implements helpers.ValueGenerator.
+func (p *Plugin) Generate(vc *helpers.ValueContext) (string, bool) {
+	req := pluginRequest{
+		Path:       vc.Path,
+		Name:       vc.Name.Local,
+		Namespace:  vc.Name.Space,
+		Attribute:  vc.Attribute,
+		Type:       vc.Type,
+		Base:       vc.Base,
+		Occurrence: vc.Occurrence,
+		Parent:     vc.Parent,
+		Seed:       vc.Rand.Uint64(),
+	}
+	if f := vc.Facets(); f != nil {
+		req.Facets = &pluginFacets{
+			MinInclusive: f.MinInclusive, MaxInclusive: f.MaxInclusive,
+			MinExclusive: f.MinExclusive, MaxExclusive: f.MaxExclusive,
+			Length: f.Length, MinLength: f.MinLength, MaxLength: f.MaxLength,
+			TotalDigits: f.TotalDigits, FractionDigits: f.FractionDigits,
+			Pattern: f.Pattern, Enumerations: f.Enumerations,
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return "", false
+	}
+	resp, err := p.call(&req)
+	if err != nil {
+		p.err = fmt.Errorf("plugin %s: %s: %w", strings.Join(p.command, " "), vc.Path, err)
+		return "", false
+	}
+	if resp.Skip || resp.Value == nil {
+		return "", false
+	}
+	return *resp.Value, true
+}
+
+// ReadsParent implements helpers.ParentReader.
+func (p *Plugin) ReadsParent() bool {
+	return p.parent
+}
+
+// call sends req to the process and reads its response.
+func (p *Plugin) call(req *pluginRequest) (pluginResponse, error) {
+	var resp pluginResponse
+	line, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+	if _, err := p.stdin.Write(append(line, '\n')); err != nil {
+		return resp, err
+	}
+	if err := p.stdout.Decode(&resp); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return resp, fmt.Errorf("read response: %w", err)
+	}
+	if resp.Error != "" {
+		return resp, errors.New(resp.Error)
+	}
+	if resp.Value == nil && !resp.Skip {
+		return resp, errors.New("response has neither value nor skip")
+	}
+	return resp, nil
+}
+
+// Err returns the error the plugin failed with, or nil.
+func (p *Plugin) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// Close closes the standard input of the process, which should exit then, and waits for it.
+func (p *Plugin) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cmd == nil {
+		return nil
+	}
+	err := p.stdin.Close()
+	if waitErr := p.cmd.Wait(); waitErr != nil {
+		err = fmt.Errorf("plugin %s: %w", strings.Join(p.command, " "), waitErr)
+	}
+	p.cmd = nil
+	return err
+}
+
+// Plugins configures the plugins of a Registry, usually loaded from a YAML or JSON file with
+// LoadPlugins. Each maps paths, simple type QNames or built-in types to the command of a plugin:
+//
+//	types:
+//	  ContainerNumber: python3 plugins/iso6346.py
+//	paths:
+//	  //lei: [./bin/lei, --country, FR]
+//	builtins:
+//	  xs:anyURI: node plugins/uri.js
+//	parent: true
+//
+// A command is a list of arguments, or a string split at spaces. Plugins with the same command share
+// one process. With parent, the attributes the plugins generate come after the content of their element,
+// so that their parent values hold its children, which holds streamed elements until their end. Paths are registered in the order of the file, which decides between paths matching
+// the same node.
+type Plugins struct {
+	Paths    PluginPaths              `json:"paths" yaml:"paths"`
+	Types    map[string]PluginCommand `json:"types" yaml:"types"`
+	Builtins map[string]PluginCommand `json:"builtins" yaml:"builtins"`
+	Parent   bool                     `json:"parent" yaml:"parent"`
+}
+
+// PluginPaths maps paths to the commands of their plugins, in order.
+type PluginPaths []PluginPath
+
+// PluginPath is the command of the plugin generating the values of the nodes matching Path.
+type PluginPath struct {
+	Path    string
+	Command PluginCommand
+}
+
+// UnmarshalYAML reads a mapping of paths to commands, keeping its order.
+func (p *PluginPaths) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: paths must map paths to plugin commands", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		entry := PluginPath{Path: key.Value}
+		if err := value.Decode(&entry.Command); err != nil {
+			return fmt.Errorf("path %s: %w", key.Value, err)
+		}
+		*p = append(*p, entry)
+	}
+	return nil
+}
+
+// PluginCommand is the command starting a plugin: the executable and its arguments.
+type PluginCommand []string
+
+// UnmarshalYAML accepts a list of arguments or a string of arguments separated by spaces.
+func (c *PluginCommand) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*c = strings.Fields(node.Value)
+		return nil
+	}
+	var args []string
+	if err := node.Decode(&args); err != nil {
+		return err
+	}
+	*c = args
+	return nil
+}
+
+// LoadPlugins reads the plugins configured in the YAML or JSON file at path and starts them. The
+// registry must be closed to stop them.
+func LoadPlugins(path string) (*Registry, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is given by the user on the command line
+	if err != nil {
+		return nil, fmt.Errorf("read plugins: %w", err)
+	}
+	var plugins Plugins
+	if err := yaml.Unmarshal(data, &plugins); err != nil {
+		return nil, fmt.Errorf("parse plugins %s: %w", path, err)
+	}
+	return plugins.Start()
+}
+
+// Start starts the plugins and returns a registry of them. When one of them fails to start, the ones
+// already started are stopped.
+func (p Plugins) Start() (*Registry, error) {
+	registry := NewRegistry()
+	started := map[string]*Plugin{}
+	start := func(command PluginCommand) (*Plugin, error) {
+		key := strings.Join(command, "\x00")
+		if plugin, ok := started[key]; ok {
+			return plugin, nil
+		}
+		plugin, err := StartPlugin(command...)
+		if err != nil {
+			return nil, err
+		}
+		plugin.parent = p.Parent
+		started[key] = plugin
+		return plugin, nil
+	}
+	fail := func(err error) (*Registry, error) {
+		for _, plugin := range started {
+			_ = plugin.Close()
+		}
+		return nil, err
+	}
+	for _, entry := range p.Paths {
+		plugin, err := start(entry.Command)
+		if err != nil {
+			return fail(err)
+		}
+		if err := registry.RegisterPath(entry.Path, plugin); err != nil {
+			return fail(err)
+		}
+	}
+	for _, kind := range []struct {
+		commands map[string]PluginCommand
+		register func(string, helpers.ValueGenerator)
+	}{{p.Types, registry.RegisterType}, {p.Builtins, registry.RegisterBuiltin}} {
+		for _, name := range slices.Sorted(maps.Keys(kind.commands)) {
+			plugin, err := start(kind.commands[name])
+			if err != nil {
+				return fail(err)
+			}
+			kind.register(name, plugin)
+		}
+	}
+	return registry, nil
+}
@@ -0,0 +1,128 @@
+package xmlgen
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pluginEnv makes the test binary serve as a plugin, answering as servePlugin describes.
+const pluginEnv = "XMLGEN_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(pluginEnv); mode != "" {
+		servePlugin(mode)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// servePlugin answers the requests read from the standard input: seals get their name in upper case
+// followed by the seed, other values are skipped. In mode "error" every request fails, and in mode
+// "crash" the process exits on the first request.
+func servePlugin(mode string) {
+	scanner := bufio.NewScanner(os.Stdin)
+	out := json.NewEncoder(os.Stdout)
+	for scanner.Scan() {
+		var req pluginRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			_ = out.Encode(map[string]string{"error": err.Error()})
+			continue
+		}
+		switch {
+		case mode == "crash":
+			return
+		case mode == "error":
+			_ = out.Encode(map[string]string{"error": "no code for " + req.Path})
+		case req.Name == "seal":
+			_ = out.Encode(map[string]string{"value": fmt.Sprintf("%s-%d", strings.ToUpper(req.Name), req.Seed%1000)})
+		default:
+			_ = out.Encode(map[string]bool{"skip": true})
+		}
+	}
+}
+
+// startTestPlugin starts the test binary as a plugin in mode.
+func startTestPlugin(t *testing.T, mode string) *Plugin {
+	t.Helper()
+	t.Setenv(pluginEnv, mode)
+	plugin, err := StartPlugin(os.Args[0])
+	require.NoError(t, err)
+	return plugin
+}
+
+func TestPluginGeneratesValues(t *testing.T) {
+	plugin := startTestPlugin(t, "serve")
+	vc := &helpers.ValueContext{Path: "/manifest/container[1]/seal", Base: "xs:string", Rand: helpers.NewRand(1)}
+	vc.Name.Local = "seal"
+	value, ok := plugin.Generate(vc)
+	assert.True(t, ok)
+	assert.Regexp(t, `^SEAL-\d+$`, value)
+
+	vc.Name.Local = "number"
+	_, ok = plugin.Generate(vc)
+	assert.False(t, ok, "skipped values are declined")
+	assert.NoError(t, plugin.Err())
+	assert.NoError(t, plugin.Close())
+}
+
+func TestPluginFailures(t *testing.T) {
+	for mode, want := range map[string]string{"error": "no code for /seal", "crash": "unexpected EOF"} {
+		t.Run(mode, func(t *testing.T) {
+			plugin := startTestPlugin(t, mode)
+			defer plugin.Close()
+			vc := &helpers.ValueContext{Path: "/seal", Base: "xs:string"}
+			_, ok := plugin.Generate(vc)
+			assert.False(t, ok)
+			assert.ErrorContains(t, plugin.Err(), want)
+			_, ok = plugin.Generate(vc)
+			assert.False(t, ok, "failed plugins decline every value")
+		})
+	}
+	_, err := StartPlugin(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestLoadPlugins(t *testing.T) {
+	t.Setenv(pluginEnv, "serve")
+	command, err := json.Marshal([]string{os.Args[0]})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "plugins.json")
+	config := fmt.Sprintf(`{"paths": {"//seal": %s}, "types": {"ContainerNumber": %s}}`, command, command)
+	require.NoError(t, os.WriteFile(path, []byte(config), 0o600))
+
+	registry, err := LoadPlugins(path)
+	require.NoError(t, err)
+	defer registry.Close()
+	assert.Same(t, registry.paths[0].gen, registry.types["ContainerNumber"], "plugins with the same command share a process")
+	assert.False(t, registry.ReadsParent(), "plugins read the parent values of attributes when configured to")
+
+	doc, err := New(testSchema(t, "manifest.xsd"), WithSeed(3), WithGenerators(registry)).Generate(context.Background())
+	require.NoError(t, err)
+	for _, seal := range doc.FindElements("//seal") {
+		assert.Regexp(t, `^SEAL-\d+$`, seal.Text())
+	}
+	again, err := New(testSchema(t, "manifest.xsd"), WithSeed(3), WithGenerators(registry)).Generate(context.Background())
+	require.NoError(t, err)
+	first, _ := doc.WriteToString()
+	second, _ := again.WriteToString()
+	assert.Equal(t, first, second, "plugins drawing from the seed keep documents reproducible")
+
+	require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf(`{"paths": {"//seal": %s}, "parent": true}`, command)), 0o600))
+	readers, err := LoadPlugins(path)
+	require.NoError(t, err)
+	defer readers.Close()
+	assert.True(t, readers.ReadsParent())
+
+	_, err = LoadPlugins(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
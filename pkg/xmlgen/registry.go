@@ -0,0 +1,187 @@
+package xmlgen
+
+import (
+	"errors"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+)
+
+// Registry is a helpers.ValueGenerator dispatching each value to the generator registered for its path,
+// else for its simple type, else for its built-in type, and declining the values none of them produces.
+// It lets domain types whose values a pattern cannot describe, such as codes with check digits, get
+// valid values. Registries are set with WithGenerators, or used as any value generator.
+type Registry struct {
+	paths    []registeredPath
+	types    map[string]helpers.ValueGenerator
+	builtins map[string]helpers.ValueGenerator
+}
+
+// registeredPath is a generator registered for the nodes matching a path.
+type registeredPath struct {
+	path  string
+	steps pathPattern
+	gen   helpers.ValueGenerator
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{types: map[string]helpers.ValueGenerator{}, builtins: map[string]helpers.ValueGenerator{}}
+}
+
+// RegisterPath registers g for the elements and attributes matching path, written as in Rules:
+// "/order/container/number", "//lei" or "/order/**/@code". When several paths match a node, the first
+// one registered wins.
+func (r *Registry) RegisterPath(path string, g helpers.ValueGenerator) error {
+	steps, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	r.paths = append(r.paths, registeredPath{path: path, steps: steps, gen: g})
+	return nil
+}
+
+// RegisterType registers g for the values of the simple type qname. A prefixed QName, such as
+// "tns:ContainerNumber", matches the type written with that prefix only, and a local name any prefix.
+// Registering a type again replaces its generator.
+func (r *Registry) RegisterType(qname string, g helpers.ValueGenerator) {
+	r.types[qname] = g
+}
+
+// RegisterBuiltin registers g for the values deriving from the built-in type xsdType, such as
+// "xs:anyURI", whatever prefix the schema binds to XML Schema, that no path or type generator produces.
+func (r *Registry) RegisterBuiltin(xsdType string, g helpers.ValueGenerator) {
+	r.builtins[localName(xsdType)] = g
+}
+
+// Generate implements helpers.ValueGenerator. A registered generator declining a value leaves it to the
+// generators registered less specifically.
+func (r *Registry) Generate(vc *helpers.ValueContext) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	if len(r.paths) > 0 {
+		names := pathNames(vc.Path)
+		for _, p := range r.paths {
+			if !p.steps.matches(names) {
+				continue
+			}
+			if value, ok := p.gen.Generate(vc); ok {
+				return value, true
+			}
+		}
+	}
+	for _, name := range []string{vc.Type, localName(vc.Type)} {
+		if g, ok := r.types[name]; ok && name != "" {
+			if value, ok := g.Generate(vc); ok {
+				return value, true
+			}
+			break
+		}
+	}
+	if g, ok := r.builtins[localName(vc.Base)]; ok {
+		return g.Generate(vc)
+	}
+	return "", false
+}
+
+// ReadsParent implements helpers.ParentReader: it reports whether one of the registered generators
+// reads Parent.
+func (r *Registry) ReadsParent() bool {
+	reads := false
+	r.each(func(g helpers.ValueGenerator) {
+		reads = reads || helpers.ReadsParent(g)
+	})
+	return reads
+}
+
+// readsParent reports whether one of the generators that may be asked for the value of the node at
+// names, the steps of its path, of the simple type typeName deriving from the built-in type base reads
+// Parent.
+func (r *Registry) readsParent(names []string, typeName, base string) bool {
+	if r == nil {
+		return false
+	}
+	for _, p := range r.paths {
+		if p.steps.matches(names) && helpers.ReadsParent(p.gen) {
+			return true
+		}
+	}
+	for _, name := range []string{typeName, localName(typeName)} {
+		if g, ok := r.types[name]; ok && name != "" {
+			if helpers.ReadsParent(g) {
+				return true
+			}
+			break
+		}
+	}
+	return helpers.ReadsParent(r.builtins[localName(base)])
+}
+
+// Err returns the errors of the registered generators that report one, such as plugins that stopped.
+func (r *Registry) Err() error {
+	if r == nil {
+		return nil
+	}
+	var errs []error
+	r.each(func(g helpers.ValueGenerator) {
+		if e, ok := g.(interface{ Err() error }); ok {
+			errs = append(errs, e.Err())
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// Close closes the registered generators that are io.Closers, such as plugins, once each.
+func (r *Registry) Close() error {
+	if r == nil {
+		return nil
+	}
+	var errs []error
+	r.each(func(g helpers.ValueGenerator) {
+		if c, ok := g.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// each calls fn with the generators of r, once each, in a stable order.
+func (r *Registry) each(fn func(helpers.ValueGenerator)) {
+	gens := make([]helpers.ValueGenerator, 0, len(r.paths)+len(r.types)+len(r.builtins))
+	for _, p := range r.paths {
+		gens = append(gens, p.gen)
+	}
+	for _, key := range slices.Sorted(maps.Keys(r.types)) {
+		gens = append(gens, r.types[key])
+	}
+	for _, key := range slices.Sorted(maps.Keys(r.builtins)) {
+		gens = append(gens, r.builtins[key])
+	}
+	seen := map[any]bool{}
+	for _, g := range gens {
+		if reflect.ValueOf(g).Comparable() {
+			if seen[g] {
+				continue
+			}
+			seen[g] = true
+		}
+		fn(g)
+	}
+}
+
+// pathNames returns the names of the steps of the path of a value, without their positions:
+// "/order/line[2]/@id" gives order, line, @id.
+func pathNames(p string) []string {
+	steps := strings.Split(strings.TrimPrefix(p, "/"), "/")
+	for i, step := range steps {
+		if j := strings.IndexByte(step, '['); j >= 0 {
+			steps[i] = step[:j]
+		}
+	}
+	return steps
+}
@@ -0,0 +1,125 @@
+package xmlgen
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkDigit returns the ISO 6346 check digit of the first ten characters of a container number.
+func checkDigit(code string) int {
+	sum := 0
+	for i, c := range code[:10] {
+		v := int(c - '0')
+		if c >= 'A' {
+			// Letters count from 10, skipping the multiples of 11.
+			v = int(c-'A') + 10
+			v += (v - 1) / 10
+		}
+		sum += v << i
+	}
+	return sum % 11 % 10
+}
+
+// containerNumbers generates valid ISO 6346 container numbers.
+var containerNumbers = helpers.ValueGeneratorFunc(func(vc *helpers.ValueContext) (string, bool) {
+	code := "MSCU" + strconv.Itoa(100000+vc.Rand.Intn(900000))
+	return code + strconv.Itoa(checkDigit(code)), true
+})
+
+func TestCheckDigit(t *testing.T) {
+	assert.Equal(t, 3, checkDigit("CSQU3054383"))
+}
+
+func TestRegistryPrecedence(t *testing.T) {
+	constant := func(value string) helpers.ValueGenerator {
+		return helpers.ValueGeneratorFunc(func(*helpers.ValueContext) (string, bool) { return value, true })
+	}
+	decline := helpers.ValueGeneratorFunc(func(*helpers.ValueContext) (string, bool) { return "", false })
+	r := NewRegistry()
+	require.NoError(t, r.RegisterPath("//container/seal", constant("path")))
+	require.NoError(t, r.RegisterPath("//container/*", decline))
+	r.RegisterType("ContainerNumber", constant("type"))
+	r.RegisterType("other:ContainerNumber", constant("other type"))
+	r.RegisterBuiltin("xs:string", constant("built-in"))
+	assert.Error(t, r.RegisterPath("/a[", decline))
+
+	cases := map[string]helpers.ValueContext{
+		"path":       {Path: "/manifest/container[2]/seal", Base: "xs:string"},
+		"type":       {Path: "/manifest/container[2]/number", Type: "tns:ContainerNumber", Base: "xs:string"},
+		"other type": {Path: "/manifest/container[1]/number", Type: "other:ContainerNumber", Base: "xs:string"},
+		"built-in":   {Path: "/manifest/carrier/lei", Base: "xsd:string"},
+	}
+	for want, vc := range cases {
+		value, ok := r.Generate(&vc)
+		assert.True(t, ok)
+		assert.Equal(t, want, value, vc.Path)
+	}
+	_, ok := r.Generate(&helpers.ValueContext{Path: "/manifest/carrier/website", Base: "xs:anyURI"})
+	assert.False(t, ok, "values without generator are declined")
+}
+
+func TestGeneratorWithGenerators(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterType("ContainerNumber", containerNumbers)
+	require.NoError(t, registry.RegisterPath("/manifest/carrier/lei", helpers.ValueGeneratorFunc(
+		func(*helpers.ValueContext) (string, bool) { return "5493001KJTIIGC8Y1R12", true })))
+	registry.RegisterBuiltin("xs:anyURI", helpers.ValueGeneratorFunc(
+		func(vc *helpers.ValueContext) (string, bool) { return "https://example.com/" + vc.Name.Local, true }))
+
+	doc, err := New(testSchema(t, "manifest.xsd"), WithSeed(1), WithMode(ModeMax), WithGenerators(registry)).Generate(context.Background())
+	require.NoError(t, err)
+	numbers := doc.FindElements("//container/number")
+	require.NotEmpty(t, numbers)
+	for _, number := range numbers {
+		n := number.Text()
+		assert.Equal(t, strconv.Itoa(checkDigit(n)), n[10:], "%s has a valid check digit", n)
+	}
+	assert.Equal(t, "5493001KJTIIGC8Y1R12", doc.FindElement("//lei").Text())
+	assert.Equal(t, "https://example.com/website", doc.FindElement("//website").Text())
+	for _, seal := range doc.FindElements("//seal") {
+		assert.False(t, strings.HasPrefix(seal.Text(), "https:"), "other values keep their generator")
+	}
+}
+
+func TestRegistryReadsParent(t *testing.T) {
+	sku := helpers.ReadingParent(helpers.ValueGeneratorFunc(func(vc *helpers.ValueContext) (string, bool) {
+		return vc.Parent["status"] + "-" + vc.Parent["quantity"], true
+	}))
+	registry := NewRegistry()
+	assert.False(t, registry.ReadsParent())
+	require.NoError(t, registry.RegisterPath("//line/@sku", sku))
+	registry.RegisterType("status", sku)
+	assert.True(t, registry.ReadsParent())
+	assert.True(t, registry.readsParent([]string{"order", "line", "@sku"}, "xs:string", "xs:string"))
+	assert.True(t, registry.readsParent([]string{"order", "@code"}, "status", "xs:string"))
+	assert.False(t, registry.readsParent([]string{"order", "@version"}, "xs:string", "xs:string"))
+
+	doc, err := New(testSchema(t, "order.xsd"), WithSeed(2), WithGenerators(registry)).Generate(context.Background())
+	require.NoError(t, err)
+	for _, line := range doc.FindElements("//line") {
+		want := line.SelectElement("status").Text() + "-" + line.SelectElement("quantity").Text()
+		assert.Equal(t, want, line.SelectAttrValue("sku", ""), "the attributes of readers come after the content")
+	}
+}
+
+func TestGeneratorFailsWithRegistryErrors(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterBuiltin("xs:string", failing{})
+	_, err := New(testSchema(t, "manifest.xsd"), WithSeed(1), WithGenerators(registry)).Generate(context.Background())
+	assert.ErrorContains(t, err, "out of numbers")
+	assert.NoError(t, registry.Close())
+}
+
+// failing is a generator that failed, like a plugin whose process stopped.
+type failing struct{}
+
+func (failing) Generate(*helpers.ValueContext) (string, bool) { return "", false }
+
+func (failing) Err() error { return errors.New("out of numbers") }
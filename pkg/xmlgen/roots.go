@@ -0,0 +1,134 @@
+package xmlgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+)
+
+// Root describes a global element that can be the root of a generated document.
+type Root struct {
+	Element   *model.XSDElement
+	Namespace string // target namespace of the schema declaring the element
+	Type      string // declared type, or "(anonymous complexType)" / "(anonymous simpleType)"
+	Complex   bool   // the element has complex content
+}
+
+// QName returns the name of the root prefixed with the prefix the schema binds to its namespace, or its
+// local name when there is none.
+func (r Root) QName(schema *model.XSDSchema) string {
+	if r.Namespace == "" {
+		return r.Element.Name
+	}
+	var prefixes []string
+	for prefix, ns := range schema.Namespaces() {
+		if ns == r.Namespace && prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	if len(prefixes) == 0 {
+		return r.Element.Name
+	}
+	sort.Strings(prefixes)
+	return prefixes[0] + ":" + r.Element.Name
+}
+
+// Roots returns every global element of the schema in declaration order.
+func Roots(schema *model.XSDSchema) []Root {
+	roots := make([]Root, 0, len(schema.Elements))
+	for i := range schema.Elements {
+		el := &schema.Elements[i]
+		root := Root{Element: el, Namespace: schema.TargetNamespace, Type: el.Type}
+		if el.Origin != nil {
+			root.Namespace = el.Origin.TargetNamespace
+		}
+		switch {
+		case el.ComplexType != nil:
+			root.Type, root.Complex = "(anonymous complexType)", true
+		case el.SimpleType != nil:
+			root.Type = "(anonymous simpleType)"
+		default:
+			root.Complex = isComplexType(schema, el.Type)
+		}
+		roots = append(roots, root)
+	}
+	return roots
+}
+
+// FindRoot returns the global element called name, which is either a local name, a name prefixed with
+// one of the prefixes declared on the schema, or a {namespace}local name. An empty name selects the first
+// global element with complex content. Local names matching elements of several namespaces are rejected.
+func FindRoot(schema *model.XSDSchema, name string) (Root, error) {
+	roots := Roots(schema)
+	if name == "" {
+		for _, r := range roots {
+			if r.Complex {
+				return r, nil
+			}
+		}
+		if len(roots) > 0 {
+			return roots[0], nil
+		}
+		return Root{}, fmt.Errorf("schema declares no global element")
+	}
+
+	ns, local, qualified, err := splitQName(schema, name)
+	if err != nil {
+		return Root{}, err
+	}
+	var matches []Root
+	for _, r := range roots {
+		if r.Element.Name == local && (!qualified || r.Namespace == ns) {
+			matches = append(matches, r)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Root{}, fmt.Errorf("no global element %s, candidates are %s", name, rootNames(schema, roots))
+	case 1:
+		return matches[0], nil
+	default:
+		return Root{}, fmt.Errorf("global element %s is ambiguous, use one of %s", name, rootNames(schema, matches))
+	}
+}
+
+// splitQName resolves a {namespace}local or prefix:local name; qualified is false for bare local names.
+func splitQName(schema *model.XSDSchema, name string) (ns, local string, qualified bool, err error) {
+	if rest, ok := strings.CutPrefix(name, "{"); ok {
+		ns, local, ok = strings.Cut(rest, "}")
+		if !ok {
+			return "", "", false, fmt.Errorf("malformed element name %s", name)
+		}
+		return ns, local, true, nil
+	}
+	prefix, local, ok := strings.Cut(name, ":")
+	if !ok {
+		return "", name, false, nil
+	}
+	ns, ok = schema.Namespaces()[prefix]
+	if !ok {
+		return "", "", false, fmt.Errorf("prefix %s of %s is not declared on the schema", prefix, name)
+	}
+	return ns, local, true, nil
+}
+
+func rootNames(schema *model.XSDSchema, roots []Root) string {
+	names := make([]string, 0, len(roots))
+	for _, r := range roots {
+		names = append(names, r.QName(schema))
+	}
+	return strings.Join(names, ", ")
+}
+
+// isComplexType reports whether typeName, a prefixed name, refers to a complex type of the schema.
+func isComplexType(schema *model.XSDSchema, typeName string) bool {
+	local := typeName[strings.LastIndex(typeName, ":")+1:]
+	for i := range schema.ComplexTypes {
+		if schema.ComplexTypes[i].Name == local {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package xmlgen
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rootsSchema() *model.XSDSchema {
+	other := &model.XSDOrigin{TargetNamespace: "urn:other"}
+	return &model.XSDSchema{
+		TargetNamespace: "urn:orders",
+		Attrs: []xml.Attr{
+			{Name: xml.Name{Space: "xmlns", Local: "tns"}, Value: "urn:orders"},
+			{Name: xml.Name{Space: "xmlns", Local: "o"}, Value: "urn:other"},
+		},
+		ComplexTypes: []model.XSDComplexType{{Name: "OrderType"}},
+		Elements: []model.XSDElement{
+			{Name: "comment", Type: "xs:string"},
+			{Name: "order", Type: "tns:OrderType"},
+			{Name: "note", ComplexType: &model.XSDComplexType{}},
+			{Name: "note", Type: "xs:string", Origin: other},
+		},
+	}
+}
+
+func TestRoots(t *testing.T) {
+	schema := rootsSchema()
+	roots := Roots(schema)
+	require.Len(t, roots, 4)
+	assert.Equal(t, "tns:comment", roots[0].QName(schema))
+	assert.False(t, roots[0].Complex)
+	assert.True(t, roots[1].Complex)
+	assert.Equal(t, "(anonymous complexType)", roots[2].Type)
+	assert.Equal(t, "o:note", roots[3].QName(schema))
+	assert.Equal(t, "urn:other", roots[3].Namespace)
+}
+
+func TestFindRoot(t *testing.T) {
+	schema := rootsSchema()
+	for name, want := range map[string]*model.XSDElement{
+		"":                 &schema.Elements[1],
+		"order":            &schema.Elements[1],
+		"tns:order":        &schema.Elements[1],
+		"{urn:orders}note": &schema.Elements[2],
+		"o:note":           &schema.Elements[3],
+	} {
+		root, err := FindRoot(schema, name)
+		if assert.NoError(t, err, name) {
+			assert.Same(t, want, root.Element, name)
+		}
+	}
+	for name, msg := range map[string]string{
+		"note":        "ambiguous",
+		"missing":     "candidates are tns:comment, tns:order, tns:note, o:note",
+		"x:order":     "prefix x",
+		"{urn:orders": "malformed",
+	} {
+		_, err := FindRoot(schema, name)
+		if assert.Error(t, err, name) {
+			assert.Contains(t, err.Error(), msg)
+		}
+	}
+}
@@ -0,0 +1,449 @@
+package xmlgen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/validation"
+	"gopkg.in/yaml.v3"
+)
+
+// Rules pins or constrains the values and occurrences of the elements and attributes at given paths.
+// They are usually loaded from a YAML or JSON file with LoadRules, keyed by path:
+//
+//	/purchaseOrder/billTo/name: ACME Corp
+//	//country:
+//	  values: [FR, DE, US]
+//	/purchaseOrder/items/item/quantity:
+//	  min: 1
+//	  max: 5
+//	/purchaseOrder/shipDate:
+//	  min: today
+//	  max: today+30d
+//	/purchaseOrder/items/item/@partNum:
+//	  pattern: '\d{3}-[A-Z]{2}'
+//	/purchaseOrder/items/item:
+//	  occurs: 2..4
+//
+// A path is a list of local element names from the root, ending with an element or an "@attribute".
+// Each step may use the wildcards of path.Match, "*" matching any one element, and "**" matches any
+// number of elements, so "//country" is "/**/country". The first rule matching a node decides its
+// value, and the first one with occurs its number of occurrences.
+type Rules []Rule
+
+// Rule constrains the nodes matching Path. It sets at most one of Value, Values, the range Min..Max
+// and Pattern, and Occurs.
+type Rule struct {
+	Path string `json:"-" yaml:"-"`
+	// Value is the value of every matching node. In a file, a rule written as a plain string sets it.
+	Value string `json:"value" yaml:"value"`
+	// Values lists the values picked from at random.
+	Values []string `json:"values" yaml:"values"`
+	// Min and Max bound numbers, dates and dateTimes, inclusively; either may be left out. Dates and
+	// dateTimes may be relative to the time of generation: "today", "today+30d", "now-12h".
+	Min string `json:"min" yaml:"min"`
+	Max string `json:"max" yaml:"max"`
+	// Pattern is an XSD regular expression the generated values match.
+	Pattern string `json:"pattern" yaml:"pattern"`
+	// Occurs is the number of occurrences of matching elements, "3", or a range picked from at random,
+	// "1..3". It is clamped to the minOccurs and maxOccurs of the element, so documents stay valid.
+	Occurs string `json:"occurs" yaml:"occurs"`
+}
+
+// UnmarshalYAML reads rules from a mapping of paths to rules, keeping the order of the file.
+func (r *Rules) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: rules must map paths to rules", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		rule := Rule{Path: key.Value}
+		var err error
+		if value.Kind == yaml.ScalarNode {
+			err = value.Decode(&rule.Value)
+		} else {
+			type plain Rule
+			err = value.Decode((*plain)(&rule))
+		}
+		if err != nil {
+			return fmt.Errorf("rule %s: %w", key.Value, err)
+		}
+		*r = append(*r, rule)
+	}
+	return nil
+}
+
+// LoadRules reads rules from a YAML file. JSON being a subset of YAML, JSON files are accepted too.
+func LoadRules(path string) (Rules, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is given by the user on the command line
+	if err != nil {
+		return nil, fmt.Errorf("read rules: %w", err)
+	}
+	var rules Rules
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parse rules %s: %w", path, err)
+	}
+	if _, err := compileRules(rules, time.Now()); err != nil {
+		return nil, fmt.Errorf("rules %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// Unmatched returns the paths of the rules matching no element or attribute of the documents of schema.
+// Recursive types are followed as long as a rule may still match deeper.
+func (r Rules) Unmatched(schema *model.XSDSchema) []string {
+	compiled, err := compileRules(r, time.Now())
+	if err != nil {
+		return nil
+	}
+	var unmatched []string
+	for _, rule := range compiled {
+		if !rule.reachable(schema) {
+			unmatched = append(unmatched, rule.Path)
+		}
+	}
+	return unmatched
+}
+
+// rule is a Rule compiled against the time of generation.
+type rule struct {
+	Rule
+	steps     pathPattern
+	low, high int // bounds of Occurs
+	now       time.Time
+}
+
+// ruleSet holds the compiled rules of a Generator; a nil ruleSet matches nothing.
+type ruleSet []*rule
+
+func compileRules(rules Rules, now time.Time) (ruleSet, error) {
+	var set ruleSet
+	for _, r := range rules {
+		c, err := compileRule(r, now)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", r.Path, err)
+		}
+		set = append(set, c)
+	}
+	return set, nil
+}
+
+func compileRule(r Rule, now time.Time) (*rule, error) {
+	if !strings.HasPrefix(r.Path, "/") {
+		return nil, errors.New("paths start with /")
+	}
+	steps, err := parsePath(r.Path)
+	if err != nil {
+		return nil, err
+	}
+	c := &rule{Rule: r, steps: steps, now: now}
+	set := 0
+	for _, given := range []bool{r.Value != "", len(r.Values) > 0, r.Min != "" || r.Max != "", r.Pattern != ""} {
+		if given {
+			set++
+		}
+	}
+	if set > 1 {
+		return nil, errors.New("value, values, min/max and pattern are exclusive")
+	}
+	if set == 0 && r.Occurs == "" {
+		return nil, errors.New("no constraint given")
+	}
+	if r.Pattern != "" {
+		if _, err := validation.CompilePattern(r.Pattern); err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	for _, b := range []string{r.Min, r.Max} {
+		if _, _, err := relativeTime(b, now); err != nil {
+			return nil, err
+		}
+	}
+	if r.Occurs != "" {
+		if c.attribute() {
+			return nil, errors.New("attributes have no occurs")
+		}
+		low, high, ranged := strings.Cut(r.Occurs, "..")
+		if !ranged {
+			high = low
+		}
+		l, errLow := strconv.Atoi(low)
+		h, errHigh := strconv.Atoi(high)
+		if errLow != nil || errHigh != nil || l < 0 || h < l {
+			return nil, fmt.Errorf("invalid occurs %q, want n or min..max", r.Occurs)
+		}
+		c.low, c.high = l, h
+	}
+	return c, nil
+}
+
+func (r *rule) attribute() bool {
+	return len(r.steps) > 0 && strings.HasPrefix(r.steps[len(r.steps)-1], "@")
+}
+
+// setsValue reports whether the rule decides the values of the nodes it matches.
+func (r *rule) setsValue() bool {
+	return r.Value != "" || len(r.Values) > 0 || r.Min != "" || r.Max != "" || r.Pattern != ""
+}
+
+// pathPattern is a path of Rules and Constraints split into steps. Its steps form a nondeterministic
+// automaton whose state i means that the first i steps matched; "**" steps may be skipped or consume
+// any element name.
+type pathPattern []string
+
+// parsePath splits an absolute path, or a path relative to a context element, into steps. "." is the
+// context element itself.
+func parsePath(p string) (pathPattern, error) {
+	original := p
+	if p == "." {
+		return pathPattern{}, nil
+	}
+	if strings.HasPrefix(p, "//") {
+		p = "/**/" + p[2:]
+	}
+	p = strings.TrimPrefix(strings.ReplaceAll(p, "//", "/**/"), "/")
+	var steps pathPattern
+	for _, step := range strings.Split(p, "/") {
+		if _, err := path.Match(step, ""); err != nil || step == "" {
+			return nil, fmt.Errorf("invalid step %q in path %s", step, original)
+		}
+		steps = append(steps, step)
+	}
+	for i, step := range steps {
+		if strings.HasPrefix(step, "@") && i != len(steps)-1 {
+			return nil, errors.New("attributes end paths")
+		}
+	}
+	return steps, nil
+}
+
+// start returns the states before the first step.
+func (p pathPattern) start() []bool {
+	return p.closure(make([]bool, len(p)+1), 0)
+}
+
+// closure adds state i, and the states after the "**" steps following it.
+func (p pathPattern) closure(states []bool, i int) []bool {
+	for ; i <= len(p); i++ {
+		states[i] = true
+		if i == len(p) || p[i] != "**" {
+			break
+		}
+	}
+	return states
+}
+
+// next returns the states reached from states by the node name, "@name" for attributes.
+func (p pathPattern) next(states []bool, name string) []bool {
+	next := make([]bool, len(states))
+	for i, ok := range states[:len(p)] {
+		if !ok {
+			continue
+		}
+		step := p[i]
+		if step == "**" {
+			if !strings.HasPrefix(name, "@") {
+				p.closure(next, i)
+			}
+			continue
+		}
+		if strings.HasPrefix(step, "@") != strings.HasPrefix(name, "@") {
+			continue
+		}
+		if matched, _ := path.Match(strings.TrimPrefix(step, "@"), strings.TrimPrefix(name, "@")); matched {
+			p.closure(next, i+1)
+		}
+	}
+	return next
+}
+
+// accepts reports whether states include the final one.
+func (p pathPattern) accepts(states []bool) bool {
+	return states[len(p)]
+}
+
+func (p pathPattern) matches(names []string) bool {
+	states := p.start()
+	for _, name := range names {
+		states = p.next(states, name)
+	}
+	return p.accepts(states)
+}
+
+// reachable reports whether a node of a document of schema matches the rule, walking the declarations
+// from every global element. Each declaration is visited once per set of states, which bounds the walk
+// of recursive types.
+func (r *rule) reachable(schema *model.XSDSchema) bool {
+	seen := map[string]bool{}
+	var visit func(el *model.XSDElement, states []bool) bool
+	visit = func(el *model.XSDElement, states []bool) bool {
+		if el.Ref != "" {
+			head := globalElement(schema, el.Ref)
+			if head == nil {
+				return false
+			}
+			for _, c := range substitutes(schema, head) {
+				if visit(c, states) {
+					return true
+				}
+			}
+			return false
+		}
+		states = r.steps.next(states, el.Name)
+		if !slices.Contains(states, true) {
+			return false
+		}
+		if r.steps.accepts(states) {
+			return true
+		}
+		key := fmt.Sprintf("%p %v", el, states)
+		if seen[key] {
+			return false
+		}
+		seen[key] = true
+		ct := complexTypeOf(schema, el)
+		if ct == nil {
+			return false
+		}
+		for _, attr := range ct.Attrs {
+			if r.steps.accepts(r.steps.next(states, "@"+attr.Name)) {
+				return true
+			}
+		}
+		found := false
+		var particles func(ps []model.XSDParticle)
+		particles = func(ps []model.XSDParticle) {
+			for _, p := range ps {
+				switch {
+				case found:
+					return
+				case p.Element != nil:
+					found = visit(p.Element, states)
+				case p.Sequence != nil:
+					particles(p.Sequence.Particles())
+				case p.Choice != nil:
+					particles(p.Choice.Particles())
+				}
+			}
+		}
+		if ct.Sequence != nil {
+			particles(ct.Sequence.Particles())
+		}
+		if ct.Choice != nil {
+			particles(ct.Choice.Particles())
+		}
+		return found
+	}
+	for i := range schema.Elements {
+		if visit(&schema.Elements[i], r.steps.start()) {
+			return true
+		}
+	}
+	return false
+}
+
+// value returns the first rule deciding the value of the node at names.
+func (s ruleSet) value(names []string) *rule {
+	for _, r := range s {
+		if r.setsValue() && r.steps.matches(names) {
+			return r
+		}
+	}
+	return nil
+}
+
+// occurs returns the number of occurrences of the element at names decided by the first matching rule
+// with occurs, clamped to minCount..maxCount.
+func (s ruleSet) occurs(names []string, rand *helpers.Rand, minCount, maxCount int) (int, bool) {
+	for _, r := range s {
+		if r.Occurs == "" || !r.steps.matches(names) {
+			continue
+		}
+		n := rand.RandomBetween(r.low, r.high)
+		if maxCount != Unbounded {
+			n = min(n, maxCount)
+		}
+		return max(n, minCount), true
+	}
+	return 0, false
+}
+
+// generate returns a value of the built-in type base following the rule.
+func (r *rule) generate(rand *helpers.Rand, base string) (string, error) {
+	switch {
+	case r.Value != "":
+		return r.Value, nil
+	case len(r.Values) > 0:
+		return r.Values[rand.Intn(len(r.Values))], nil
+	case r.Pattern != "":
+		return rand.StringFromPattern(r.Pattern), nil
+	}
+	low, err := r.bound(r.Min, base)
+	if err != nil {
+		return "", err
+	}
+	high, err := r.bound(r.Max, base)
+	if err != nil {
+		return "", err
+	}
+	return rand.ValueInRange(base, low, high)
+}
+
+// bound resolves a bound relative to the time of generation into a literal of base.
+func (r *rule) bound(b, base string) (string, error) {
+	t, date, err := relativeTime(b, r.now)
+	switch {
+	case err != nil || t.IsZero():
+		return b, err
+	case localName(base) == "date":
+		return t.Format("2006-01-02"), nil
+	case localName(base) == "dateTime" && !date:
+		return t.Format(time.RFC3339), nil
+	case localName(base) == "dateTime":
+		return t.Format("2006-01-02") + "T00:00:00Z", nil
+	}
+	return "", fmt.Errorf("relative bound %s needs a date or dateTime, not %s", b, base)
+}
+
+// relativeTime parses "today" or "now", optionally followed by a signed offset in days, "+30d", or in
+// the units of time.ParseDuration, "-12h". It returns the zero time for other bounds, and whether the
+// bound is a day.
+func relativeTime(b string, now time.Time) (time.Time, bool, error) {
+	var t time.Time
+	var rest string
+	date := false
+	switch {
+	case strings.HasPrefix(b, "today"):
+		y, m, d := now.UTC().Date()
+		t, rest, date = time.Date(y, m, d, 0, 0, 0, 0, time.UTC), b[len("today"):], true
+	case strings.HasPrefix(b, "now"):
+		t, rest = now.UTC().Truncate(time.Second), b[len("now"):]
+	default:
+		return time.Time{}, false, nil
+	}
+	switch {
+	case rest == "":
+		return t, date, nil
+	case rest[0] != '+' && rest[0] != '-':
+		return time.Time{}, false, fmt.Errorf("invalid bound %q", b)
+	case strings.HasSuffix(rest, "d"):
+		days, err := strconv.Atoi(rest[:len(rest)-1])
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid bound %q", b)
+		}
+		return t.AddDate(0, 0, days), date, nil
+	}
+	offset, err := time.ParseDuration(rest)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid bound %q", b)
+	}
+	return t.Add(offset), false, nil
+}
@@ -0,0 +1,102 @@
+package xmlgen
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRulesPinAndConstrainValues(t *testing.T) {
+	schema := testSchema(t, "order.xsd")
+	rules, err := LoadRules(filepath.Join("testdata", "rules.yaml"))
+	require.NoError(t, err)
+	require.Len(t, rules, 7)
+	assert.Equal(t, []string{"/order/customer"}, rules.Unmatched(schema))
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	for seed := range uint64(10) {
+		doc, err := New(schema, WithSeed(seed), WithRules(rules)).Generate(context.Background())
+		require.NoError(t, err)
+		order := doc.Root()
+		assert.Equal(t, "ABC123", order.SelectElement("id").Text())
+
+		placed, err := time.Parse("2006-01-02", order.SelectElement("placed").Text())
+		require.NoError(t, err)
+		assert.False(t, placed.Before(today) || placed.After(today.AddDate(0, 0, 30)), "placed %s", placed)
+
+		lines := order.SelectElements("line")
+		require.Len(t, lines, 2)
+		for _, line := range lines {
+			assert.Equal(t, "shipped", line.SelectElement("status").Text())
+			quantity, err := strconv.Atoi(line.SelectElement("quantity").Text())
+			require.NoError(t, err)
+			assert.True(t, quantity >= 4 && quantity <= 6, "quantity %d", quantity)
+			assert.Regexp(t, regexp.MustCompile(`^SKU-\d{4}$`), line.SelectAttrValue("sku", ""))
+		}
+	}
+}
+
+func TestRulesRelativeToNow(t *testing.T) {
+	schema := testSchema(t, "order.xsd")
+	rules, err := LoadRules(filepath.Join("testdata", "rules.yaml"))
+	require.NoError(t, err)
+	now := time.Date(2020, 2, 20, 15, 4, 5, 0, time.UTC)
+	out := write(t, New(schema, WithSeed(3), WithRules(rules), WithNow(now)))
+	assert.Equal(t, out, write(t, New(schema, WithSeed(3), WithRules(rules), WithNow(now))),
+		"the reference time makes the documents reproducible")
+	assert.Regexp(t, `<placed>2020-0[23]-\d\d</placed>`, out)
+
+	g := New(schema, WithSeed(3), WithRules(rules))
+	assert.Equal(t, write(t, g), write(t, g), "a Generator keeps its reference time")
+}
+
+func TestRulesMatchPaths(t *testing.T) {
+	cases := []struct {
+		path  string
+		names []string
+		want  bool
+	}{
+		{"/order/line", []string{"order", "line"}, true},
+		{"/order/line", []string{"order", "line", "quantity"}, false},
+		{"/order/*/quantity", []string{"order", "line", "quantity"}, true},
+		{"//quantity", []string{"order", "line", "quantity"}, true},
+		{"/order/**", []string{"order", "line", "quantity"}, true},
+		{"/**/@sku", []string{"order", "line", "@sku"}, true},
+		{"/order/line/*", []string{"order", "line", "@sku"}, false},
+		{"/order/l?ne", []string{"order", "line"}, true},
+		{"/order//status", []string{"order", "line", "status"}, true},
+	}
+	for _, c := range cases {
+		r, err := compileRule(Rule{Path: c.path, Value: "x"}, time.Now())
+		require.NoError(t, err, c.path)
+		assert.Equal(t, c.want, r.steps.matches(c.names), "%s against %v", c.path, c.names)
+	}
+}
+
+func TestRulesRejectInvalidRules(t *testing.T) {
+	for _, r := range []Rule{
+		{Path: "order/id", Value: "x"},
+		{Path: "/order/id"},
+		{Path: "/order/id", Value: "x", Pattern: "y"},
+		{Path: "/order/@sku/id", Value: "x"},
+		{Path: "/order/@sku", Occurs: "2"},
+		{Path: "/order/line", Occurs: "3..1"},
+		{Path: "/order/placed", Min: "today+x"},
+		{Path: "/order/[", Value: "x"},
+	} {
+		_, err := compileRule(r, time.Now())
+		assert.Error(t, err, "%+v", r)
+	}
+}
+
+func TestRulesFailOnMismatchedTypes(t *testing.T) {
+	schema := testSchema(t, "order.xsd")
+	_, err := New(schema, WithSeed(1), WithRules(Rules{{Path: "/order/id", Min: "1"}})).Generate(context.Background())
+	assert.ErrorContains(t, err, "/order/id: rule /order/id: ranges are not supported for xs:string")
+}
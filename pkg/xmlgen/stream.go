@@ -0,0 +1,390 @@
+package xmlgen
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/beevik/etree"
+)
+
+// fillCount is the number of occurrences of the unbounded particle filling a document up to its target
+// size, which stops once the target is reached.
+const fillCount = math.MaxInt
+
+// ErrConstraintsNeedDocument is returned by Stream and Encode for generators with constraints, which
+// apply to whole documents: Generate builds them.
+var ErrConstraintsNeedDocument = errors.New("constraints apply to whole documents, which cannot be streamed")
+
+// output receives the document from the walker, node by node: the attributes of an element come after
+// its start, and before its content unless hold was called.
+type output interface {
+	start(tag string)
+	// hold tells that the attributes of the element being started come after its content.
+	hold()
+	attr(name, value string)
+	// namespace declares prefix for ns, which an attribute of the element being started is about to use,
+	// and reports whether it wrote a declaration.
+	namespace(prefix, ns string) bool
+	text(value string)
+	end()
+	// err returns the error that stopped the output, such as a failed write.
+	err() error
+}
+
+// tree builds the document as an etree element.
+type tree struct {
+	root *etree.Element
+	open []*etree.Element
+}
+
+func (t *tree) start(tag string) {
+	elem := etree.NewElement(tag)
+	if n := len(t.open); n > 0 {
+		t.open[n-1].AddChild(elem)
+	} else {
+		t.root = elem
+	}
+	t.open = append(t.open, elem)
+}
+
+func (t *tree) hold() {}
+
+func (t *tree) attr(name, value string) {
+	t.open[len(t.open)-1].CreateAttr(name, value)
+}
+
+// namespace does nothing: Generator declares the prefixes generated for a tree on its root.
+func (t *tree) namespace(string, string) bool {
+	return false
+}
+
+func (t *tree) text(value string) {
+	t.open[len(t.open)-1].SetText(value)
+}
+
+func (t *tree) end() {
+	t.open = t.open[:len(t.open)-1]
+}
+
+func (t *tree) err() error {
+	return nil
+}
+
+// tokenEncoder writes XML tokens, like *xml.Encoder.
+type tokenEncoder interface {
+	EncodeToken(t xml.Token) error
+	Flush() error
+}
+
+// stream writes the document to a tokenEncoder as it is generated, holding the start tag of the last
+// element started until its attributes are complete. The content of the elements whose attributes come
+// after it is held too, until their end. Otherwise its memory only grows with the depth of the document.
+type stream struct {
+	enc     tokenEncoder
+	root    []xml.Attr // declarations added to the root element
+	pending *xml.StartElement
+	open    []openElement
+	held    []xml.Token // tokens of the held elements, written once the outermost one ends
+	holding int         // number of held elements open
+	started bool        // whether the start tag of the root is written
+	failed  error
+}
+
+// openElement is an element whose end tag is not written yet.
+type openElement struct {
+	name     xml.Name
+	prefixes []string // prefixes declared on the element
+	held     bool     // whether its attributes come after its content
+	at       int      // index of the start tag of a held element in stream.held, once it is not pending
+}
+
+func (s *stream) start(tag string) {
+	s.flush()
+	s.pending = &xml.StartElement{Name: xml.Name{Local: tag}}
+	s.open = append(s.open, openElement{name: s.pending.Name})
+}
+
+func (s *stream) hold() {
+	s.open[len(s.open)-1].held = true
+	s.holding++
+}
+
+func (s *stream) attr(name, value string) {
+	a := xml.Attr{Name: xml.Name{Local: name}, Value: value}
+	if s.pending != nil {
+		s.pending.Attr = append(s.pending.Attr, a)
+		return
+	}
+	// the start tag of a held element, after its content
+	at := s.open[len(s.open)-1].at
+	start := s.held[at].(xml.StartElement)
+	start.Attr = append(start.Attr, a)
+	s.held[at] = start
+}
+
+// namespace declares prefix on the element being started unless an enclosing element declares it. The
+// declarations of the held content of the element, written before, are then dropped.
+func (s *stream) namespace(prefix, ns string) bool {
+	for _, e := range s.open {
+		if slices.Contains(e.prefixes, prefix) {
+			return false
+		}
+	}
+	top := &s.open[len(s.open)-1]
+	top.prefixes = append(top.prefixes, prefix)
+	s.attr("xmlns:"+prefix, ns)
+	for i := top.at + 1; s.pending == nil && i < len(s.held); i++ {
+		if start, ok := s.held[i].(xml.StartElement); ok {
+			start.Attr = slices.DeleteFunc(start.Attr, func(a xml.Attr) bool { return a.Name.Local == "xmlns:"+prefix })
+			s.held[i] = start
+		}
+	}
+	return true
+}
+
+func (s *stream) text(value string) {
+	s.flush()
+	if value != "" {
+		s.emit(xml.CharData(value))
+	}
+}
+
+func (s *stream) end() {
+	s.flush()
+	n := len(s.open)
+	s.emit(xml.EndElement{Name: s.open[n-1].name})
+	held := s.open[n-1].held
+	s.open = s.open[:n-1]
+	if held {
+		if s.holding--; s.holding == 0 {
+			for _, t := range s.held {
+				s.encode(t)
+			}
+			s.held = nil
+		}
+	}
+}
+
+func (s *stream) err() error {
+	return s.failed
+}
+
+// flush passes on the pending start tag.
+func (s *stream) flush() {
+	if s.pending == nil {
+		return
+	}
+	if top := &s.open[len(s.open)-1]; top.held {
+		top.at = len(s.held)
+	}
+	s.emit(*s.pending)
+	s.pending = nil
+}
+
+// emit writes t, or holds it while a held element is open.
+func (s *stream) emit(t xml.Token) {
+	if s.holding > 0 {
+		s.held = append(s.held, t)
+		return
+	}
+	s.encode(t)
+}
+
+// encode writes t, with the declarations of the root when t is its start tag.
+func (s *stream) encode(t xml.Token) {
+	if start, ok := t.(xml.StartElement); ok && !s.started {
+		start.Attr = append(start.Attr, s.root...)
+		t, s.started = start, true
+	}
+	if s.failed == nil {
+		s.failed = s.enc.EncodeToken(t)
+	}
+}
+
+// close flushes the encoder once the document is complete.
+func (s *stream) close() error {
+	if s.failed == nil {
+		s.failed = s.enc.Flush()
+	}
+	return s.failed
+}
+
+// xmlWriter writes tokens in the format of etree documents indented with Document.Indent, so that
+// streamed documents are the same as the ones Generate builds: elements without content are
+// self-closing, text stays on the line of its element, and the document ends with a line break.
+type xmlWriter struct {
+	w       *bufio.Writer
+	written int64
+	indent  int
+	depth   int  // number of open elements
+	open    bool // whether the last start tag is not closed by '>' yet
+	ended   bool // whether the last token is an end tag, so that the next end tag goes on its own line
+	err     error
+}
+
+func newXMLWriter(w io.Writer, indent int) *xmlWriter {
+	return &xmlWriter{w: bufio.NewWriter(w), indent: indent}
+}
+
+// EncodeToken implements tokenEncoder for the tokens streams write.
+func (x *xmlWriter) EncodeToken(t xml.Token) error {
+	switch t := t.(type) {
+	case xml.StartElement:
+		x.close()
+		x.newLine(x.depth)
+		x.write("<" + t.Name.Local)
+		for _, a := range t.Attr {
+			x.write(" " + a.Name.Local + `="`)
+			x.escape(a.Value)
+			x.write(`"`)
+		}
+		x.open, x.ended = true, false
+		x.depth++
+	case xml.CharData:
+		x.close()
+		x.escape(string(t))
+	case xml.EndElement:
+		x.depth--
+		switch {
+		case x.open:
+			x.write("/>")
+			x.open = false
+		case x.ended:
+			x.newLine(x.depth)
+			fallthrough
+		default:
+			x.write("</" + t.Name.Local + ">")
+		}
+		x.ended = true
+		if x.depth == 0 && x.indent > 0 {
+			x.write("\n")
+		}
+	}
+	return x.err
+}
+
+// Flush implements tokenEncoder.
+func (x *xmlWriter) Flush() error {
+	return x.w.Flush()
+}
+
+// close closes the pending start tag.
+func (x *xmlWriter) close() {
+	if x.open {
+		x.write(">")
+		x.open = false
+	}
+}
+
+// newLine starts the line of a tag at depth when the document is indented, except the first one.
+func (x *xmlWriter) newLine(depth int) {
+	if x.indent > 0 && x.written > 0 {
+		x.write("\n" + strings.Repeat(" ", depth*x.indent))
+	}
+}
+
+func (x *xmlWriter) write(s string) {
+	if x.err != nil {
+		return
+	}
+	n, err := x.w.WriteString(s)
+	x.written += int64(n)
+	x.err = err
+}
+
+// escape writes s escaped as etree escapes text and attribute values, replacing the characters XML
+// does not allow.
+func (x *xmlWriter) escape(s string) {
+	last := 0
+	for i := 0; i < len(s); {
+		r, width := utf8.DecodeRuneInString(s[i:])
+		i += width
+		var esc string
+		switch r {
+		case '&':
+			esc = "&amp;"
+		case '<':
+			esc = "&lt;"
+		case '>':
+			esc = "&gt;"
+		case '\'':
+			esc = "&apos;"
+		case '"':
+			esc = "&quot;"
+		case '\t', '\n', '\r':
+			continue
+		default:
+			if isXMLChar(r) && (r != utf8.RuneError || width > 1) {
+				continue
+			}
+			esc = "\uFFFD"
+		}
+		x.write(s[last : i-width])
+		x.write(esc)
+		last = i
+	}
+	x.write(s[last:])
+}
+
+// isXMLChar reports whether XML documents may contain r.
+func isXMLChar(r rune) bool {
+	return r == '\t' || r == '\n' || r == '\r' || r >= 0x20 && r <= 0xD7FF || r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= 0x10FFFF
+}
+
+// Stream generates a document and writes it to w as it goes, indented as set with WithIndent, so that
+// memory does not grow with the size of the document: use Stream, or WriteTo, rather than Generate for
+// large documents. Only the elements with an attribute generated by a helpers.ParentReader are held until
+// their end, since their attributes come after their content. The document is the one Generate builds, written
+// like WriteTo writes it, except that the prefixes generated for attribute namespaces without one are
+// declared on the elements using them rather than on the root. Stream fails with
+// ErrConstraintsNeedDocument when the generator has constraints; on other errors w may hold a partial
+// document.
+func (g *Generator) Stream(ctx context.Context, w io.Writer) error {
+	_, err := g.stream(ctx, w)
+	return err
+}
+
+// stream implements Stream and returns the number of bytes written.
+func (g *Generator) stream(ctx context.Context, w io.Writer) (int64, error) {
+	x := newXMLWriter(w, g.opts.indent)
+	err := g.encode(ctx, x)
+	return x.written, err
+}
+
+// Encode generates a document and writes it to enc as it goes, like Stream. The tokens carry prefixed
+// names in their local names and namespace declarations as attributes, which enc writes as they are.
+// enc formats the document as it is configured, with its Indent method for instance, and is flushed once
+// the document is complete.
+func (g *Generator) Encode(ctx context.Context, enc *xml.Encoder) error {
+	return g.encode(ctx, enc)
+}
+
+// encode generates a document into enc.
+func (g *Generator) encode(ctx context.Context, enc tokenEncoder) error {
+	if len(g.opts.constraints) > 0 {
+		return ErrConstraintsNeedDocument
+	}
+	root, err := FindRoot(g.schema, g.opts.root)
+	if err != nil {
+		return err
+	}
+	out := &stream{enc: enc, root: g.rootAttrs(root, nil)}
+	w := g.newWalker(ctx)
+	w.out = out
+	w.element(root.Element, w.originOf(root.Element), "", 1, false)
+	if w.err == nil {
+		w.err = w.generators.Err()
+	}
+	if w.err == nil {
+		w.err = out.close()
+	}
+	return w.err
+}
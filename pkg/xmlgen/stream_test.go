@@ -0,0 +1,200 @@
+package xmlgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/beevik/etree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generated returns the document Generate builds, written as WriteTo wrote it before streaming.
+func generated(t *testing.T, g *Generator, indent int) string {
+	t.Helper()
+	doc, err := g.Generate(context.Background())
+	require.NoError(t, err)
+	if indent > 0 {
+		doc.Indent(indent)
+	}
+	out, err := doc.WriteToString()
+	require.NoError(t, err)
+	return out
+}
+
+func TestStreamWritesTheGeneratedDocument(t *testing.T) {
+	escaped := WithRules(Rules{{Path: "/order/line/@sku", Value: `<a & 'b' "c">` + "\x01"}})
+	for _, name := range []string{"order.xsd", "invoice.xsd", "shapes.xsd", "manifest.xsd", "recursive.xsd"} {
+		for _, indent := range []int{0, 2, 4} {
+			for seed := range uint64(5) {
+				opts := []Option{WithSeed(seed), WithIndent(indent), WithSchemaLocation("schema.xsd"), escaped}
+				var buf bytes.Buffer
+				require.NoError(t, New(testSchema(t, name), opts...).Stream(context.Background(), &buf))
+				assert.Equal(t, generated(t, New(testSchema(t, name), opts...), indent), buf.String(),
+					"%s indented by %d with seed %d", name, indent, seed)
+			}
+		}
+	}
+	out := write(t, New(testSchema(t, "order.xsd"), WithSeed(1), escaped))
+	assert.Contains(t, out, `sku="&lt;a &amp; &apos;b&apos; &quot;c&quot;&gt;`+"�\"")
+}
+
+func TestStreamDeclaresGeneratedPrefixesWhereUsed(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, New(testSchema(t, "catalog.xsd"), shallow, WithSeed(2)).Stream(context.Background(), &buf))
+	doc := etree.NewDocument()
+	require.NoError(t, doc.ReadFromBytes(buf.Bytes()))
+
+	root := doc.Root()
+	assert.Empty(t, root.SelectAttrValue("xmlns:ns1", ""))
+	for _, category := range root.SelectElements("category") {
+		assert.Equal(t, catalogNS, category.SelectAttrValue("xmlns:ns1", ""))
+		assert.Regexp(t, `^[A-Z]{2}\d{4}$`, category.SelectAttrValue("ns1:code", ""))
+		for _, nested := range category.FindElements(".//category") {
+			assert.Empty(t, nested.SelectAttrValue("xmlns:ns1", ""), "declarations in scope are not repeated")
+		}
+	}
+}
+
+func TestStreamHoldsElementsUntilTheirAttributes(t *testing.T) {
+	sku := helpers.ValueGeneratorFunc(func(vc *helpers.ValueContext) (string, bool) {
+		return vc.Parent["status"] + "-" + vc.Parent["quantity"], vc.Name.Local == "sku"
+	})
+	g := New(testSchema(t, "order.xsd"), WithSeed(4), WithValueGenerator(helpers.ReadingParent(sku)))
+	var buf bytes.Buffer
+	require.NoError(t, g.Stream(context.Background(), &buf))
+	doc := etree.NewDocument()
+	require.NoError(t, doc.ReadFromBytes(buf.Bytes()))
+	lines := doc.Root().SelectElements("line")
+	require.NotEmpty(t, lines)
+	for _, line := range lines {
+		want := line.SelectElement("status").Text() + "-" + line.SelectElement("quantity").Text()
+		assert.Equal(t, want, line.SelectAttrValue("sku", ""), "attributes see the content of their element")
+	}
+
+	out := write(t, New(testSchema(t, "order.xsd"), WithSeed(4), WithValueGenerator(sku)))
+	assert.Contains(t, out, `sku="-"`, "attributes come first for generators that do not read Parent")
+}
+
+// writeCounter counts the bytes written to it.
+type writeCounter struct{ n int }
+
+func (w *writeCounter) Write(p []byte) (int, error) {
+	w.n += len(p)
+	return len(p), nil
+}
+
+func TestStreamKeepsMemoryBounded(t *testing.T) {
+	// a root with attributes and many children with attributes, like the large documents streamed
+	schema := &model.XSDSchema{Elements: []model.XSDElement{{
+		Name: "log",
+		ComplexType: &model.XSDComplexType{
+			Sequence: &model.XSDSequence{Elements: []model.XSDElement{{
+				Name: "entry", MaxOccurs: "unbounded",
+				ComplexType: &model.XSDComplexType{
+					Sequence: &model.XSDSequence{Elements: []model.XSDElement{{Name: "message", Type: "xs:string"}}},
+					Attrs:    []model.XSDAttribute{{Name: "id", Type: "xs:int", Use: "required"}},
+				},
+			}}},
+			Attrs: []model.XSDAttribute{{Name: "source", Type: "xs:string", Use: "required"}},
+		},
+	}}}
+	var out writeCounter
+	last := 0 // bytes written when the last value is generated
+	values := helpers.ValueGeneratorFunc(func(*helpers.ValueContext) (string, bool) {
+		last = out.n
+		return "", false
+	})
+	g := New(schema, WithSeed(1), WithOccurrences(MaxOccurrences(20_000)), WithMaxElements(0),
+		WithValueGenerator(values))
+	require.NoError(t, g.Stream(context.Background(), &out))
+	assert.Greater(t, out.n, 1<<20)
+	assert.Less(t, out.n-last, 16<<10, "the document is written as it is generated, not held until the root ends")
+}
+
+func TestEncodeWritesTokens(t *testing.T) {
+	g := New(testSchema(t, "order.xsd"), WithSeed(3))
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "\t")
+	require.NoError(t, g.Encode(context.Background(), enc))
+
+	doc := etree.NewDocument()
+	require.NoError(t, doc.ReadFromBytes(buf.Bytes()))
+	want, err := New(testSchema(t, "order.xsd"), WithSeed(3)).Generate(context.Background())
+	require.NoError(t, err)
+	doc.Unindent()
+	first, _ := doc.WriteToString()
+	second, _ := want.WriteToString()
+	assert.Equal(t, second, first)
+	assert.Contains(t, buf.String(), "\n\t<line ")
+}
+
+func TestStreamFailures(t *testing.T) {
+	constraints, err := LoadConstraints(filepath.Join("testdata", "constraints.yaml"))
+	require.NoError(t, err)
+	g := New(testSchema(t, "order.xsd"), WithConstraints(constraints))
+	assert.ErrorIs(t, g.Stream(context.Background(), &bytes.Buffer{}), ErrConstraintsNeedDocument)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, New(testSchema(t, "order.xsd")).Stream(ctx, &bytes.Buffer{}), context.Canceled)
+
+	broken := errors.New("broken pipe")
+	g = New(testSchema(t, "manifest.xsd"), WithTargetSize(1<<20))
+	assert.ErrorIs(t, g.Stream(context.Background(), failingWriter{broken}), broken)
+}
+
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+func TestWithTargetSize(t *testing.T) {
+	for _, tc := range []struct {
+		schema string
+		target int
+		indent int
+	}{
+		{"manifest.xsd", 200_000, 2},
+		{"manifest.xsd", 50_000, 0},
+		{"catalog.xsd", 100_000, 2},
+	} {
+		// shallow keeps the recursive categories, by which the catalog grows, small against the target
+		g := New(testSchema(t, tc.schema), shallow, WithSeed(7), WithTargetSize(tc.target), WithIndent(tc.indent))
+		out := write(t, g)
+		assert.InEpsilon(t, tc.target, len(out), 0.05, "%s of about %d bytes", tc.schema, tc.target)
+
+		doc := etree.NewDocument()
+		require.NoError(t, doc.ReadFromString(out))
+		if tc.schema == "manifest.xsd" {
+			assert.Greater(t, len(doc.Root().SelectElements("container")), 500)
+		}
+	}
+
+	small := write(t, New(testSchema(t, "order.xsd"), WithSeed(7), WithTargetSize(100_000)))
+	assert.Less(t, len(small), 1000, "documents without unbounded particles stay small")
+}
+
+func TestParseSize(t *testing.T) {
+	for in, want := range map[string]int{
+		"20000": 20000, "500MB": 500_000_000, "1.5 GiB": 3 << 29, "64kib": 64 << 10, "2g": 2_000_000_000,
+	} {
+		got, err := ParseSize(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	for _, in := range []string{"", "MB", "12 parsecs", "-5MB"} {
+		_, err := ParseSize(in)
+		assert.Error(t, err, in)
+	}
+	_, err := ParseSize("5XB")
+	assert.ErrorContains(t, err, `invalid size "5XB"`)
+}
@@ -0,0 +1,418 @@
+package xmlgen
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/beevik/etree"
+)
+
+// Kinds of the constructs covered by a Suite.
+const (
+	CoverChoice       = "choice"       // a branch of a choice
+	CoverEnumeration  = "enumeration"  // a value of an enumeration
+	CoverBoundary     = "boundary"     // a boundary value of a restricted type, see WithBoundaryValues
+	CoverOptional     = "optional"     // the presence or absence of an optional particle
+	CoverSubstitution = "substitution" // a member of a substitution group
+)
+
+// Coverage lists the documents of a Suite exercising one case of a schema construct.
+type Coverage struct {
+	Kind string `json:"kind"`
+	// Construct locates the construct in the schema, such as "type(shape)/choice" or
+	// "element(order)/note", from the named type, simple type or global element declaring it.
+	Construct string `json:"construct"`
+	// Case is the branch, value, "present", "absent" or member covered.
+	Case      string   `json:"case"`
+	Documents []string `json:"documents"`
+}
+
+// SuiteDocument is a document of a Suite.
+type SuiteDocument struct {
+	Name     string // file name, <root>-<n>.xml
+	Root     Root
+	Document *etree.Document
+}
+
+// Suite is a set of documents that together cover the choice branches, enumeration values, optional
+// particles and substitution group members of a schema, and the boundary values of its restricted types
+// when generated WithBoundaryValues.
+type Suite struct {
+	Documents []SuiteDocument
+	Coverage  []Coverage // every case of every construct, in schema order
+}
+
+// Uncovered returns the cases no document exercises, because they are unreachable from the roots or
+// lie beyond the limits.
+func (s *Suite) Uncovered() []Coverage {
+	var uncovered []Coverage
+	for _, c := range s.Coverage {
+		if len(c.Documents) == 0 {
+			uncovered = append(uncovered, c)
+		}
+	}
+	return uncovered
+}
+
+// WriteReport writes the coverage of the suite as indented JSON.
+func (s *Suite) WriteReport(w io.Writer) error {
+	names := make([]string, 0, len(s.Documents))
+	for _, d := range s.Documents {
+		names = append(names, d.Name)
+	}
+	report := struct {
+		Documents  []string   `json:"documents"`
+		Covered    int        `json:"covered"`
+		Total      int        `json:"total"`
+		Constructs []Coverage `json:"constructs"`
+	}{names, len(s.Coverage) - len(s.Uncovered()), len(s.Coverage), s.Coverage}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// Suite generates documents until every case of every construct reachable from the roots is covered.
+// The roots are the one set with WithRoot, or else every non-abstract global element; roots whose
+// content cannot be completed within the limits are skipped then.
+//
+// Each document steers its choices towards cases not covered yet, and is kept only when it covers at
+// least one of them, so that the suite stays small. The suite is not guaranteed to be the smallest one,
+// which is a hard problem, but the same seed always gives the same suite.
+func (g *Generator) Suite(ctx context.Context) (*Suite, error) {
+	roots := Roots(g.schema)
+	if g.opts.root != "" {
+		root, err := FindRoot(g.schema, g.opts.root)
+		if err != nil {
+			return nil, err
+		}
+		roots = []Root{root}
+	}
+	base := g.newWalker(ctx)
+	boundaries, _ := base.values.(helpers.BoundaryProvider)
+	base.plan = newPlan(g.schema, boundaries)
+	suite := &Suite{}
+	names := map[string]int{}
+	for _, root := range roots {
+		if root.Element.Abstract && g.opts.root == "" {
+			continue
+		}
+		for {
+			w := *base
+			base.plan.begin()
+			doc, err := g.document(&w, root)
+			var limit *LimitError
+			if errors.As(err, &limit) && g.opts.root == "" {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if !base.plan.fresh {
+				break
+			}
+			names[root.Element.Name]++
+			name := root.Element.Name + "-" + strconv.Itoa(names[root.Element.Name]) + ".xml"
+			base.plan.commit(name)
+			suite.Documents = append(suite.Documents, SuiteDocument{Name: name, Root: root, Document: doc})
+		}
+	}
+	for _, t := range base.plan.targets {
+		suite.Coverage = append(suite.Coverage, Coverage{
+			Kind: t.kind, Construct: t.construct, Case: t.value, Documents: base.plan.documents[t],
+		})
+	}
+	return suite, nil
+}
+
+// target is a case of a construct to cover.
+type target struct {
+	kind, construct, value string
+}
+
+// plan steers the choices of a walker towards targets not covered yet and records the targets each
+// document covers. Its methods do nothing on a nil plan, so that random generation ignores it.
+type plan struct {
+	schema     *model.XSDSchema
+	boundaries helpers.BoundaryProvider           // nil when boundary values are not covered
+	names      map[any]string                     // construct of each choice, optional particle, restriction and substitution head
+	values     map[*model.XSDRestriction][]target // enumeration and boundary values of each restriction
+	targets    []target                           // every target, in schema order
+	known      map[target]bool
+	covered    map[target]bool
+	documents  map[target][]string
+	current    map[target]bool // targets hit by the document being generated
+	fresh      bool            // the document being generated covers a new target
+}
+
+// newPlan lists the targets of every named type, global element and named simple type of schema. The
+// boundary values of restricted types are targets too when boundaries is not nil.
+func newPlan(schema *model.XSDSchema, boundaries helpers.BoundaryProvider) *plan {
+	p := &plan{
+		schema:     schema,
+		boundaries: boundaries,
+		names:      map[any]string{},
+		values:     map[*model.XSDRestriction][]target{},
+		known:      map[target]bool{},
+		covered:    map[target]bool{},
+		documents:  map[target][]string{},
+	}
+	for i := range schema.Elements {
+		el := &schema.Elements[i]
+		p.element("element("+el.Name+")", el)
+		if el.SubstitutionGroup == "" {
+			continue
+		}
+		if head := globalElement(schema, el.SubstitutionGroup); head != nil && p.names[head] == "" {
+			p.names[head] = "element(" + head.Name + ")"
+			for _, c := range substitutes(schema, head) {
+				p.add(target{CoverSubstitution, p.names[head], c.Name})
+			}
+		}
+	}
+	for i := range schema.ComplexTypes {
+		ct := &schema.ComplexTypes[i]
+		p.complexType("type("+ct.Name+")", ct)
+	}
+	for i := range schema.SimpleTypes {
+		st := &schema.SimpleTypes[i]
+		p.simpleType("simpleType("+st.Name+")", st)
+	}
+	return p
+}
+
+func (p *plan) add(t target) {
+	if !p.known[t] {
+		p.known[t] = true
+		p.targets = append(p.targets, t)
+	}
+}
+
+// element lists the targets of the inline types of el, declared at path.
+func (p *plan) element(path string, el *model.XSDElement) {
+	switch {
+	case el.ComplexType != nil:
+		p.complexType(path, el.ComplexType)
+	case el.SimpleType != nil:
+		p.simpleType(path, el.SimpleType)
+	}
+}
+
+func (p *plan) complexType(path string, ct *model.XSDComplexType) {
+	if ct.Sequence != nil {
+		p.optional(path+"/sequence", ct.Sequence, ct.Sequence.MinOccurs)
+		p.particles(path, ct.Sequence.Particles())
+	}
+	if ct.Choice != nil {
+		p.choice(path+"/choice", ct.Choice)
+	}
+}
+
+// particles lists the targets of the particles of a model group located at path.
+func (p *plan) particles(path string, particles []model.XSDParticle) {
+	for i, label := range labels(particles) {
+		particle, at := particles[i], path+"/"+label
+		switch {
+		case particle.Element != nil:
+			p.optional(at, particle.Element, particle.Element.MinOccurs)
+			if particle.Element.Ref == "" {
+				p.element(at, particle.Element)
+			}
+		case particle.Sequence != nil:
+			p.optional(at, particle.Sequence, particle.Sequence.MinOccurs)
+			p.particles(at, particle.Sequence.Particles())
+		case particle.Choice != nil:
+			p.choice(at, particle.Choice)
+		}
+	}
+}
+
+func (p *plan) choice(path string, choice *model.XSDChoice) {
+	p.optional(path, choice, choice.MinOccurs)
+	p.names[choice] = path
+	particles := choice.Particles()
+	for _, label := range labels(particles) {
+		p.add(target{CoverChoice, path, label})
+	}
+	p.particles(path, particles)
+}
+
+// optional lists the presence and absence of a particle when it is optional.
+func (p *plan) optional(path string, particle any, minOccurs string) {
+	if parseOccurs(minOccurs) != 0 {
+		return
+	}
+	p.names[particle] = path
+	p.add(target{CoverOptional, path, "present"})
+	p.add(target{CoverOptional, path, "absent"})
+}
+
+// simpleType lists the enumeration values of st, or else its boundary values.
+func (p *plan) simpleType(path string, st *model.XSDSimpleType) {
+	base, r := simpleBase(p.schema, st)
+	if r == nil {
+		return
+	}
+	var targets []target
+	for _, e := range r.Enumerations {
+		targets = append(targets, target{CoverEnumeration, path, e.Value})
+	}
+	if p.boundaries != nil {
+		for _, v := range p.boundaries.BoundaryValues(base, r) {
+			targets = append(targets, target{CoverBoundary, path, v})
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+	p.names[r] = path
+	p.values[r] = targets
+	for _, t := range targets {
+		p.add(t)
+	}
+}
+
+// labels names the particles of a model group: elements by name, nested groups by kind and position.
+func labels(particles []model.XSDParticle) []string {
+	labels := make([]string, len(particles))
+	groups := map[string]int{}
+	for i, particle := range particles {
+		switch {
+		case particle.Element != nil && particle.Element.Ref != "":
+			labels[i] = localName(particle.Element.Ref)
+		case particle.Element != nil:
+			labels[i] = particle.Element.Name
+		case particle.Sequence != nil:
+			groups["sequence"]++
+			labels[i] = fmt.Sprintf("sequence[%d]", groups["sequence"])
+		case particle.Choice != nil:
+			groups["choice"]++
+			labels[i] = fmt.Sprintf("choice[%d]", groups["choice"])
+		}
+	}
+	return labels
+}
+
+// begin starts a document.
+func (p *plan) begin() {
+	p.current, p.fresh = map[target]bool{}, false
+}
+
+// commit keeps the document being generated under name.
+func (p *plan) commit(name string) {
+	for _, t := range p.targets {
+		if p.current[t] {
+			p.documents[t] = append(p.documents[t], name)
+		}
+	}
+}
+
+// hit records that the document being generated covers t.
+func (p *plan) hit(t target) {
+	if !p.known[t] {
+		return
+	}
+	p.current[t] = true
+	if !p.covered[t] {
+		p.covered[t], p.fresh = true, true
+	}
+}
+
+// open reports whether the case value of the construct of component is still to cover.
+func (p *plan) open(kind string, component any, value string) bool {
+	t := target{kind, p.names[component], value}
+	return p.known[t] && !p.covered[t]
+}
+
+// branch returns the first branch of choice not covered yet, or drawn.
+func (p *plan) branch(choice *model.XSDChoice, branches, drawn int) int {
+	if p == nil {
+		return drawn
+	}
+	for i, label := range labels(choice.Particles())[:branches] {
+		if p.open(CoverChoice, choice, label) {
+			return i
+		}
+	}
+	return drawn
+}
+
+func (p *plan) chose(choice *model.XSDChoice, branch int) {
+	if p != nil {
+		p.hit(target{CoverChoice, p.names[choice], labels(choice.Particles())[branch]})
+	}
+}
+
+// occurs returns the number of occurrences of an optional particle: at least one while its presence is
+// not covered, none while its absence is not, and drawn otherwise.
+func (p *plan) occurs(particle any, maxOccurs, drawn int) int {
+	switch {
+	case p == nil:
+		return drawn
+	case p.open(CoverOptional, particle, "present") && maxOccurs != 0:
+		return max(drawn, 1)
+	case p.open(CoverOptional, particle, "absent"):
+		return 0
+	}
+	return drawn
+}
+
+func (p *plan) occurred(particle any, count int) {
+	if p == nil || p.names[particle] == "" {
+		return
+	}
+	if count > 0 {
+		p.hit(target{CoverOptional, p.names[particle], "present"})
+	} else {
+		p.hit(target{CoverOptional, p.names[particle], "absent"})
+	}
+}
+
+// enumeration returns the first enumeration or boundary value of r not covered yet.
+func (p *plan) enumeration(r *model.XSDRestriction) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	for _, t := range p.values[r] {
+		if !p.covered[t] {
+			p.hit(t)
+			return t.value, true
+		}
+	}
+	return "", false
+}
+
+func (p *plan) value(r *model.XSDRestriction, value string) {
+	if p == nil {
+		return
+	}
+	for _, t := range p.values[r] {
+		if t.value == value {
+			p.hit(t)
+		}
+	}
+}
+
+// substitution returns the first candidate for head not covered yet, or drawn.
+func (p *plan) substitution(head *model.XSDElement, candidates []*model.XSDElement, drawn int) int {
+	if p == nil {
+		return drawn
+	}
+	for i, c := range candidates {
+		if p.open(CoverSubstitution, head, c.Name) {
+			return i
+		}
+	}
+	return drawn
+}
+
+func (p *plan) substituted(head, member *model.XSDElement) {
+	if p != nil {
+		p.hit(target{CoverSubstitution, p.names[head], member.Name})
+	}
+}
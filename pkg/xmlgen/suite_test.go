@@ -0,0 +1,100 @@
+package xmlgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuiteCoversEveryConstruct(t *testing.T) {
+	suite, err := New(testSchema(t, "shapes.xsd"), WithSeed(3)).Suite(context.Background())
+	require.NoError(t, err)
+
+	assert.Empty(t, suite.Uncovered())
+	cases := map[string][]string{}
+	for _, c := range suite.Coverage {
+		key := c.Kind + " " + c.Construct
+		cases[key] = append(cases[key], c.Case)
+	}
+	assert.Equal(t, map[string][]string{
+		"choice element(drawing)/choice[1]": {"background", "pattern", "sequence[1]"},
+		"enumeration simpleType(color)":     {"red", "green", "blue"},
+		"optional element(drawing)/title":   {"present", "absent"},
+		"substitution element(shape)":       {"circle", "square"},
+	}, cases)
+
+	require.Len(t, suite.Documents, 3, "one document per branch of the choice")
+	for _, d := range suite.Documents {
+		assert.Equal(t, "drawing", d.Root.Element.Name)
+		assert.Nil(t, d.Document.FindElement("//shape"), "abstract elements are substituted")
+	}
+	assert.Equal(t, "drawing-1.xml", suite.Documents[0].Name)
+}
+
+func TestSuiteReport(t *testing.T) {
+	suite, err := New(testSchema(t, "shapes.xsd"), WithSeed(3)).Suite(context.Background())
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, suite.WriteReport(&buf))
+
+	var report struct {
+		Documents  []string
+		Covered    int
+		Total      int
+		Constructs []Coverage
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+	assert.Equal(t, []string{"drawing-1.xml", "drawing-2.xml", "drawing-3.xml"}, report.Documents)
+	assert.Equal(t, 10, report.Total)
+	assert.Equal(t, report.Total, report.Covered)
+	assert.Equal(t, suite.Coverage, report.Constructs)
+}
+
+func TestSuiteRootsAndLimits(t *testing.T) {
+	suite, err := New(testSchema(t, "recursive.xsd"), WithMaxDepth(4)).Suite(context.Background())
+	require.NoError(t, err, "roots that cannot be completed are skipped")
+	for _, d := range suite.Documents {
+		assert.NotEqual(t, "loop", d.Root.Element.Name)
+	}
+	assert.Empty(t, suite.Uncovered())
+
+	_, err = New(testSchema(t, "recursive.xsd"), WithRoot("loop"), WithMaxDepth(4)).Suite(context.Background())
+	var limit *LimitError
+	assert.ErrorAs(t, err, &limit)
+}
+
+func TestSubstitutionGroups(t *testing.T) {
+	seen := map[string]bool{}
+	for seed := uint64(0); seed < 20; seed++ {
+		doc, err := New(testSchema(t, "shapes.xsd"), WithSeed(seed)).Generate(context.Background())
+		require.NoError(t, err)
+		for _, e := range doc.Root().ChildElements() {
+			seen[e.Tag] = true
+		}
+	}
+	assert.True(t, seen["circle"])
+	assert.True(t, seen["square"])
+	assert.False(t, seen["shape"])
+}
+
+func TestSuiteCoversBoundaryValues(t *testing.T) {
+	schema := testSchema(t, "order.xsd")
+	suite, err := New(schema, WithSeed(1), WithMode(ModeMax), WithBoundaryValues()).Suite(context.Background())
+	require.NoError(t, err)
+
+	assert.Empty(t, suite.Uncovered())
+	var boundaries []string
+	for _, c := range suite.Coverage {
+		if c.Kind == CoverBoundary {
+			boundaries = append(boundaries, c.Construct+"="+c.Case)
+		}
+	}
+	assert.Equal(t, []string{
+		"type(line)/quantity=1", "type(line)/quantity=2", "type(line)/quantity=9", "type(line)/quantity=10",
+	}, boundaries)
+	assert.Len(t, suite.Documents, 2, "two lines per document")
+}
@@ -1,112 +1,656 @@
 package xmlgen
 
 import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
 	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/semantic"
 	"github.com/beevik/etree"
 )
 
 // GenerateElement creates an XML element (etree.Element) based on the provided XSD schema definition.
 // It evaluates whether the element is of a simple type, complex type, reference, or inline definition.
-// gen is used as a value generator for populating element content.
+// gen generates the text of the elements with simple content and the values of the attributes; the values
+// it declines, and all of them when gen is nil, are random. When gen implements helpers.Randomized,
+// occurrences and choice branches are drawn from its Rand too, so that a seeded gen reproduces the element.
+//
+// Content deeper than DefaultMaxDepth or beyond DefaultMaxElements is limited to its shortest valid
+// completion. GenerateElement returns nil when the element cannot be completed within these limits.
+//
+// The element carries no namespace declaration; Generator builds whole documents.
 func GenerateElement(schema *model.XSDSchema, element *model.XSDElement, gen helpers.ValueGenerator) *etree.Element {
-	elem := etree.NewElement(element.Name)
+	r := helpers.RandOf(gen)
+	if gen == nil {
+		gen = helpers.DefaultValueGenerator{}
+	}
+	w := &walker{
+		ctx:         context.Background(),
+		schema:      schema,
+		rand:        r,
+		values:      gen,
+		occurrences: RandomOccurrences(defaultUnboundedLimit),
+		limits:      limits{maxDepth: DefaultMaxDepth, maxElements: DefaultMaxElements},
+		sizes:       newSizes(schema),
+	}
+	tree := &tree{}
+	w.out = tree
+	origin := w.originOf(element)
+	w.element(element, origin, w.namespace(element, origin), 1, false)
+	if w.err != nil {
+		return nil
+	}
+	return tree.root
+}
+
+// walker generates the elements of one document.
+type walker struct {
+	ctx         context.Context
+	schema      *model.XSDSchema
+	rand        *helpers.Rand
+	values      helpers.ValueGenerator
+	rules       ruleSet            // pin or constrain the values and occurrences of given paths
+	generators  *Registry          // custom generators per path and type, nil for none
+	semantic    *semantic.Registry // realistic values picked by name, nil for random ones
+	occurrences OccurrencePolicy
+	out         output            // receives the document as it is generated
+	minimal     bool              // generate the smallest choice branches and only the required attributes
+	plan        *plan             // steers the choices towards constructs not covered yet, nil for random ones
+	limits      limits            // from which only the shortest valid completion is generated
+	sizes       *sizes            // smallest size of the content of each complex type
+	elements    int               // elements generated so far
+	bytes       int               // estimated size of the document so far, without indentation
+	indent      int               // spaces per indentation level of the written document
+	layout      int               // estimated size of the indentation of the document so far
+	target      int               // size the unbounded particles fill the document up to, 0 for none
+	filling     bool              // whether the occurrences of an unbounded particle are filling the document
+	path        []string          // names of the elements being generated, from the root, then @name of an attribute
+	scopes      []scope           // elements being generated, from the root, then the attribute being generated
+	prefixes    map[string]string // prefix of each namespace written with one
+	declared    map[string]string // prefixes generated for namespaces without one, declared on the root of trees
+	err         error             // first error, such as the cancellation of ctx
 
+	// effective restriction of each simple type, computed once
+	restrictions map[*model.XSDSimpleType]*model.XSDRestriction
+}
+
+// scope is an element or attribute being generated.
+type scope struct {
+	name     xml.Name
+	key      string            // name of the node in the values of its parent: its local name, prefixed with @ for attributes
+	step     string            // step of the node in the paths of values: key, with the position of elements that may repeat
+	position int               // position of an element among its siblings of the same name, from 0
+	values   map[string]string // values generated so far inside an element, by key
+	children map[string]int    // number of child elements generated so far inside an element, by local name
+}
+
+// element generates el, declared in the schema document origin. defaultNS is the default namespace in
+// scope, in which unprefixed child elements end up, depth the depth of the element, 1 for the root, and
+// repeated tells whether its particle allows several occurrences.
+func (w *walker) element(el *model.XSDElement, origin *model.XSDOrigin, defaultNS string, depth int, repeated bool) {
+	if el.Ref != "" {
+		ref := w.globalElement(el.Ref)
+		if ref == nil {
+			return
+		}
+		ref = w.substitute(ref, depth)
+		w.element(ref, w.originOf(ref), defaultNS, depth, repeated)
+		return
+	}
+	if w.err == nil {
+		w.err = w.ctx.Err()
+	}
+	if w.err == nil {
+		w.err = w.out.err()
+	}
+	if w.err != nil {
+		return
+	}
+
+	ns := w.namespace(el, origin)
+	w.enter(xml.Name{Space: ns, Local: el.Name}, false, repeated)
+	defer w.leave()
+	if limit := w.reached(depth); limit != "" && w.sizes.element(el) == infinite {
+		w.err = &LimitError{Path: w.location(), Limit: limit}
+		return
+	}
+	defaultNS = w.newElement(el.Name, ns, defaultNS)
+	w.elements++
 	switch {
-	case element.Type != "":
-		handleType(schema, elem, element, gen)
-	case element.ComplexType != nil:
-		appendComplexContent(schema, elem, *element.ComplexType, gen)
-	case element.SimpleType != nil:
-		elem.SetText(helpers.GenerateValue(element.SimpleType.Restriction.Base, element.SimpleType.Restriction))
-	case element.Ref != "":
-		elem = handleRef(schema, element.Ref, gen)
+	case el.Fixed != "":
+		w.setText(el.Fixed)
+	case el.ComplexType != nil:
+		w.complexContent(el.ComplexType, origin, defaultNS, depth)
+	case el.SimpleType != nil:
+		w.setText(w.simpleValue(el.SimpleType, ""))
+	case el.Type != "":
+		w.typedContent(el.Type, defaultNS, depth)
 	}
+	w.out.end()
+}
 
-	return elem
+// setText sets the text of the element being generated and accounts for its size.
+func (w *walker) setText(text string) {
+	w.out.text(text)
+	w.bytes += len(text)
+	w.record(text)
 }
 
-func handleType(schema *model.XSDSchema, elem *etree.Element, element *model.XSDElement, gen helpers.ValueGenerator) {
-	if strings.HasPrefix(element.Type, "tns:") {
-		typeName := strings.TrimPrefix(element.Type, "tns:")
-		if tryAppendComplexType(schema, elem, typeName, gen) {
-			return
+// enter starts generating the element or attribute name. repeated tells whether an element may have
+// several occurrences, which the paths of values then number.
+func (w *walker) enter(name xml.Name, attribute, repeated bool) {
+	s := scope{name: name, key: name.Local, step: name.Local}
+	if attribute {
+		s.key, s.step = "@"+name.Local, "@"+name.Local
+	} else if n := len(w.scopes); n > 0 {
+		parent := &w.scopes[n-1]
+		if parent.children == nil {
+			parent.children = map[string]int{}
+			w.layout += w.indentation(n - 1) // the end tag of the parent goes on its own line
+		}
+		w.layout += w.indentation(n)
+		s.position = parent.children[name.Local]
+		parent.children[name.Local]++
+	}
+	if repeated {
+		s.step += "[" + strconv.Itoa(s.position+1) + "]"
+	}
+	w.path = append(w.path, s.key)
+	w.scopes = append(w.scopes, s)
+}
+
+// leave ends the generation of the node entered last.
+func (w *walker) leave() {
+	w.path = w.path[:len(w.path)-1]
+	w.scopes = w.scopes[:len(w.scopes)-1]
+}
+
+// record keeps the value of the node being generated among the values of its parent element.
+func (w *walker) record(value string) {
+	n := len(w.scopes)
+	if n < 2 {
+		return
+	}
+	parent := &w.scopes[n-2]
+	if parent.values == nil {
+		parent.values = map[string]string{}
+	}
+	parent.values[w.scopes[n-1].key] = value
+}
+
+// setAttr adds an attribute to the element being generated and accounts for its size.
+func (w *walker) setAttr(name, value string) {
+	w.out.attr(name, value)
+	w.bytes += attributeBytes(name, value)
+}
+
+// newElement starts an element of namespace ns, prefixed when ns has a prefix and declaring ns as the
+// default namespace when it differs from the one in scope. It returns the default namespace of its children.
+//
+// Unqualified elements inside an element of the default namespace stay unprefixed and so inherit that
+// namespace, like the Go types generated by codegen marshal them.
+func (w *walker) newElement(local, ns, defaultNS string) string {
+	tag := local
+	if prefix, ok := w.prefixes[ns]; ok && ns != "" {
+		tag = prefix + ":" + local
+	}
+	w.out.start(tag)
+	w.bytes += elementBytes(tag)
+	if tag == local && ns != "" && ns != defaultNS {
+		w.setAttr("xmlns", ns)
+		defaultNS = ns
+	}
+	return defaultNS
+}
+
+// indentation returns the size of the line break and indentation before a tag at depth, 0 for the root.
+func (w *walker) indentation(depth int) int {
+	if w.indent <= 0 {
+		return 0
+	}
+	return 1 + depth*w.indent
+}
+
+// typedContent generates the content of the named type typeName.
+func (w *walker) typedContent(typeName, defaultNS string, depth int) {
+	if !isBuiltin(typeName) {
+		local := localName(typeName)
+		for i := range w.schema.ComplexTypes {
+			if ct := &w.schema.ComplexTypes[i]; ct.Name == local {
+				w.complexContent(ct, w.originOf(ct), defaultNS, depth)
+				return
+			}
 		}
-		if trySetSimpleType(schema, elem, typeName) {
+		if st := w.simpleType(local); st != nil {
+			w.setText(w.simpleValue(st, typeName))
 			return
 		}
+	}
+	w.setText(w.value(typeName, typeName, nil))
+}
+
+// simpleValue generates a value of a simple type, named typeName or "" for anonymous types, following
+// restrictions of other named simple types down to their built-in base.
+func (w *walker) simpleValue(st *model.XSDSimpleType, typeName string) string {
+	base, r := simpleBase(w.schema, st)
+	if r == nil || w.rules.value(w.path) != nil {
+		return w.value(typeName, base, w.restriction(st))
+	}
+	if value, ok := w.plan.enumeration(r); ok {
+		return value
+	}
+	value := w.value(typeName, base, w.restriction(st))
+	w.plan.value(r, value)
+	return value
+}
+
+// restriction returns the effective restriction of st, computed once per simple type.
+func (w *walker) restriction(st *model.XSDSimpleType) *model.XSDRestriction {
+	if r, ok := w.restrictions[st]; ok {
+		return r
+	}
+	if w.restrictions == nil {
+		w.restrictions = map[*model.XSDSimpleType]*model.XSDRestriction{}
+	}
+	r := effectiveRestriction(w.schema, st)
+	w.restrictions[st] = r
+	return r
+}
+
+// value generates the value of the element or attribute being generated, of the built-in type base
+// restricted by r and named typeName, "" for anonymous types. The first rule matching its path decides
+// it, or else the registered generators, the semantic provider of its name or of typeName and the value
+// generator in turn, and random values when they all decline.
+func (w *walker) value(typeName, base string, r *model.XSDRestriction) string {
+	rule := w.rules.value(w.path)
+	if rule == nil {
+		vc := w.valueContext(typeName, base, r)
+		for _, g := range []helpers.ValueGenerator{w.generators, w.semantic, w.values} {
+			if value, ok := g.Generate(vc); ok {
+				return value
+			}
+		}
+		return w.rand.GenerateValue(base, r)
+	}
+	value, err := rule.generate(w.rand, base)
+	if err != nil && w.err == nil {
+		w.err = fmt.Errorf("%s: rule %s: %w", w.location(), rule.Path, err)
+	}
+	return value
+}
+
+// valueContext describes the node being generated to the value generators.
+func (w *walker) valueContext(typeName, base string, r *model.XSDRestriction) *helpers.ValueContext {
+	steps := make([]string, len(w.scopes))
+	for i, s := range w.scopes {
+		steps[i] = s.step
+	}
+	n := len(w.scopes)
+	node := w.scopes[n-1]
+	vc := &helpers.ValueContext{
+		Path:        "/" + strings.Join(steps, "/"),
+		Name:        node.name,
+		Attribute:   strings.HasPrefix(node.key, "@"),
+		Type:        typeName,
+		Base:        base,
+		Restriction: r,
+		Occurrence:  node.position,
+		Rand:        w.rand,
+	}
+	if vc.Attribute {
+		vc.Occurrence = w.scopes[n-2].position
+	}
+	if n > 1 {
+		vc.Parent = w.scopes[n-2].values
+	}
+	return vc
+}
+
+// complexContent generates the content of a complexType into the element being generated.
+// It handles sequences, choices, and attributes as defined in the XSD. Attributes come first, so that
+// streamed elements are written as they are generated, except when the generator of one of them reads
+// the values of the children: the element is then held until its end and its attributes come last.
+func (w *walker) complexContent(ct *model.XSDComplexType, origin *model.XSDOrigin, defaultNS string, depth int) {
+	last := slices.ContainsFunc(ct.Attrs, w.readsParent)
+	if last {
+		w.out.hold()
 	} else {
-		elem.SetText(helpers.GenerateValue(element.Type, nil))
+		w.attributes(ct, origin)
+	}
+
+	// Handle <xs:sequence> — ordered elements and nested groups
+	if ct.Sequence != nil {
+		w.sequence(ct.Sequence, origin, defaultNS, depth)
+	}
+
+	// Handle <xs:choice> — only one of the listed particles should be chosen
+	if ct.Choice != nil {
+		w.choice(ct.Choice, origin, defaultNS, depth)
+	}
+
+	if last {
+		w.attributes(ct, origin)
+	}
+}
+
+// readsParent reports whether attr, an attribute of the element being generated, is generated by a
+// value generator reading Parent.
+func (w *walker) readsParent(attr model.XSDAttribute) bool {
+	if !w.generates(attr) || attr.Fixed != "" {
+		return false
+	}
+	names := slices.Concat(w.path, []string{"@" + attr.Name})
+	if w.rules.value(names) != nil {
+		return false
+	}
+	if helpers.ReadsParent(w.values) {
+		return true
+	}
+	base := attr.Type
+	if !isBuiltin(base) {
+		if st := w.simpleType(localName(base)); st != nil {
+			base, _ = simpleBase(w.schema, st)
+		}
 	}
+	return w.generators.readsParent(names, attr.Type, base)
+}
+
+// generates reports whether attr is generated.
+func (w *walker) generates(attr model.XSDAttribute) bool {
+	return attr.Use != "prohibited" && (!w.minimal || attr.Use == "required")
 }
 
-func tryAppendComplexType(schema *model.XSDSchema, elem *etree.Element, typeName string, gen helpers.ValueGenerator) bool {
-	for _, ct := range schema.ComplexTypes {
-		if ct.Name == typeName {
-			appendComplexContent(schema, elem, ct, gen)
-			return true
+// attributes generates the attributes of ct into the element being generated.
+func (w *walker) attributes(ct *model.XSDComplexType, origin *model.XSDOrigin) {
+	for _, attr := range ct.Attrs {
+		if !w.generates(attr) {
+			continue
+		}
+		// Generate a value according to type (unless a 'fixed' value is provided)
+		ns := origin.AttributeNamespace(attr.Form)
+		w.enter(xml.Name{Space: ns, Local: attr.Name}, true, false)
+		val := attr.Fixed
+		if val == "" {
+			val = w.attributeValue(attr.Type)
+		}
+		w.record(val)
+		w.leave()
+		name := attr.Name
+		if ns != "" {
+			prefix := w.prefix(ns)
+			if _, ok := w.prefixes[ns]; !ok && w.out.namespace(prefix, ns) {
+				w.bytes += attributeBytes("xmlns:"+prefix, ns)
+			}
+			name = prefix + ":" + name
 		}
+		w.setAttr(name, val)
 	}
-	return false
 }
 
-func trySetSimpleType(schema *model.XSDSchema, elem *etree.Element, typeName string) bool {
-	for _, st := range schema.SimpleTypes {
-		if st.Name == typeName {
-			elem.SetText(helpers.GenerateValue(st.Restriction.Base, st.Restriction))
-			return true
+// attributeValue generates a value of the built-in or named simple type typeName.
+func (w *walker) attributeValue(typeName string) string {
+	if !isBuiltin(typeName) {
+		if st := w.simpleType(localName(typeName)); st != nil {
+			return w.simpleValue(st, typeName)
 		}
 	}
-	return false
+	return w.value(typeName, typeName, nil)
 }
 
-func handleRef(schema *model.XSDSchema, ref string, gen helpers.ValueGenerator) *etree.Element {
-	refName := strings.Split(ref, ":")[1]
-	for _, el := range schema.Elements {
-		if el.Name == refName {
-			return GenerateElement(schema, &el, gen)
+// sequence appends the particles of a sequence in order, repeating the whole group
+// a number of times within its own minOccurs/maxOccurs.
+func (w *walker) sequence(seq *model.XSDSequence, origin *model.XSDOrigin, defaultNS string, depth int) {
+	minCount, count := w.count(seq, seq.MinOccurs, seq.MaxOccurs, depth)
+	i := 0
+	for ; w.more(i, minCount, count, depth); i++ {
+		for _, p := range seq.Particles() {
+			w.particle(p, origin, defaultNS, depth)
 		}
 	}
-	return nil
+	w.filled(count)
+	w.plan.occurred(seq, i)
 }
 
-// appendComplexContent populates a complexType into the target XML element.
-// It handles sequences, choices, and attributes as defined in the XSD.
-func appendComplexContent(schema *model.XSDSchema, elem *etree.Element, ct model.XSDComplexType, gen helpers.ValueGenerator) {
-	// Handle <xs:sequence> — ordered elements
-	if ct.Sequence != nil {
-		for _, child := range ct.Sequence.Elements {
-			// Parse occurrence constraints (default to 1 if not specified)
-			minOccurs := helpers.ParseOccurs(child.MinOccurs, 1)
-			maxOccurs := helpers.ParseOccurs(child.MaxOccurs, 1)
-
-			// Randomly pick how many times to repeat this element (within min/max)
-			count := helpers.RandomBetween(minOccurs, maxOccurs)
-			for i := 0; i < count; i++ {
-				// Recursively generate child elements
-				childXML := GenerateElement(schema, &child, gen)
-				elem.AddChild(childXML)
+// choice appends one randomly picked particle per occurrence of the choice. In minimal documents, and
+// once a limit is reached, the smallest alternative is picked instead, which avoids the recursive ones.
+func (w *walker) choice(choice *model.XSDChoice, origin *model.XSDOrigin, defaultNS string, depth int) {
+	particles := choice.Particles()
+	if len(particles) == 0 {
+		return
+	}
+	minCount, count := w.count(choice, choice.MinOccurs, choice.MaxOccurs, depth)
+	i := 0
+	for ; w.more(i, minCount, count, depth); i++ {
+		branch := w.rand.Intn(len(particles))
+		if w.minimal || w.reached(depth) != "" {
+			branch = w.smallest(particles)
+		} else {
+			branch = w.plan.branch(choice, len(particles), branch)
+		}
+		w.plan.chose(choice, branch)
+		w.particle(particles[branch], origin, defaultNS, depth)
+	}
+	w.filled(count)
+	w.plan.occurred(choice, i)
+}
+
+// smallest returns the index of the first of the alternatives of a choice with the smallest content.
+func (w *walker) smallest(particles []model.XSDParticle) int {
+	best, size := 0, w.sizes.group(particles[0])
+	for i, p := range particles[1:] {
+		if n := w.sizes.group(p); n < size {
+			best, size = i+1, n
+		}
+	}
+	return best
+}
+
+// substitute returns the element generated for a reference to the global element head: head itself or
+// one of the members of its substitution group, picked at random or, once a limit is reached, the
+// smallest one. Abstract heads are always substituted.
+func (w *walker) substitute(head *model.XSDElement, depth int) *model.XSDElement {
+	candidates := substitutes(w.schema, head)
+	pick := 0
+	switch {
+	case len(candidates) == 0:
+		return head
+	case len(candidates) == 1:
+	case w.minimal || w.reached(depth) != "":
+		for i, c := range candidates {
+			if w.sizes.element(c) < w.sizes.element(candidates[pick]) {
+				pick = i
 			}
 		}
+	default:
+		pick = w.plan.substitution(head, candidates, w.rand.Intn(len(candidates)))
 	}
+	w.plan.substituted(head, candidates[pick])
+	return candidates[pick]
+}
 
-	// Handle <xs:choice> — only one of the listed elements should be chosen
-	if ct.Choice != nil && len(ct.Choice.Elements) > 0 {
-		// Randomly pick one child element from the choice
-		choice := ct.Choice.Elements[helpers.RandomBetween(0, len(ct.Choice.Elements)-1)]
-		elem.AddChild(GenerateElement(schema, &choice, gen))
+// particle generates an element, honouring its occurrence constraints, or a nested group.
+func (w *walker) particle(p model.XSDParticle, origin *model.XSDOrigin, defaultNS string, depth int) {
+	switch {
+	case p.Element != nil:
+		maxCount := parseOccurs(p.Element.MaxOccurs)
+		minCount, count := w.count(p.Element, p.Element.MinOccurs, p.Element.MaxOccurs, depth)
+		i := 0
+		for ; w.more(i, minCount, count, depth); i++ {
+			// Recursively generate child elements
+			w.element(p.Element, origin, defaultNS, depth+1, maxCount != 1)
+		}
+		w.filled(count)
+		w.plan.occurred(p.Element, i)
+	case p.Choice != nil:
+		w.choice(p.Choice, origin, defaultNS, depth)
+	case p.Sequence != nil:
+		w.sequence(p.Sequence, origin, defaultNS, depth)
 	}
+}
 
-	// Handle attributes defined in the complex type
-	for _, attr := range ct.Attrs {
-		// Generate a value according to type (unless a 'fixed' value is provided)
-		val := helpers.GenerateValue(attr.Type, nil)
-		if attr.Fixed != "" {
-			val = attr.Fixed
+// count picks how many times the particle of an element at depth is repeated, and returns its minimum
+// number of occurrences too. Once a limit is reached only the required occurrences are generated. With
+// a target size, the first unbounded particle met while the document is smaller than the target, outside
+// of the ones filling it already, gets fillCount: it repeats until the document reaches the target.
+func (w *walker) count(particle any, minOccurs, maxOccurs string, depth int) (minCount, count int) {
+	minCount, maxCount := parseOccurs(minOccurs), parseOccurs(maxOccurs)
+	if w.reached(depth) != "" {
+		return minCount, minCount
+	}
+	if el, ok := particle.(*model.XSDElement); ok && len(w.rules) > 0 {
+		name := el.Name
+		if el.Ref != "" {
+			name = localName(el.Ref)
+		}
+		if n, ok := w.rules.occurs(slices.Concat(w.path, []string{name}), w.rand, minCount, maxCount); ok {
+			return minCount, n
+		}
+	}
+	if w.target > 0 && maxCount == Unbounded && !w.filling && w.size() < w.target {
+		w.filling = true
+		return minCount, fillCount
+	}
+	count = w.occurrences(w.rand, minCount, maxCount)
+	if minCount == 0 {
+		count = w.plan.occurs(particle, maxCount, count)
+	}
+	return minCount, count
+}
+
+// more reports whether occurrence i of a particle of an element at depth, repeated count times, is still
+// generated: required occurrences always are until an error stops the document, optional ones only
+// while no limit is reached, and the ones filling the document while it is smaller than its target size.
+func (w *walker) more(i, minCount, count, depth int) bool {
+	switch {
+	case i >= count || w.err != nil:
+		return false
+	case i < minCount:
+		return true
+	case w.reached(depth) != "":
+		return false
+	case count == fillCount:
+		return w.size() < w.target
+	}
+	return true
+}
+
+// filled ends the filling of the document by a particle repeated count times, when it was filling it.
+func (w *walker) filled(count int) {
+	if count == fillCount {
+		w.filling = false
+	}
+}
+
+// size estimates the size of the document generated so far, indentation included.
+func (w *walker) size() int {
+	return w.bytes + w.layout
+}
+
+// prefix returns the prefix of namespace ns, declaring a generated one on the root when ns has none.
+func (w *walker) prefix(ns string) string {
+	if prefix, ok := w.prefixes[ns]; ok {
+		return prefix
+	}
+	if w.declared == nil {
+		w.declared = map[string]string{}
+	}
+	if prefix, ok := w.declared[ns]; ok {
+		return prefix
+	}
+	taken := map[string]bool{}
+	for _, p := range w.prefixes {
+		taken[p] = true
+	}
+	for n := len(w.declared) + 1; ; n++ {
+		if prefix := "ns" + strconv.Itoa(n); !taken[prefix] {
+			w.declared[ns] = prefix
+			return prefix
+		}
+	}
+}
+
+func (w *walker) globalElement(ref string) *model.XSDElement {
+	return globalElement(w.schema, ref)
+}
+
+// substitutes returns the elements that can appear in place of the global element head: head unless it
+// is abstract, then the members of its substitution group, recursively, in schema order.
+func substitutes(schema *model.XSDSchema, head *model.XSDElement) []*model.XSDElement {
+	var candidates []*model.XSDElement
+	seen := map[*model.XSDElement]bool{}
+	var add func(el *model.XSDElement)
+	add = func(el *model.XSDElement) {
+		if seen[el] {
+			return
+		}
+		seen[el] = true
+		if !el.Abstract {
+			candidates = append(candidates, el)
+		}
+		for i := range schema.Elements {
+			member := &schema.Elements[i]
+			if member.SubstitutionGroup != "" && globalElement(schema, member.SubstitutionGroup) == el {
+				add(member)
+			}
 		}
-		// Add the attribute to the element
-		elem.CreateAttr(attr.Name, val)
 	}
+	add(head)
+	return candidates
+}
+
+// globalElement resolves the QName of an element reference, falling back to its local name for prefixes
+// that are not declared on the main schema document.
+func globalElement(schema *model.XSDSchema, ref string) *model.XSDElement {
+	if root, err := FindRoot(schema, ref); err == nil {
+		return root.Element
+	}
+	if root, err := FindRoot(schema, localName(ref)); err == nil {
+		return root.Element
+	}
+	return nil
+}
+
+// namespace returns the namespace of el, declared in the schema document origin: the target namespace
+// for global elements, and for local ones only when they are qualified.
+func (w *walker) namespace(el *model.XSDElement, origin *model.XSDOrigin) string {
+	for i := range w.schema.Elements {
+		if &w.schema.Elements[i] == el {
+			return origin.TargetNamespace
+		}
+	}
+	return origin.ElementNamespace(el.Form)
+}
+
+func (w *walker) simpleType(name string) *model.XSDSimpleType {
+	return findSimpleType(w.schema, name)
+}
+
+// originOf returns the schema document declaring a global element or named type.
+func (w *walker) originOf(component any) *model.XSDOrigin {
+	var origin *model.XSDOrigin
+	switch c := component.(type) {
+	case *model.XSDElement:
+		origin = c.Origin
+	case *model.XSDComplexType:
+		origin = c.Origin
+	}
+	if origin == nil {
+		return w.schema.Origin()
+	}
+	return origin
+}
+
+// isBuiltin reports whether a type QName uses one of the conventional XML Schema prefixes.
+func isBuiltin(qname string) bool {
+	return strings.HasPrefix(qname, "xs:") || strings.HasPrefix(qname, "xsd:")
+}
+
+// localName strips the namespace prefix of a QName.
+func localName(qname string) string {
+	return qname[strings.LastIndex(qname, ":")+1:]
 }
@@ -1,18 +1,21 @@
 package xmlgen
 
 import (
+	"maps"
 	"strings"
 	"testing"
 
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/helpers"
 	"github.com/Patrick-Ivann/xsd-codegen/pkg/model"
 	"github.com/Patrick-Ivann/xsd-codegen/pkg/xmlgen/mocks"
 	"github.com/beevik/etree"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestGenerateElement(t *testing.T) {
 	mockGen := new(mocks.MockValueGenerator)
-	mockGen.On("Generate", "xs:string", (*model.XSDRestriction)(nil)).Return("MockTitle")
+	mockGen.On("Generate", mocks.Base("xsd:string")).Return("MockTitle", true)
 
 	schema := &model.XSDSchema{
 		Elements: []model.XSDElement{
@@ -23,12 +26,14 @@ func TestGenerateElement(t *testing.T) {
 	if elem.Tag != "test" {
 		t.Errorf("expected tag 'test', got %s", elem.Tag)
 	}
+	assert.Equal(t, "MockTitle", elem.Text())
+	mockGen.AssertExpectations(t)
 }
 
 func TestGenerateDocument(t *testing.T) {
 
 	mockGen := new(mocks.MockValueGenerator)
-	mockGen.On("Generate", "xs:string", (*model.XSDRestriction)(nil)).Return("MockTitle")
+	mockGen.On("Generate", mocks.Base("xsd:string")).Return("MockTitle", true)
 
 	schema := &model.XSDSchema{
 		Elements: []model.XSDElement{
@@ -50,16 +55,14 @@ func TestGenerateDocument(t *testing.T) {
 	doc.SetRoot(root)
 
 	out, err := doc.WriteToString()
-	if err != nil || !strings.Contains(out, "<child>") {
+	if err != nil || !strings.Contains(out, "<child>MockTitle</child>") {
 		t.Error("Expected output with <child> tag")
 	}
 }
 
 func TestGenerateElementWhenElementHasTnsComplexType(t *testing.T) {
 	mockGen := new(mocks.MockValueGenerator)
-	mockGen.On("Generate", "xs:string", (*model.XSDRestriction)(nil)).Return("MockTitle")
-	mockGen.On("Generate", "xs:string", (*model.XSDRestriction)(nil)).Return("MockTitle")
-	mockGen.On("Generate", "xs:int", (*model.XSDRestriction)(nil)).Return(31)
+	mockGen.On("Generate", mocks.Base("xs:string")).Return("MockTitle", true)
 
 	schema := &model.XSDSchema{
 		ComplexTypes: []model.XSDComplexType{
@@ -83,14 +86,15 @@ func TestGenerateElementWhenElementHasTnsComplexType(t *testing.T) {
 
 	elem := GenerateElement(schema, &schema.Elements[0], mockGen)
 	assert.Equal(t, "Person", elem.Tag)
-	assert.NotNil(t, elem.SelectElement("FirstName"))
-	assert.NotNil(t, elem.SelectElement("LastName"))
-	assert.NotEmpty(t, elem.SelectAttrValue("id", ""))
+	assert.Equal(t, "MockTitle", elem.SelectElement("FirstName").Text())
+	assert.Equal(t, "MockTitle", elem.SelectElement("LastName").Text())
+	assert.Equal(t, "69", elem.SelectAttrValue("id", ""), "fixed values skip the generator")
+	mockGen.AssertNumberOfCalls(t, "Generate", 2)
 }
 
 func TestGenerateElementWhenElementHasTnsSimpleType(t *testing.T) {
 	mockGen := new(mocks.MockValueGenerator)
-	mockGen.On("Generate", "xs:int", (*model.XSDRestriction)(nil)).Return(69)
+	mockGen.On("Generate", mocks.Base("xs:int")).Return("69", true)
 
 	schema := &model.XSDSchema{
 		SimpleTypes: []model.XSDSimpleType{
@@ -108,12 +112,12 @@ func TestGenerateElementWhenElementHasTnsSimpleType(t *testing.T) {
 
 	elem := GenerateElement(schema, &schema.Elements[0], mockGen)
 	assert.Equal(t, "Age", elem.Tag)
-	assert.NotEmpty(t, elem.Text())
+	assert.Equal(t, "69", elem.Text())
 }
 
 func TestGenerateElementWhenElementHasPrimitiveType(t *testing.T) {
 	mockGen := new(mocks.MockValueGenerator)
-	mockGen.On("Generate", "xs:string", (*model.XSDRestriction)(nil)).Return("MockTitle")
+	mockGen.On("Generate", mocks.Base("xs:string")).Return("MockTitle", true)
 
 	schema := &model.XSDSchema{
 		Elements: []model.XSDElement{
@@ -123,13 +127,12 @@ func TestGenerateElementWhenElementHasPrimitiveType(t *testing.T) {
 
 	elem := GenerateElement(schema, &schema.Elements[0], mockGen)
 	assert.Equal(t, "Title", elem.Tag)
-	assert.NotEmpty(t, elem.Text())
+	assert.Equal(t, "MockTitle", elem.Text())
 }
 
 func TestGenerateElementWhenElementHasInlineComplexType(t *testing.T) {
 	mockGen := new(mocks.MockValueGenerator)
-	mockGen.On("Generate", "xs:string", (*model.XSDRestriction)(nil)).Return("MockTitle")
-	mockGen.On("Generate", "xs:string", (*model.XSDRestriction)(nil)).Return("MockTitle")
+	mockGen.On("Generate", mocks.Base("xs:string")).Return("MockTitle", true)
 
 	schema := &model.XSDSchema{
 		Elements: []model.XSDElement{
@@ -149,13 +152,13 @@ func TestGenerateElementWhenElementHasInlineComplexType(t *testing.T) {
 
 	elem := GenerateElement(schema, &schema.Elements[0], mockGen)
 	assert.Equal(t, "Book", elem.Tag)
-	assert.NotNil(t, elem.SelectElement("Title"))
-	assert.NotNil(t, elem.SelectElement("Author"))
+	assert.Equal(t, "MockTitle", elem.SelectElement("Title").Text())
+	assert.Equal(t, "MockTitle", elem.SelectElement("Author").Text())
 }
 
 func TestGenerateElementWhenElementHasInlineSimpleType(t *testing.T) {
 	mockGen := new(mocks.MockValueGenerator)
-	mockGen.On("Generate", "xs:int", (*model.XSDRestriction)(nil)).Return(93)
+	mockGen.On("Generate", mocks.Base("xs:int")).Return("93", true)
 
 	schema := &model.XSDSchema{
 		Elements: []model.XSDElement{
@@ -172,13 +175,13 @@ func TestGenerateElementWhenElementHasInlineSimpleType(t *testing.T) {
 
 	elem := GenerateElement(schema, &schema.Elements[0], mockGen)
 	assert.Equal(t, "Rating", elem.Tag)
-	assert.NotEmpty(t, elem.Text())
+	assert.Equal(t, "93", elem.Text())
 }
 
 func TestGenerateElementWhenElementIsAReference(t *testing.T) {
 
 	mockGen := new(mocks.MockValueGenerator)
-	mockGen.On("Generate", "xs:string", (*model.XSDRestriction)(nil)).Return("MockTitle")
+	mockGen.On("Generate", mocks.Base("xs:string")).Return("MockTitle", true)
 
 	schema := &model.XSDSchema{
 		Elements: []model.XSDElement{
@@ -195,13 +198,12 @@ func TestGenerateElementWhenElementIsAReference(t *testing.T) {
 
 	elem := GenerateElement(schema, &schema.Elements[1], mockGen)
 	assert.Equal(t, "Original", elem.Tag)
-	assert.NotEmpty(t, elem.Text())
+	assert.Equal(t, "MockTitle", elem.Text())
 }
 
 func TestGenerateElementWhenComplexTypeHasChoice(t *testing.T) {
 	mockGen := new(mocks.MockValueGenerator)
-	mockGen.On("Generate", "xs:string", (*model.XSDRestriction)(nil)).Return("MockTitle")
-	mockGen.On("Generate", "xs:string", (*model.XSDRestriction)(nil)).Return("MockTitle")
+	mockGen.On("Generate", mocks.Base("xs:string")).Return("MockTitle", true)
 
 	schema := &model.XSDSchema{
 		ComplexTypes: []model.XSDComplexType{
@@ -230,5 +232,132 @@ func TestGenerateElementWhenComplexTypeHasChoice(t *testing.T) {
 
 	child := children[0]
 	assert.Contains(t, []string{"Email", "Phone"}, child.Tag)
-	assert.NotEmpty(t, child.Text())
+	assert.Equal(t, "MockTitle", child.Text())
+}
+
+func TestGenerateElementWhenSequenceNestsChoiceAndSequence(t *testing.T) {
+	mockGen := new(mocks.MockValueGenerator)
+	mockGen.On("Generate", mock.Anything).Return("", false)
+
+	schema := &model.XSDSchema{
+		ComplexTypes: []model.XSDComplexType{
+			{
+				Name: "PaymentType",
+				Sequence: &model.XSDSequence{
+					Elements: []model.XSDElement{{Name: "Amount", Type: "xs:decimal"}},
+					Choices: []model.XSDChoice{{
+						Elements: []model.XSDElement{{Name: "Card", Type: "xs:string"}},
+						Sequences: []model.XSDSequence{{
+							Elements: []model.XSDElement{
+								{Name: "Iban", Type: "xs:string"},
+								{Name: "Bic", Type: "xs:string"},
+							},
+						}},
+					}},
+				},
+			},
+		},
+		Elements: []model.XSDElement{{Name: "Payment", Type: "tns:PaymentType"}},
+	}
+
+	elem := GenerateElement(schema, &schema.Elements[0], mockGen)
+	var tags []string
+	for _, child := range elem.ChildElements() {
+		tags = append(tags, child.Tag)
+	}
+	assert.Contains(t, [][]string{{"Amount", "Card"}, {"Amount", "Iban", "Bic"}}, tags)
+	for _, child := range elem.ChildElements() {
+		assert.NotEmpty(t, child.Text(), "declined values are random")
+	}
+}
+
+func TestGenerateElementIsReproducibleWithSeededGenerator(t *testing.T) {
+	schema := &model.XSDSchema{
+		Elements: []model.XSDElement{{
+			Name: "root",
+			ComplexType: &model.XSDComplexType{
+				Sequence: &model.XSDSequence{
+					Elements: []model.XSDElement{
+						{Name: "code", SimpleType: &model.XSDSimpleType{Restriction: &model.XSDRestriction{
+							Base: "xs:string", Pattern: &model.XSDPattern{Value: `[A-Z]{3}-\d+`},
+						}}},
+						{Name: "when", Type: "xs:dateTime", MaxOccurs: "unbounded"},
+					},
+					Choices: []model.XSDChoice{{
+						Elements: []model.XSDElement{{Name: "a", Type: "xs:int"}, {Name: "b", Type: "xs:boolean"}},
+					}},
+				},
+				Attrs: []model.XSDAttribute{{Name: "id", Type: "xs:NMTOKEN"}},
+			},
+		}},
+	}
+	generate := func(seed uint64) string {
+		doc := etree.NewDocument()
+		doc.SetRoot(GenerateElement(schema, &schema.Elements[0], helpers.DefaultValueGenerator{Source: helpers.NewRand(seed)}))
+		out, err := doc.WriteToString()
+		assert.NoError(t, err)
+		return out
+	}
+
+	first := generate(42)
+	for range 5 {
+		assert.Equal(t, first, generate(42))
+	}
+	differs := false
+	for seed := range uint64(5) {
+		differs = differs || generate(seed) != first
+	}
+	assert.True(t, differs, "other seeds give other documents")
+}
+
+func TestGenerateElementDescribesValuesToGenerator(t *testing.T) {
+	schema := &model.XSDSchema{
+		SimpleTypes: []model.XSDSimpleType{
+			{Name: "Positive", Restriction: &model.XSDRestriction{Base: "xs:int", MinIncl: &model.XSDValue{Value: "1"}}},
+			{Name: "Quantity", Restriction: &model.XSDRestriction{Base: "tns:Positive", MaxIncl: &model.XSDValue{Value: "9"}}},
+		},
+		Elements: []model.XSDElement{{
+			Name: "order",
+			ComplexType: &model.XSDComplexType{Sequence: &model.XSDSequence{Elements: []model.XSDElement{{
+				Name: "line", MinOccurs: "2", MaxOccurs: "2",
+				ComplexType: &model.XSDComplexType{
+					Sequence: &model.XSDSequence{Elements: []model.XSDElement{
+						{Name: "quantity", Type: "tns:Quantity"},
+						{Name: "price", Type: "xs:decimal"},
+					}},
+					Attrs: []model.XSDAttribute{{Name: "status", Type: "xs:string"}},
+				},
+			}}}},
+		}},
+	}
+	contexts := map[string]helpers.ValueContext{}
+	gen := helpers.ValueGeneratorFunc(func(vc *helpers.ValueContext) (string, bool) {
+		snapshot := *vc
+		snapshot.Parent = maps.Clone(vc.Parent)
+		contexts[vc.Path] = snapshot
+		return "value of " + vc.Path, true
+	})
+	GenerateElement(schema, &schema.Elements[0], helpers.ReadingParent(gen))
+
+	quantity := contexts["/order/line[2]/quantity"]
+	assert.Equal(t, "quantity", quantity.Name.Local)
+	assert.Equal(t, "tns:Quantity", quantity.Type)
+	assert.Equal(t, "xs:int", quantity.Base)
+	assert.Equal(t, "1", quantity.Restriction.MinIncl.Value, "facets of restricted types are inherited")
+	assert.Equal(t, "9", quantity.Restriction.MaxIncl.Value)
+	assert.Empty(t, quantity.Parent)
+
+	price := contexts["/order/line[2]/price"]
+	assert.Equal(t, "xs:decimal", price.Type)
+	assert.Nil(t, price.Restriction)
+	assert.Equal(t, "value of /order/line[2]/quantity", price.Parent["quantity"])
+
+	status := contexts["/order/line[2]/@status"]
+	assert.True(t, status.Attribute)
+	assert.Equal(t, 1, status.Occurrence)
+	assert.Equal(t, map[string]string{
+		"quantity": "value of /order/line[2]/quantity",
+		"price":    "value of /order/line[2]/price",
+	}, status.Parent, "the parent of an attribute is its element")
+	assert.Len(t, contexts, 6)
 }
@@ -0,0 +1,96 @@
+package xsdtypes
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// HexBinary is an xs:hexBinary. It prints in the canonical upper-case form and parses either case.
+type HexBinary []byte
+
+// ParseHexBinary parses an xs:hexBinary lexical value such as "0FB7".
+func ParseHexBinary(s string) (HexBinary, error) {
+	b, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("xsdtypes: invalid hexBinary %q: %w", s, err)
+	}
+	return b, nil
+}
+
+// String returns the canonical xs:hexBinary lexical value.
+func (h HexBinary) String() string { return strings.ToUpper(hex.EncodeToString(h)) }
+
+// MarshalText implements encoding.TextMarshaler.
+func (h HexBinary) MarshalText() ([]byte, error) { return []byte(h.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (h *HexBinary) UnmarshalText(text []byte) (err error) {
+	*h, err = ParseHexBinary(string(text))
+	return err
+}
+
+// MarshalXML implements xml.Marshaler.
+func (h HexBinary) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeElement(e, start, h.String())
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (h *HexBinary) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	return decodeElement(h, dec, start)
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr.
+func (h HexBinary) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: h.String()}, nil
+}
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr.
+func (h *HexBinary) UnmarshalXMLAttr(attr xml.Attr) error { return h.UnmarshalText([]byte(attr.Value)) }
+
+// Base64Binary is an xs:base64Binary. Whitespace inside the lexical value is ignored on parsing.
+type Base64Binary []byte
+
+// ParseBase64Binary parses an xs:base64Binary lexical value such as "aGVsbG8=".
+func ParseBase64Binary(s string) (Base64Binary, error) {
+	compact := strings.Join(strings.Fields(s), "")
+	b, err := base64.StdEncoding.DecodeString(compact)
+	if err != nil {
+		return nil, fmt.Errorf("xsdtypes: invalid base64Binary %q: %w", s, err)
+	}
+	return b, nil
+}
+
+// String returns the xs:base64Binary lexical value.
+func (b Base64Binary) String() string { return base64.StdEncoding.EncodeToString(b) }
+
+// MarshalText implements encoding.TextMarshaler.
+func (b Base64Binary) MarshalText() ([]byte, error) { return []byte(b.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *Base64Binary) UnmarshalText(text []byte) (err error) {
+	*b, err = ParseBase64Binary(string(text))
+	return err
+}
+
+// MarshalXML implements xml.Marshaler.
+func (b Base64Binary) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeElement(e, start, b.String())
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (b *Base64Binary) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	return decodeElement(b, dec, start)
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr.
+func (b Base64Binary) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: b.String()}, nil
+}
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr.
+func (b *Base64Binary) UnmarshalXMLAttr(attr xml.Attr) error {
+	return b.UnmarshalText([]byte(attr.Value))
+}
@@ -0,0 +1,254 @@
+package xsdtypes
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Layouts of the calendar types. Fractional seconds are accepted on parsing and printed only when non-zero.
+// Years may have more than four digits or be negative, and the end of day 24:00:00 parses as the start
+// of the next day, which is how it prints.
+const (
+	dateLayout       = "2006-01-02"
+	dateTimeLayout   = "2006-01-02T15:04:05.999999999"
+	timeLayout       = "15:04:05.999999999"
+	gYearLayout      = "2006"
+	gYearMonthLayout = "2006-01"
+)
+
+// Date is an xs:date. HasTimezone records whether the lexical value carried a timezone;
+// when it did not, Time is expressed in UTC.
+type Date struct {
+	Time        time.Time
+	HasTimezone bool
+}
+
+// NewDate returns the date of t, keeping its timezone.
+func NewDate(t time.Time) Date { return Date{Time: t, HasTimezone: true} }
+
+// ParseDate parses an xs:date lexical value such as "2002-10-10" or "2002-10-10+13:00".
+func ParseDate(s string) (Date, error) {
+	t, tz, err := parseTimeValue("date", s, dateLayout)
+	return Date{Time: t, HasTimezone: tz}, err
+}
+
+// String returns the xs:date lexical value.
+func (d Date) String() string {
+	return d.Time.Format(dateLayout) + formatTimezone(d.Time, d.HasTimezone)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Date) MarshalText() ([]byte, error) { return []byte(d.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Date) UnmarshalText(text []byte) (err error) {
+	*d, err = ParseDate(string(text))
+	return err
+}
+
+// MarshalXML implements xml.Marshaler.
+func (d Date) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeElement(e, start, d.String())
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (d *Date) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	return decodeElement(d, dec, start)
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr.
+func (d Date) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: d.String()}, nil
+}
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr.
+func (d *Date) UnmarshalXMLAttr(attr xml.Attr) error { return d.UnmarshalText([]byte(attr.Value)) }
+
+// DateTime is an xs:dateTime. HasTimezone records whether the lexical value carried a timezone;
+// when it did not, Time is expressed in UTC.
+type DateTime struct {
+	Time        time.Time
+	HasTimezone bool
+}
+
+// NewDateTime returns t as a dateTime, keeping its timezone.
+func NewDateTime(t time.Time) DateTime { return DateTime{Time: t, HasTimezone: true} }
+
+// ParseDateTime parses an xs:dateTime lexical value such as "2002-10-10T12:00:00.5-05:00".
+func ParseDateTime(s string) (DateTime, error) {
+	t, tz, err := parseTimeValue("dateTime", s, dateTimeLayout)
+	return DateTime{Time: t, HasTimezone: tz}, err
+}
+
+// String returns the xs:dateTime lexical value.
+func (d DateTime) String() string {
+	return d.Time.Format(dateTimeLayout) + formatTimezone(d.Time, d.HasTimezone)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d DateTime) MarshalText() ([]byte, error) { return []byte(d.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *DateTime) UnmarshalText(text []byte) (err error) {
+	*d, err = ParseDateTime(string(text))
+	return err
+}
+
+// MarshalXML implements xml.Marshaler.
+func (d DateTime) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeElement(e, start, d.String())
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (d *DateTime) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	return decodeElement(d, dec, start)
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr.
+func (d DateTime) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: d.String()}, nil
+}
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr.
+func (d *DateTime) UnmarshalXMLAttr(attr xml.Attr) error { return d.UnmarshalText([]byte(attr.Value)) }
+
+// Time is an xs:time. Only the clock part of Time is meaningful; HasTimezone records whether
+// the lexical value carried a timezone.
+type Time struct {
+	Time        time.Time
+	HasTimezone bool
+}
+
+// NewTime returns the time of day of t, keeping its timezone.
+func NewTime(t time.Time) Time { return Time{Time: t, HasTimezone: true} }
+
+// ParseTime parses an xs:time lexical value such as "13:20:00" or "13:20:00.25Z".
+func ParseTime(s string) (Time, error) {
+	t, tz, err := parseTimeValue("time", s, timeLayout)
+	return Time{Time: t, HasTimezone: tz}, err
+}
+
+// String returns the xs:time lexical value.
+func (t Time) String() string {
+	return t.Time.Format(timeLayout) + formatTimezone(t.Time, t.HasTimezone)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (t Time) MarshalText() ([]byte, error) { return []byte(t.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *Time) UnmarshalText(text []byte) (err error) {
+	*t, err = ParseTime(string(text))
+	return err
+}
+
+// MarshalXML implements xml.Marshaler.
+func (t Time) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeElement(e, start, t.String())
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (t *Time) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	return decodeElement(t, dec, start)
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr.
+func (t Time) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: t.String()}, nil
+}
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr.
+func (t *Time) UnmarshalXMLAttr(attr xml.Attr) error { return t.UnmarshalText([]byte(attr.Value)) }
+
+// GYear is an xs:gYear, a Gregorian calendar year with an optional timezone.
+type GYear struct {
+	Time        time.Time
+	HasTimezone bool
+}
+
+// ParseGYear parses an xs:gYear lexical value such as "1999" or "1999Z".
+func ParseGYear(s string) (GYear, error) {
+	t, tz, err := parseTimeValue("gYear", s, gYearLayout)
+	return GYear{Time: t, HasTimezone: tz}, err
+}
+
+// Year returns the calendar year.
+func (g GYear) Year() int { return g.Time.Year() }
+
+// String returns the xs:gYear lexical value.
+func (g GYear) String() string {
+	return g.Time.Format(gYearLayout) + formatTimezone(g.Time, g.HasTimezone)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (g GYear) MarshalText() ([]byte, error) { return []byte(g.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (g *GYear) UnmarshalText(text []byte) (err error) {
+	*g, err = ParseGYear(string(text))
+	return err
+}
+
+// MarshalXML implements xml.Marshaler.
+func (g GYear) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeElement(e, start, g.String())
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (g *GYear) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	return decodeElement(g, dec, start)
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr.
+func (g GYear) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: g.String()}, nil
+}
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr.
+func (g *GYear) UnmarshalXMLAttr(attr xml.Attr) error { return g.UnmarshalText([]byte(attr.Value)) }
+
+// GYearMonth is an xs:gYearMonth, a Gregorian calendar month with an optional timezone.
+type GYearMonth struct {
+	Time        time.Time
+	HasTimezone bool
+}
+
+// ParseGYearMonth parses an xs:gYearMonth lexical value such as "1999-05" or "1999-05-03:00".
+func ParseGYearMonth(s string) (GYearMonth, error) {
+	t, tz, err := parseTimeValue("gYearMonth", s, gYearMonthLayout)
+	return GYearMonth{Time: t, HasTimezone: tz}, err
+}
+
+// String returns the xs:gYearMonth lexical value.
+func (g GYearMonth) String() string {
+	return g.Time.Format(gYearMonthLayout) + formatTimezone(g.Time, g.HasTimezone)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (g GYearMonth) MarshalText() ([]byte, error) { return []byte(g.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (g *GYearMonth) UnmarshalText(text []byte) (err error) {
+	*g, err = ParseGYearMonth(string(text))
+	return err
+}
+
+// MarshalXML implements xml.Marshaler.
+func (g GYearMonth) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeElement(e, start, g.String())
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (g *GYearMonth) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	return decodeElement(g, dec, start)
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr.
+func (g GYearMonth) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: g.String()}, nil
+}
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr.
+func (g *GYearMonth) UnmarshalXMLAttr(attr xml.Attr) error {
+	return g.UnmarshalText([]byte(attr.Value))
+}
@@ -0,0 +1,110 @@
+package xsdtypes
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+
+// Decimal is an arbitrary precision xs:decimal stored as an unscaled integer and a number of
+// fraction digits, so "12.50" keeps both its value and its lexical scale. The zero value is 0.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int
+}
+
+// ParseDecimal parses an xs:decimal lexical value such as "-1.23", "+100000.00" or "210".
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	if !decimalPattern.MatchString(s) {
+		return Decimal{}, fmt.Errorf("xsdtypes: invalid decimal %q", s)
+	}
+	intPart, fracPart, _ := strings.Cut(strings.TrimPrefix(s, "+"), ".")
+	unscaled, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("xsdtypes: invalid decimal %q", s)
+	}
+	return Decimal{unscaled: unscaled, scale: len(fracPart)}, nil
+}
+
+// NewDecimal returns unscaled × 10^-scale.
+func NewDecimal(unscaled int64, scale int) Decimal {
+	return Decimal{unscaled: big.NewInt(unscaled), scale: scale}
+}
+
+// value returns the unscaled value, treating the zero Decimal as 0.
+func (d Decimal) value() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// Rat returns the exact value of d.
+func (d Decimal) Rat() *big.Rat {
+	if d.scale < 0 {
+		factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-d.scale)), nil)
+		return new(big.Rat).SetInt(new(big.Int).Mul(d.value(), factor))
+	}
+	denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.scale)), nil)
+	return new(big.Rat).SetFrac(d.value(), denom)
+}
+
+// Float64 returns the nearest float64 to d.
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// Cmp compares d and other, returning -1, 0 or +1.
+func (d Decimal) Cmp(other Decimal) int {
+	return d.Rat().Cmp(other.Rat())
+}
+
+// String returns the xs:decimal lexical value with the scale the value was created with.
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.value()).String()
+	sign := ""
+	if d.value().Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale <= 0 {
+		return sign + digits + strings.Repeat("0", -d.scale)
+	}
+	if len(digits) <= d.scale {
+		digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+	}
+	point := len(digits) - d.scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Decimal) MarshalText() ([]byte, error) { return []byte(d.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Decimal) UnmarshalText(text []byte) (err error) {
+	*d, err = ParseDecimal(string(text))
+	return err
+}
+
+// MarshalXML implements xml.Marshaler.
+func (d Decimal) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeElement(e, start, d.String())
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (d *Decimal) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	return decodeElement(d, dec, start)
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr.
+func (d Decimal) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: d.String()}, nil
+}
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr.
+func (d *Decimal) UnmarshalXMLAttr(attr xml.Attr) error { return d.UnmarshalText([]byte(attr.Value)) }
@@ -0,0 +1,32 @@
+package xsdtypes
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+// Decode parses the lexical form of a simple value into v, a pointer to any type encoding/xml can decode
+// from element content: the types of this package, Go basic types and text unmarshalers.
+func Decode(lexical string, v any) error {
+	var buf bytes.Buffer
+	buf.WriteString("<v>")
+	if err := xml.EscapeText(&buf, []byte(lexical)); err != nil {
+		return err
+	}
+	buf.WriteString("</v>")
+	if err := xml.Unmarshal(buf.Bytes(), v); err != nil {
+		return fmt.Errorf("decode %q: %w", lexical, err)
+	}
+	return nil
+}
+
+// MustDecode returns the T holding lexical and panics when lexical is not a valid T.
+// It is meant for values known to be valid, such as the default values of a schema.
+func MustDecode[T any](lexical string) T {
+	var v T
+	if err := Decode(lexical, &v); err != nil {
+		panic(err)
+	}
+	return v
+}
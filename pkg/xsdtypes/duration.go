@@ -0,0 +1,119 @@
+package xsdtypes
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// durationPattern matches the xs:duration lexical space; the fraction keeps at most nanosecond precision.
+var durationPattern = regexp.MustCompile(
+	`^(-)?P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)(?:\.(\d+))?S)?)?$`)
+
+// Duration is an xs:duration. Components are kept separately because months and years have no fixed
+// length, so "P1M" cannot be converted to a time.Duration without a reference date.
+type Duration struct {
+	Negative    bool
+	Years       int
+	Months      int
+	Days        int
+	Hours       int
+	Minutes     int
+	Seconds     int
+	Nanoseconds int
+}
+
+// ParseDuration parses an xs:duration lexical value such as "P1Y2M3DT10H30M" or "-PT0.5S".
+func ParseDuration(s string) (Duration, error) {
+	s = strings.TrimSpace(s)
+	m := durationPattern.FindStringSubmatch(s)
+	if m == nil || s == "P" || s == "-P" || strings.HasSuffix(s, "T") {
+		return Duration{}, fmt.Errorf("xsdtypes: invalid duration %q", s)
+	}
+	var d Duration
+	d.Negative = m[1] == "-"
+	fields := []*int{&d.Years, &d.Months, &d.Days, &d.Hours, &d.Minutes, &d.Seconds}
+	for i, field := range fields {
+		if m[i+2] == "" {
+			continue
+		}
+		v, err := strconv.Atoi(m[i+2])
+		if err != nil {
+			return Duration{}, fmt.Errorf("xsdtypes: invalid duration %q: %w", s, err)
+		}
+		*field = v
+	}
+	if frac := m[8]; frac != "" {
+		// Right-pad to nine digits and drop anything beyond nanosecond precision.
+		frac = (frac + "000000000")[:9]
+		d.Nanoseconds, _ = strconv.Atoi(frac)
+	}
+	return d, nil
+}
+
+// String returns the xs:duration lexical value, omitting zero components. The zero Duration prints as "PT0S".
+func (d Duration) String() string {
+	var sb strings.Builder
+	if d.Negative {
+		sb.WriteByte('-')
+	}
+	sb.WriteByte('P')
+	writeComponent(&sb, d.Years, 'Y')
+	writeComponent(&sb, d.Months, 'M')
+	writeComponent(&sb, d.Days, 'D')
+	if d.Hours == 0 && d.Minutes == 0 && d.Seconds == 0 && d.Nanoseconds == 0 {
+		if sb.Len() == 1 || (d.Negative && sb.Len() == 2) {
+			sb.WriteString("T0S")
+		}
+		return sb.String()
+	}
+	sb.WriteByte('T')
+	writeComponent(&sb, d.Hours, 'H')
+	writeComponent(&sb, d.Minutes, 'M')
+	if d.Seconds != 0 || d.Nanoseconds != 0 {
+		sb.WriteString(strconv.Itoa(d.Seconds))
+		if d.Nanoseconds != 0 {
+			sb.WriteString(strings.TrimRight(fmt.Sprintf(".%09d", d.Nanoseconds), "0"))
+		}
+		sb.WriteByte('S')
+	}
+	return sb.String()
+}
+
+// writeComponent appends a non-zero duration component followed by its designator.
+func writeComponent(sb *strings.Builder, v int, designator byte) {
+	if v == 0 {
+		return
+	}
+	sb.WriteString(strconv.Itoa(v))
+	sb.WriteByte(designator)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) { return []byte(d.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) (err error) {
+	*d, err = ParseDuration(string(text))
+	return err
+}
+
+// MarshalXML implements xml.Marshaler.
+func (d Duration) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeElement(e, start, d.String())
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (d *Duration) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	return decodeElement(d, dec, start)
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr.
+func (d Duration) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: d.String()}, nil
+}
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr.
+func (d *Duration) UnmarshalXMLAttr(attr xml.Attr) error { return d.UnmarshalText([]byte(attr.Value)) }
@@ -0,0 +1,96 @@
+package xsdtypes
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+var integerPattern = regexp.MustCompile(`^[+-]?\d+$`)
+
+// Integer is an arbitrary precision xs:integer, also used for the types derived from it without
+// bounds, such as xs:positiveInteger. The zero value is 0.
+type Integer struct {
+	value *big.Int
+}
+
+// ParseInteger parses an xs:integer lexical value such as "-12", "+007" or "123456789012345678901234".
+func ParseInteger(s string) (Integer, error) {
+	s = strings.TrimSpace(s)
+	if !integerPattern.MatchString(s) {
+		return Integer{}, fmt.Errorf("xsdtypes: invalid integer %q", s)
+	}
+	v, ok := new(big.Int).SetString(strings.TrimPrefix(s, "+"), 10)
+	if !ok {
+		return Integer{}, fmt.Errorf("xsdtypes: invalid integer %q", s)
+	}
+	return Integer{value: v}, nil
+}
+
+// NewInteger returns the Integer holding v.
+func NewInteger(v int64) Integer {
+	return Integer{value: big.NewInt(v)}
+}
+
+// NewBigInteger returns the Integer holding a copy of v.
+func NewBigInteger(v *big.Int) Integer {
+	return Integer{value: new(big.Int).Set(v)}
+}
+
+// Big returns a copy of the value of i.
+func (i Integer) Big() *big.Int {
+	if i.value == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(i.value)
+}
+
+// Int64 returns the value of i and whether it fits an int64.
+func (i Integer) Int64() (int64, bool) {
+	if i.value == nil {
+		return 0, true
+	}
+	return i.value.Int64(), i.value.IsInt64()
+}
+
+// Cmp compares i and other, returning -1, 0 or +1.
+func (i Integer) Cmp(other Integer) int {
+	return i.Big().Cmp(other.Big())
+}
+
+// String returns the canonical xs:integer lexical value, without leading zeros or plus sign.
+func (i Integer) String() string {
+	if i.value == nil {
+		return "0"
+	}
+	return i.value.String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (i Integer) MarshalText() ([]byte, error) { return []byte(i.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (i *Integer) UnmarshalText(text []byte) (err error) {
+	*i, err = ParseInteger(string(text))
+	return err
+}
+
+// MarshalXML implements xml.Marshaler.
+func (i Integer) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeElement(e, start, i.String())
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (i *Integer) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	return decodeElement(i, dec, start)
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr.
+func (i Integer) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: i.String()}, nil
+}
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr.
+func (i *Integer) UnmarshalXMLAttr(attr xml.Attr) error { return i.UnmarshalText([]byte(attr.Value)) }
@@ -0,0 +1,62 @@
+package xsdtypes
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// QName is an xs:QName in its lexical form. Prefix is empty for unprefixed names; resolving the prefix
+// to a namespace URI is left to the caller since it depends on the declarations in scope.
+type QName struct {
+	Prefix string
+	Local  string
+}
+
+// ParseQName parses an xs:QName lexical value such as "tns:PurchaseOrderType".
+func ParseQName(s string) (QName, error) {
+	s = strings.TrimSpace(s)
+	prefix, local, found := strings.Cut(s, ":")
+	if !found {
+		prefix, local = "", s
+	}
+	if local == "" || (found && prefix == "") || strings.ContainsAny(local, ": \t\n") {
+		return QName{}, fmt.Errorf("xsdtypes: invalid QName %q", s)
+	}
+	return QName{Prefix: prefix, Local: local}, nil
+}
+
+// String returns the xs:QName lexical value.
+func (q QName) String() string {
+	if q.Prefix == "" {
+		return q.Local
+	}
+	return q.Prefix + ":" + q.Local
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (q QName) MarshalText() ([]byte, error) { return []byte(q.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (q *QName) UnmarshalText(text []byte) (err error) {
+	*q, err = ParseQName(string(text))
+	return err
+}
+
+// MarshalXML implements xml.Marshaler.
+func (q QName) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeElement(e, start, q.String())
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (q *QName) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	return decodeElement(q, dec, start)
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr.
+func (q QName) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: q.String()}, nil
+}
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr.
+func (q *QName) UnmarshalXMLAttr(attr xml.Attr) error { return q.UnmarshalText([]byte(attr.Value)) }
@@ -0,0 +1,149 @@
+// Package xsdtypes provides Go representations of the XML Schema built-in types that have no
+// lossless native equivalent: calendar values, durations, arbitrary precision integers and decimals,
+// binary encodings and qualified names.
+//
+// Every type parses and prints the XSD lexical form and implements encoding.TextMarshaler,
+// encoding.TextUnmarshaler, xml.Marshaler, xml.Unmarshaler, xml.MarshalerAttr and xml.UnmarshalerAttr,
+// so it can be used directly as a struct field in code generated by xsd-codegen.
+package xsdtypes
+
+import (
+	"encoding"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// splitTimezone separates the optional timezone suffix ("Z", "+hh:mm" or "-hh:mm") of a date/time
+// lexical value. The returned location is nil when the value carries no timezone.
+func splitTimezone(s string) (string, *time.Location, error) {
+	if strings.HasSuffix(s, "Z") {
+		return s[:len(s)-1], time.UTC, nil
+	}
+	if len(s) < 6 {
+		return s, nil, nil
+	}
+	tz := s[len(s)-6:]
+	if (tz[0] != '+' && tz[0] != '-') || tz[3] != ':' {
+		return s, nil, nil
+	}
+	hours, errH := strconv.Atoi(tz[1:3])
+	minutes, errM := strconv.Atoi(tz[4:6])
+	if errH != nil || errM != nil || hours > 14 || minutes > 59 || (hours == 14 && minutes != 0) {
+		return "", nil, fmt.Errorf("invalid timezone %q", tz)
+	}
+	offset := (hours*60 + minutes) * 60
+	if tz[0] == '-' {
+		offset = -offset
+	}
+	return s[:len(s)-6], time.FixedZone(tz, offset), nil
+}
+
+// formatTimezone renders the timezone of t in XSD form, or nothing when hasTimezone is false.
+func formatTimezone(t time.Time, hasTimezone bool) string {
+	if !hasTimezone {
+		return ""
+	}
+	_, offset := t.Zone()
+	if offset == 0 {
+		return "Z"
+	}
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, offset/3600, offset%3600/60)
+}
+
+// parseTimeValue parses a date/time lexical value with the given layout, keeping track of its timezone.
+// Values without timezone are interpreted in UTC. The year of the layouts starting with one is parsed by
+// parseYear, and the end of day 24:00:00 is the first instant of the next day.
+func parseTimeValue(kind, s, layout string) (time.Time, bool, error) {
+	t, hasTimezone, err := parseTimeLayout(strings.TrimSpace(s), layout)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("xsdtypes: invalid %s %q: %w", kind, s, err)
+	}
+	return t, hasTimezone, nil
+}
+
+// parseTimeLayout does the work of parseTimeValue.
+func parseTimeLayout(s, layout string) (time.Time, bool, error) {
+	rest, loc, err := splitTimezone(s)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	hasTimezone := loc != nil
+	if !hasTimezone {
+		loc = time.UTC
+	}
+	year, hasYear := 0, strings.HasPrefix(layout, "2006")
+	if hasYear {
+		if year, rest, err = parseYear(rest); err != nil {
+			return time.Time{}, false, err
+		}
+		// time only parses four-digit years: the rest is parsed after a year as leap as the real one.
+		proxy := "2001"
+		if year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+			proxy = "2000"
+		}
+		rest = proxy + rest
+	}
+	rest, endOfDay := cutEndOfDay(rest)
+	t, err := time.ParseInLocation(layout, rest, loc)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if hasYear {
+		t = time.Date(year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, hasTimezone, nil
+}
+
+// parseYear parses the year that starts s and returns the rest of s. XSD years have at least four digits,
+// without leading zeros past four, and may be negative. They are numbered like those of time, year 0000
+// being 1 BCE, as in XSD 1.1 and ISO 8601.
+func parseYear(s string) (int, string, error) {
+	digits := strings.TrimPrefix(s, "-")
+	n := len(digits) - len(strings.TrimLeft(digits, "0123456789"))
+	if n < 4 || (n > 4 && digits[0] == '0') {
+		return 0, "", fmt.Errorf("invalid year in %q", s)
+	}
+	year, err := strconv.Atoi(s[:len(s)-len(digits)+n])
+	if err != nil || (year == 0 && digits != s) {
+		return 0, "", fmt.Errorf("invalid year in %q", s)
+	}
+	return year, digits[n:], nil
+}
+
+// cutEndOfDay replaces the time 24:00:00, with optional zero fractional seconds, that ends s with
+// 00:00:00, and reports whether it did.
+func cutEndOfDay(s string) (string, bool) {
+	i := strings.LastIndex(s, "24:00:00")
+	if i < 0 || (i > 0 && s[i-1] != 'T') {
+		return s, false
+	}
+	fraction := s[i+len("24:00:00"):]
+	if fraction != "" && (fraction[0] != '.' || len(fraction) == 1 || strings.Trim(fraction[1:], "0") != "") {
+		return s, false
+	}
+	return s[:i] + "00:00:00", true
+}
+
+// encodeElement writes the lexical value as the character data of start.
+func encodeElement(e *xml.Encoder, start xml.StartElement, lexical string) error {
+	return e.EncodeElement(lexical, start)
+}
+
+// decodeElement reads the character data of start and hands it, whitespace-collapsed, to u.
+func decodeElement(u encoding.TextUnmarshaler, d *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := d.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	return u.UnmarshalText([]byte(strings.TrimSpace(s)))
+}
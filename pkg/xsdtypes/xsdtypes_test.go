@@ -0,0 +1,159 @@
+package xsdtypes_test
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/Patrick-Ivann/xsd-codegen/pkg/xsdtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lexical is implemented by every xsdtypes value.
+type lexical interface {
+	String() string
+}
+
+func TestLexicalRoundTrip(t *testing.T) {
+	cases := []struct {
+		input string
+		parse func(string) (lexical, error)
+	}{
+		{"2002-10-10", func(s string) (lexical, error) { return xsdtypes.ParseDate(s) }},
+		{"2002-10-10Z", func(s string) (lexical, error) { return xsdtypes.ParseDate(s) }},
+		{"2002-10-10-05:00", func(s string) (lexical, error) { return xsdtypes.ParseDate(s) }},
+		{"2002-10-10T12:00:00.5+13:00", func(s string) (lexical, error) { return xsdtypes.ParseDateTime(s) }},
+		{"2002-10-10T12:00:00", func(s string) (lexical, error) { return xsdtypes.ParseDateTime(s) }},
+		{"13:20:00.25Z", func(s string) (lexical, error) { return xsdtypes.ParseTime(s) }},
+		{"1999", func(s string) (lexical, error) { return xsdtypes.ParseGYear(s) }},
+		{"1999-05+02:00", func(s string) (lexical, error) { return xsdtypes.ParseGYearMonth(s) }},
+		{"12345-01-01", func(s string) (lexical, error) { return xsdtypes.ParseDate(s) }},
+		{"0000-02-29", func(s string) (lexical, error) { return xsdtypes.ParseDate(s) }},
+		{"-0044-03-15T12:00:00Z", func(s string) (lexical, error) { return xsdtypes.ParseDateTime(s) }},
+		{"-0001Z", func(s string) (lexical, error) { return xsdtypes.ParseGYear(s) }},
+		{"-12345-06", func(s string) (lexical, error) { return xsdtypes.ParseGYearMonth(s) }},
+		{"P1Y2M3DT10H30M", func(s string) (lexical, error) { return xsdtypes.ParseDuration(s) }},
+		{"-PT0.5S", func(s string) (lexical, error) { return xsdtypes.ParseDuration(s) }},
+		{"-1234.5600", func(s string) (lexical, error) { return xsdtypes.ParseDecimal(s) }},
+		{"0.001", func(s string) (lexical, error) { return xsdtypes.ParseDecimal(s) }},
+		{"-123456789012345678901234567890", func(s string) (lexical, error) { return xsdtypes.ParseInteger(s) }},
+		{"0FB7", func(s string) (lexical, error) { return xsdtypes.ParseHexBinary(s) }},
+		{"aGVsbG8=", func(s string) (lexical, error) { return xsdtypes.ParseBase64Binary(s) }},
+		{"tns:PurchaseOrderType", func(s string) (lexical, error) { return xsdtypes.ParseQName(s) }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			v, err := tc.parse(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.input, v.String())
+		})
+	}
+}
+
+func TestParseCalendarEdges(t *testing.T) {
+	d, err := xsdtypes.ParseDate("-0044-03-15")
+	require.NoError(t, err)
+	assert.Equal(t, -44, d.Time.Year())
+
+	end, err := xsdtypes.ParseDateTime("1999-12-31T24:00:00.000Z")
+	require.NoError(t, err)
+	assert.Equal(t, "2000-01-01T00:00:00Z", end.String(), "the end of a day is the start of the next one")
+	midnight, err := xsdtypes.ParseTime("24:00:00")
+	require.NoError(t, err)
+	assert.Equal(t, "00:00:00", midnight.String())
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := xsdtypes.ParseDate("2002-13-01")
+	assert.Error(t, err)
+	_, err = xsdtypes.ParseDate("2002-10-10+15:00")
+	assert.Error(t, err)
+	for _, date := range []string{"02002-10-10", "-0000-10-10", "999-10-10", "2001-02-29"} {
+		_, err = xsdtypes.ParseDate(date)
+		assert.Error(t, err, date)
+	}
+	_, err = xsdtypes.ParseTime("24:00:01")
+	assert.Error(t, err)
+	_, err = xsdtypes.ParseDuration("P")
+	assert.Error(t, err)
+	_, err = xsdtypes.ParseDuration("P1YT")
+	assert.Error(t, err)
+	_, err = xsdtypes.ParseDecimal("1e5")
+	assert.Error(t, err)
+	_, err = xsdtypes.ParseInteger("1.0")
+	assert.Error(t, err)
+	_, err = xsdtypes.ParseHexBinary("ABC")
+	assert.Error(t, err)
+	_, err = xsdtypes.ParseQName(":local")
+	assert.Error(t, err)
+}
+
+func TestTimezoneHandling(t *testing.T) {
+	withTZ, err := xsdtypes.ParseDateTime("2002-10-10T12:00:00-05:00")
+	require.NoError(t, err)
+	assert.True(t, withTZ.HasTimezone)
+	assert.Equal(t, 17, withTZ.Time.UTC().Hour())
+
+	local, err := xsdtypes.ParseDateTime("2002-10-10T12:00:00")
+	require.NoError(t, err)
+	assert.False(t, local.HasTimezone)
+
+	d := xsdtypes.NewDate(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, "2024-02-29Z", d.String())
+}
+
+func TestDecimalPrecision(t *testing.T) {
+	a, err := xsdtypes.ParseDecimal("0.1")
+	require.NoError(t, err)
+	b, err := xsdtypes.ParseDecimal("0.10000000000000000001")
+	require.NoError(t, err)
+	assert.Equal(t, -1, a.Cmp(b))
+	assert.Equal(t, "1.50", xsdtypes.NewDecimal(150, 2).String())
+	assert.Equal(t, "1500", xsdtypes.NewDecimal(15, -2).String())
+	assert.Equal(t, "0", xsdtypes.Decimal{}.String())
+}
+
+func TestIntegerPrecision(t *testing.T) {
+	huge, err := xsdtypes.ParseInteger("+18446744073709551616")
+	require.NoError(t, err)
+	assert.Equal(t, "18446744073709551616", huge.String())
+	assert.Equal(t, 1, huge.Cmp(xsdtypes.NewInteger(1<<62)))
+	_, fits := huge.Int64()
+	assert.False(t, fits)
+	n, fits := xsdtypes.NewInteger(-7).Int64()
+	assert.True(t, fits)
+	assert.Equal(t, int64(-7), n)
+	assert.Equal(t, "0", xsdtypes.Integer{}.String())
+}
+
+func TestXMLMarshaling(t *testing.T) {
+	type order struct {
+		XMLName  xml.Name           `xml:"order"`
+		Date     xsdtypes.Date      `xml:"date,attr"`
+		Total    xsdtypes.Decimal   `xml:"total"`
+		Shipped  *xsdtypes.DateTime `xml:"shipped,omitempty"`
+		Checksum xsdtypes.HexBinary `xml:"checksum"`
+	}
+	in := `<order date="2024-02-29+01:00"><total>19.90</total><checksum>CAFE</checksum></order>`
+
+	var o order
+	require.NoError(t, xml.Unmarshal([]byte(in), &o))
+	assert.Equal(t, "19.90", o.Total.String())
+	assert.Nil(t, o.Shipped)
+
+	out, err := xml.Marshal(o)
+	require.NoError(t, err)
+	assert.Equal(t, in, string(out))
+}
+
+func TestDecodeLexicalValues(t *testing.T) {
+	var d xsdtypes.Date
+	require.NoError(t, xsdtypes.Decode("2024-02-29Z", &d))
+	assert.Equal(t, "2024-02-29Z", d.String())
+
+	assert.Equal(t, 42, xsdtypes.MustDecode[int]("42"))
+	assert.Equal(t, "a < b", xsdtypes.MustDecode[string]("a < b"))
+	assert.Equal(t, "1.50", xsdtypes.MustDecode[*xsdtypes.Decimal]("1.50").String())
+	assert.Panics(t, func() { xsdtypes.MustDecode[bool]("maybe") })
+}